@@ -0,0 +1,323 @@
+package export
+
+// builtin describes how a VB6 runtime function is translated to C#.
+type builtin struct {
+	Name   string   // C# function, or a format string when it contains %s
+	Type   string   // result type
+	Params []string // argument types, used to convert the arguments
+	Casts  []string // casts applied to the arguments, by position
+	Cast   string   // cast applied to the result when used in an expression
+	Prop   bool     // the .NET equivalent is a property rather than a method
+}
+
+// builtins maps the VB6 runtime functions (lower case, without type suffix)
+// to their Microsoft.VisualBasic or .NET equivalents.
+var builtins = map[string]builtin{
+	// Strings
+	"left":       {Name: "Strings.Left", Type: "string", Params: []string{"string", "int"}},
+	"right":      {Name: "Strings.Right", Type: "string", Params: []string{"string", "int"}},
+	"mid":        {Name: "Strings.Mid", Type: "string", Params: []string{"string", "int", "int"}},
+	"len":        {Name: "Strings.Len", Type: "int"},
+	"lcase":      {Name: "Strings.LCase", Type: "string", Params: []string{"string"}},
+	"ucase":      {Name: "Strings.UCase", Type: "string", Params: []string{"string"}},
+	"trim":       {Name: "Strings.Trim", Type: "string", Params: []string{"string"}},
+	"ltrim":      {Name: "Strings.LTrim", Type: "string", Params: []string{"string"}},
+	"rtrim":      {Name: "Strings.RTrim", Type: "string", Params: []string{"string"}},
+	"instr":      {Name: "Strings.InStr", Type: "int"},
+	"instrrev":   {Name: "Strings.InStrRev", Type: "int", Params: []string{"string", "string", "int"}},
+	"replace":    {Name: "Strings.Replace", Type: "string", Params: []string{"string", "string", "string", "int", "int"}},
+	"space":      {Name: "Strings.Space", Type: "string", Params: []string{"int"}},
+	"string":     {Name: "Strings.StrDup", Type: "string", Params: []string{"int", "string"}},
+	"asc":        {Name: "Strings.Asc", Type: "int", Params: []string{"string"}},
+	"ascw":       {Name: "Strings.AscW", Type: "int", Params: []string{"string"}},
+	"chr":        {Name: "Strings.Chr(%s).ToString()", Type: "string", Params: []string{"int"}},
+	"chrw":       {Name: "Strings.ChrW(%s).ToString()", Type: "string", Params: []string{"int"}},
+	"format":     {Name: "Strings.Format", Type: "string", Params: []string{"", "string"}},
+	"split":      {Name: "Strings.Split", Type: "string[]", Params: []string{"string", "string"}},
+	"join":       {Name: "Strings.Join", Type: "string"},
+	"strcomp":    {Name: "Strings.StrComp", Type: "int", Params: []string{"string", "string"}, Casts: []string{"", "", "CompareMethod"}},
+	"strconv":    {Name: "Strings.StrConv", Type: "string", Params: []string{"string"}, Casts: []string{"", "VbStrConv"}},
+	"strreverse": {Name: "Strings.StrReverse", Type: "string", Params: []string{"string"}},
+
+	// Conversion
+	"val":   {Name: "Conversion.Val", Type: "double", Params: []string{"string"}},
+	"str":   {Name: "Conversion.Str", Type: "string"},
+	"hex":   {Name: "Conversion.Hex", Type: "string"},
+	"oct":   {Name: "Conversion.Oct", Type: "string"},
+	"int":   {Name: "Conversion.Int", Type: "double", Params: []string{"double"}},
+	"fix":   {Name: "Conversion.Fix", Type: "double", Params: []string{"double"}},
+	"cstr":  {Name: "Convert.ToString", Type: "string"},
+	"cint":  {Name: "Convert.ToInt32", Type: "int"},
+	"clng":  {Name: "Convert.ToInt32", Type: "int"},
+	"cdbl":  {Name: "Convert.ToDouble", Type: "double"},
+	"csng":  {Name: "Convert.ToSingle", Type: "float"},
+	"cbool": {Name: "Convert.ToBoolean", Type: "bool"},
+	"cbyte": {Name: "Convert.ToByte", Type: "byte"},
+	"ccur":  {Name: "Convert.ToDecimal", Type: "decimal"},
+	"cdate": {Name: "Convert.ToDateTime", Type: "DateTime"},
+	"cvar":  {Name: "(dynamic)(%s)", Type: "dynamic"},
+
+	// Math
+	"abs":   {Name: "Math.Abs", Type: "double"},
+	"sgn":   {Name: "Math.Sign", Type: "int"},
+	"sqr":   {Name: "Math.Sqrt", Type: "double", Params: []string{"double"}},
+	"sin":   {Name: "Math.Sin", Type: "double", Params: []string{"double"}},
+	"cos":   {Name: "Math.Cos", Type: "double", Params: []string{"double"}},
+	"tan":   {Name: "Math.Tan", Type: "double", Params: []string{"double"}},
+	"atn":   {Name: "Math.Atan", Type: "double", Params: []string{"double"}},
+	"exp":   {Name: "Math.Exp", Type: "double", Params: []string{"double"}},
+	"log":   {Name: "Math.Log", Type: "double", Params: []string{"double"}},
+	"round": {Name: "Math.Round", Type: "double", Params: []string{"double", "int"}},
+	"rnd":   {Name: "VBMath.Rnd", Type: "float"},
+
+	// Information
+	"isnumeric": {Name: "Information.IsNumeric", Type: "bool"},
+	"isdate":    {Name: "Information.IsDate", Type: "bool"},
+	"isarray":   {Name: "Information.IsArray", Type: "bool"},
+	"isobject":  {Name: "(%s is object)", Type: "bool"},
+	"isempty":   {Name: "(%s == null)", Type: "bool"},
+	"isnull":    {Name: "Convert.IsDBNull", Type: "bool"},
+	"typename":  {Name: "Information.TypeName", Type: "string"},
+	"vartype":   {Name: "Information.VarType", Type: "int", Cast: "int"},
+	"ubound":    {Name: "Information.UBound", Type: "int"},
+	"lbound":    {Name: "Information.LBound", Type: "int"},
+	"rgb":       {Name: "Information.RGB", Type: "int", Params: []string{"int", "int", "int"}},
+	"qbcolor":   {Name: "Information.QBColor", Type: "int", Params: []string{"int"}},
+	"error":     {Name: "Conversion.ErrorToString", Type: "string"},
+
+	// Date and time
+	"now":         {Name: "DateAndTime.Now", Type: "DateTime", Prop: true},
+	"date":        {Name: "DateAndTime.Today", Type: "DateTime", Prop: true},
+	"time":        {Name: "DateAndTime.TimeOfDay", Type: "DateTime", Prop: true},
+	"timer":       {Name: "DateAndTime.Timer", Type: "double", Prop: true},
+	"year":        {Name: "DateAndTime.Year", Type: "int", Params: []string{"DateTime"}},
+	"month":       {Name: "DateAndTime.Month", Type: "int", Params: []string{"DateTime"}},
+	"day":         {Name: "DateAndTime.Day", Type: "int", Params: []string{"DateTime"}},
+	"hour":        {Name: "DateAndTime.Hour", Type: "int", Params: []string{"DateTime"}},
+	"minute":      {Name: "DateAndTime.Minute", Type: "int", Params: []string{"DateTime"}},
+	"second":      {Name: "DateAndTime.Second", Type: "int", Params: []string{"DateTime"}},
+	"weekday":     {Name: "DateAndTime.Weekday", Type: "int", Params: []string{"DateTime"}},
+	"dateadd":     {Name: "DateAndTime.DateAdd", Type: "DateTime", Params: []string{"string", "double", ""}},
+	"datediff":    {Name: "DateAndTime.DateDiff", Type: "int", Params: []string{"string", "", ""}, Cast: "int"},
+	"dateserial":  {Name: "DateAndTime.DateSerial", Type: "DateTime", Params: []string{"int", "int", "int"}},
+	"timeserial":  {Name: "DateAndTime.TimeSerial", Type: "DateTime", Params: []string{"int", "int", "int"}},
+	"datevalue":   {Name: "DateAndTime.DateValue", Type: "DateTime", Params: []string{"string"}},
+	"timevalue":   {Name: "DateAndTime.TimeValue", Type: "DateTime", Params: []string{"string"}},
+	"monthname":   {Name: "DateAndTime.MonthName", Type: "string", Params: []string{"int", "bool"}},
+	"weekdayname": {Name: "DateAndTime.WeekdayName", Type: "string", Params: []string{"int", "bool"}},
+
+	// Interaction
+	"msgbox":        {Name: "Interaction.MsgBox", Type: "int", Params: []string{"", "", ""}, Casts: []string{"", "MsgBoxStyle"}, Cast: "int"},
+	"inputbox":      {Name: "Interaction.InputBox", Type: "string", Params: []string{"string", "string", "string", "int", "int"}},
+	"iif":           {Name: "Interaction.IIf", Type: "object", Params: []string{"bool", "", ""}},
+	"environ":       {Name: "Interaction.Environ", Type: "string"},
+	"command":       {Name: "Interaction.Command", Type: "string"},
+	"shell":         {Name: "Interaction.Shell", Type: "int", Params: []string{"string"}, Casts: []string{"", "AppWinStyle"}},
+	"beep":          {Name: "Interaction.Beep", Type: "void"},
+	"appactivate":   {Name: "Interaction.AppActivate", Type: "void"},
+	"getsetting":    {Name: "Interaction.GetSetting", Type: "string", Params: []string{"string", "string", "string", "string"}},
+	"savesetting":   {Name: "Interaction.SaveSetting", Type: "void", Params: []string{"string", "string", "string", "string"}},
+	"deletesetting": {Name: "Interaction.DeleteSetting", Type: "void", Params: []string{"string", "string", "string"}},
+	"sendkeys":      {Name: "SendKeys.Send", Type: "void", Params: []string{"string"}},
+	"doevents":      {Name: "Application.DoEvents", Type: "void"},
+	"randomize":     {Name: "VBMath.Randomize", Type: "void"},
+	"array":         {Name: "new object[] { %s }", Type: "object[]"},
+	"loadpicture":   {Name: "System.Drawing.Image.FromFile", Type: "System.Drawing.Image", Params: []string{"string"}},
+
+	// File system
+	"dir":          {Name: "FileSystem.Dir", Type: "string", Params: []string{"string"}, Casts: []string{"", "FileAttribute"}},
+	"filelen":      {Name: "FileSystem.FileLen", Type: "int", Params: []string{"string"}, Cast: "int"},
+	"filedatetime": {Name: "FileSystem.FileDateTime", Type: "DateTime", Params: []string{"string"}},
+	"getattr":      {Name: "FileSystem.GetAttr", Type: "int", Params: []string{"string"}, Cast: "int"},
+	"setattr":      {Name: "FileSystem.SetAttr", Type: "void", Params: []string{"string"}, Casts: []string{"", "FileAttribute"}},
+	"freefile":     {Name: "FileSystem.FreeFile", Type: "int"},
+	"eof":          {Name: "FileSystem.EOF", Type: "bool", Params: []string{"int"}},
+	"lof":          {Name: "FileSystem.LOF", Type: "int", Params: []string{"int"}, Cast: "int"},
+	"loc":          {Name: "FileSystem.Loc", Type: "int", Params: []string{"int"}, Cast: "int"},
+	"curdir":       {Name: "FileSystem.CurDir", Type: "string"},
+	"kill":         {Name: "FileSystem.Kill", Type: "void", Params: []string{"string"}},
+	"mkdir":        {Name: "FileSystem.MkDir", Type: "void", Params: []string{"string"}},
+	"rmdir":        {Name: "FileSystem.RmDir", Type: "void", Params: []string{"string"}},
+	"chdir":        {Name: "FileSystem.ChDir", Type: "void", Params: []string{"string"}},
+	"chdrive":      {Name: "FileSystem.ChDrive", Type: "void", Params: []string{"string"}},
+	"filecopy":     {Name: "FileSystem.FileCopy", Type: "void", Params: []string{"string", "string"}},
+}
+
+// constants maps the VB6 runtime constants (lower case) to C# expressions.
+var constants = map[string]value{
+	"vbcrlf":       {code: `"\r\n"`, typ: "string", konst: true},
+	"vbnewline":    {code: `"\r\n"`, typ: "string", konst: true},
+	"vbcr":         {code: `"\r"`, typ: "string", konst: true},
+	"vblf":         {code: `"\n"`, typ: "string", konst: true},
+	"vbtab":        {code: `"\t"`, typ: "string", konst: true},
+	"vbback":       {code: `"\b"`, typ: "string", konst: true},
+	"vbnullchar":   {code: `"\0"`, typ: "string", konst: true},
+	"vbnullstring": {code: `""`, typ: "string", konst: true},
+
+	"vbokonly":              enumConst("MsgBoxStyle.OkOnly"),
+	"vbokcancel":            enumConst("MsgBoxStyle.OkCancel"),
+	"vbabortretryignore":    enumConst("MsgBoxStyle.AbortRetryIgnore"),
+	"vbyesnocancel":         enumConst("MsgBoxStyle.YesNoCancel"),
+	"vbyesno":               enumConst("MsgBoxStyle.YesNo"),
+	"vbretrycancel":         enumConst("MsgBoxStyle.RetryCancel"),
+	"vbcritical":            enumConst("MsgBoxStyle.Critical"),
+	"vbquestion":            enumConst("MsgBoxStyle.Question"),
+	"vbexclamation":         enumConst("MsgBoxStyle.Exclamation"),
+	"vbinformation":         enumConst("MsgBoxStyle.Information"),
+	"vbdefaultbutton1":      enumConst("MsgBoxStyle.DefaultButton1"),
+	"vbdefaultbutton2":      enumConst("MsgBoxStyle.DefaultButton2"),
+	"vbdefaultbutton3":      enumConst("MsgBoxStyle.DefaultButton3"),
+	"vbapplicationmodal":    enumConst("MsgBoxStyle.ApplicationModal"),
+	"vbsystemmodal":         enumConst("MsgBoxStyle.SystemModal"),
+	"vbmsgboxsetforeground": enumConst("MsgBoxStyle.MsgBoxSetForeground"),
+
+	"vbok":     enumConst("MsgBoxResult.Ok"),
+	"vbcancel": enumConst("MsgBoxResult.Cancel"),
+	"vbabort":  enumConst("MsgBoxResult.Abort"),
+	"vbretry":  enumConst("MsgBoxResult.Retry"),
+	"vbignore": enumConst("MsgBoxResult.Ignore"),
+	"vbyes":    enumConst("MsgBoxResult.Yes"),
+	"vbno":     enumConst("MsgBoxResult.No"),
+
+	"vbbinarycompare": enumConst("CompareMethod.Binary"),
+	"vbtextcompare":   enumConst("CompareMethod.Text"),
+
+	"vbuppercase":  enumConst("VbStrConv.Uppercase"),
+	"vblowercase":  enumConst("VbStrConv.Lowercase"),
+	"vbpropercase": enumConst("VbStrConv.ProperCase"),
+
+	"vbhide":           enumConst("AppWinStyle.Hide"),
+	"vbnormalfocus":    enumConst("AppWinStyle.NormalFocus"),
+	"vbminimizedfocus": enumConst("AppWinStyle.MinimizedFocus"),
+	"vbmaximizedfocus": enumConst("AppWinStyle.MaximizedFocus"),
+	"vbnormalnofocus":  enumConst("AppWinStyle.NormalNoFocus"),
+
+	"vbnormal":    intConst("0"),
+	"vbreadonly":  intConst("1"),
+	"vbhidden":    intConst("2"),
+	"vbsystem":    intConst("4"),
+	"vbdirectory": intConst("16"),
+	"vbarchive":   intConst("32"),
+
+	"vbblack":   intConst("0x000000"),
+	"vbred":     intConst("0x0000FF"),
+	"vbgreen":   intConst("0x00FF00"),
+	"vbyellow":  intConst("0x00FFFF"),
+	"vbblue":    intConst("0xFF0000"),
+	"vbmagenta": intConst("0xFF00FF"),
+	"vbcyan":    intConst("0xFFFF00"),
+	"vbwhite":   intConst("0xFFFFFF"),
+
+	"vbshiftmask":    intConst("1"),
+	"vbctrlmask":     intConst("2"),
+	"vbaltmask":      intConst("4"),
+	"vbleftbutton":   intConst("1"),
+	"vbrightbutton":  intConst("2"),
+	"vbmiddlebutton": intConst("4"),
+
+	"vbmodal":    intConst("1"),
+	"vbmodeless": intConst("0"),
+
+	"cdlofnreadonly":         intConst("0x1"),
+	"cdlofnoverwriteprompt":  intConst("0x2"),
+	"cdlofnhidereadonly":     intConst("0x4"),
+	"cdlofnnochangedir":      intConst("0x8"),
+	"cdlofnallowmultiselect": intConst("0x200"),
+	"cdlofnexplorer":         intConst("0x80000"),
+	"cdlofnpathmustexist":    intConst("0x800"),
+	"cdlofnfilemustexist":    intConst("0x1000"),
+	"cdlofncreateprompt":     intConst("0x2000"),
+	"cdlccfullopen":          intConst("0x2"),
+	"cdlccpreventfullopen":   intConst("0x4"),
+	"cdlccrgbinit":           intConst("0x1"),
+	"cdlcfscreenfonts":       intConst("0x1"),
+	"cdlcfprinterfonts":      intConst("0x2"),
+	"cdlcfboth":              intConst("0x3"),
+	"cdlcfeffects":           intConst("0x100"),
+	"cdlcffixedpitchonly":    intConst("0x4000"),
+	"cdlcfforcefontexist":    intConst("0x10000"),
+	"cdlcancel":              intConst("32755"),
+
+	"vbdefault":   intConst("0"),
+	"vbarrow":     intConst("1"),
+	"vbhourglass": intConst("11"),
+
+	"vbformcontrolmenu": intConst("0"),
+	"vbformcode":        intConst("1"),
+	"vbappwindows":      intConst("2"),
+	"vbapptaskmanager":  intConst("3"),
+	"vbformmdiform":     intConst("4"),
+
+	"vbsunday":    intConst("1"),
+	"vbmonday":    intConst("2"),
+	"vbtuesday":   intConst("3"),
+	"vbwednesday": intConst("4"),
+	"vbthursday":  intConst("5"),
+	"vbfriday":    intConst("6"),
+	"vbsaturday":  intConst("7"),
+
+	"vbobjecterror": intConst("-2147221504"),
+}
+
+// keyConstants maps the vbKey constants that do not follow the naming of the
+// Keys enumeration.
+var keyConstants = map[string]string{
+	"vbkeyreturn":   "Return",
+	"vbkeyescape":   "Escape",
+	"vbkeyback":     "Back",
+	"vbkeytab":      "Tab",
+	"vbkeyspace":    "Space",
+	"vbkeydelete":   "Delete",
+	"vbkeyinsert":   "Insert",
+	"vbkeyleft":     "Left",
+	"vbkeyright":    "Right",
+	"vbkeyup":       "Up",
+	"vbkeydown":     "Down",
+	"vbkeyhome":     "Home",
+	"vbkeyend":      "End",
+	"vbkeypageup":   "PageUp",
+	"vbkeypagedown": "PageDown",
+	"vbkeyshift":    "ShiftKey",
+	"vbkeycontrol":  "ControlKey",
+	"vbkeymenu":     "Menu",
+	"vbkeyadd":      "Add",
+	"vbkeysubtract": "Subtract",
+	"vbkeymultiply": "Multiply",
+	"vbkeydivide":   "Divide",
+	"vbkeydecimal":  "Decimal",
+}
+
+func enumConst(name string) value {
+	return value{code: "(int)" + name, typ: "int", prec: precUnary, konst: true}
+}
+
+func intConst(v string) value {
+	return value{code: v, typ: "int", prec: precPrimary, konst: true}
+}
+
+// lookupConstant returns the translation of a VB6 runtime constant.
+func lookupConstant(name string) (value, bool) {
+	name = lower(name)
+	if v, ok := constants[name]; ok {
+		if v.prec == 0 {
+			v.prec = precPrimary
+		}
+		return v, true
+	}
+	if key, ok := keyConstants[name]; ok {
+		return enumConst("System.Windows.Forms.Keys." + key), true
+	}
+	if len(name) == 6 && name[:5] == "vbkey" {
+		c := name[5]
+		switch {
+		case c >= 'a' && c <= 'z':
+			return enumConst("System.Windows.Forms.Keys." + string(c-32)), true
+		case c >= '0' && c <= '9':
+			return enumConst("System.Windows.Forms.Keys.D" + string(c)), true
+		}
+	}
+	if len(name) >= 7 && name[:6] == "vbkeyf" {
+		return enumConst("System.Windows.Forms.Keys.F" + name[6:]), true
+	}
+	return value{}, false
+}
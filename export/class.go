@@ -0,0 +1,129 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/guthius/vb6conv/vb6"
+	"github.com/guthius/vb6conv/vb6/ast"
+)
+
+// ExportClass converts a class module into a C# class.
+func ExportClass(p *ProjectInfo, c *vb6.Class) {
+	code, err := ast.Parse(c.Script)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", c.Filename, err)
+	}
+
+	todos, err := exportClass(p, c, code)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", c.Filename, err)
+	}
+
+	report := newFileReport(c.Name, "class", c.Filename)
+	report.Untranslated = todos
+	p.addReport(report)
+}
+
+// RegisterClass makes the public events of a class available to the
+// WithEvents variables of the project. Classes must be registered before
+// they are exported.
+func (p *ProjectInfo) RegisterClass(c *vb6.Class) {
+	if p.classes == nil {
+		p.classes = make(map[string]map[string]*ast.EventDecl)
+	}
+	events := make(map[string]*ast.EventDecl)
+	p.classes[key(c.Name)] = events
+
+	// Parse errors are reported when the class is exported
+	code, _ := ast.Parse(c.Script)
+	if code == nil {
+		return
+	}
+	for _, d := range code.Decls {
+		if e, ok := d.(*ast.EventDecl); ok && visibility(e.Visibility, "public") == "public" {
+			events[key(e.Name)] = e
+		}
+	}
+}
+
+func exportClass(p *ProjectInfo, c *vb6.Class, code *ast.File) ([]CodeIssue, error) {
+	filename := filepath.Join(p.Output, c.Name+".cs")
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var todos []CodeIssue
+	writer := NewExportWriter(file)
+	writer.Write("using System;")
+	writer.Write("using System.Collections.Generic;")
+	writer.Write("using System.Drawing;")
+	writer.Write("using System.Linq;")
+	writer.Write("using System.Text;")
+	writer.Write("using System.Windows.Forms;")
+	writer.Write("using Microsoft.VisualBasic;")
+	writer.Write("using Microsoft.VisualBasic.CompilerServices;")
+	writer.Writeln()
+	writer.Writef("namespace %s;", p.Namespace)
+	writer.Writeln()
+	t := newTranslator(code, writer, c.Name)
+	t.shared = p.symbols
+	t.sharedTypes = p.types
+	t.classes = p.classes
+	t.declareTypes()
+	t.declareFile()
+
+	terminate := t.lookup("Class_Terminate")
+	disposable := terminate != nil && terminate.Kind == procSymbol
+
+	// Classes are public, the public members of the modules and forms can use
+	// them in their signatures
+	if disposable {
+		writer.Writef("public class %s : IDisposable", c.Name)
+	} else {
+		writer.Writef("public class %s", c.Name)
+	}
+	writer.Write("{")
+	writer.WriteIndent(func() {
+
+		decls, procs := t.splitDecls()
+		t.comment = true
+		t.writeDecls(decls)
+
+		initialize := t.lookup("Class_Initialize")
+		if initialize != nil && initialize.Kind == procSymbol {
+			t.separate()
+			writer.Writef("public %s()", c.Name)
+			writer.Write("{")
+			writer.WriteIndent(func() {
+				writer.Writef("%s();", initialize.Code)
+			})
+			writer.Write("}")
+			t.block = true
+		}
+		if disposable {
+			// VB6 terminates an object when its last reference is released, a
+			// finalizer would run at an unknown time on another thread
+			reason := "Class_Terminate runs in Dispose, call it where the last reference is released"
+			t.separate()
+			writer.Writef("// TODO: %s", reason)
+			writer.Write("public void Dispose()")
+			writer.Write("{")
+			writer.WriteIndent(func() {
+				writer.Writef("%s();", terminate.Code)
+			})
+			writer.Write("}")
+			t.block = true
+			t.todos = append(t.todos, CodeIssue{Line: terminate.Proc.Pos().Line, Reason: reason})
+		}
+
+		t.writeDecls(procs)
+		todos = t.todos
+	})
+	writer.Write("}")
+
+	return todos, nil
+}
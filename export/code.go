@@ -0,0 +1,1665 @@
+package export
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6/ast"
+)
+
+type symbolKind int
+
+const (
+	varSymbol symbolKind = iota
+	constSymbol
+	procSymbol
+	propSymbol
+	controlSymbol
+)
+
+// symbol is a name that can be referenced from VB6 code.
+type symbol struct {
+	Kind        symbolKind
+	Code        string // C# expression referring to the symbol
+	Type        string
+	Proc        *ast.ProcDecl
+	Indexed     bool // property with parameters
+	Control     *Control
+	Unsupported string // reason references to the symbol cannot be translated
+}
+
+// translator converts the code section of a VB6 file into C# class members.
+type translator struct {
+	file        *ast.File
+	w           *ExportWriter
+	name        string   // name of the generated class
+	form        *Control // root control of forms
+	static      bool     // members are static
+	globals     map[string]*symbol
+	shared      map[string]*symbol // public symbols of the modules in the project
+	types       map[string]*userType
+	sharedTypes map[string]*userType                 // public types and enums of the modules
+	classes     map[string]map[string]*ast.EventDecl // public events of the classes in the project
+	locals      map[string]*symbol
+	handlers    map[*ast.ProcDecl]*handler
+	proc        *ast.ProcDecl
+	result      value // the local holding the return value of functions
+	with        []value
+	temps       int
+	todos       []CodeIssue
+	comment     bool // the last line written was a comment
+	block       bool // the last member written spans multiple lines
+}
+
+func newTranslator(file *ast.File, w *ExportWriter, name string) *translator {
+	return &translator{
+		file:     file,
+		w:        w,
+		name:     name,
+		globals:  make(map[string]*symbol),
+		types:    make(map[string]*userType),
+		handlers: make(map[*ast.ProcDecl]*handler),
+	}
+}
+
+func lower(s string) string {
+	return strings.ToLower(s)
+}
+
+// key returns the symbol table key of a VB6 name.
+func key(name string) string {
+	name = strings.Trim(name, "[]")
+	name = strings.TrimRight(name, "$%&!#@")
+	return strings.ToLower(name)
+}
+
+var csKeywords = map[string]bool{
+	"abstract": true, "base": true, "bool": true, "break": true, "byte": true,
+	"case": true, "catch": true, "char": true, "checked": true, "class": true,
+	"continue": true, "decimal": true, "default": true, "delegate": true,
+	"double": true, "else": true, "enum": true, "event": true, "explicit": true,
+	"extern": true, "false": true, "finally": true, "fixed": true, "float": true,
+	"for": true, "foreach": true, "goto": true, "implicit": true, "in": true,
+	"int": true, "interface": true, "internal": true, "is": true, "lock": true,
+	"long": true, "namespace": true, "new": true, "null": true, "object": true,
+	"operator": true, "out": true, "override": true, "params": true,
+	"private": true, "protected": true, "public": true, "readonly": true,
+	"ref": true, "return": true, "sbyte": true, "sealed": true, "short": true,
+	"sizeof": true, "stackalloc": true, "static": true, "string": true,
+	"struct": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "uint": true, "ulong": true, "unchecked": true,
+	"unsafe": true, "ushort": true, "using": true, "virtual": true, "void": true,
+	"volatile": true, "while": true,
+}
+
+// csName converts a VB6 name into a C# identifier.
+func csName(name string) string {
+	name = strings.Trim(name, "[]")
+	name = strings.TrimRight(name, "$%&!#@")
+	name = strings.ReplaceAll(name, " ", "_")
+	if csKeywords[name] {
+		return "@" + name
+	}
+	return name
+}
+
+// csString quotes s as a C# string literal.
+func csString(s string) string {
+	sb := strings.Builder{}
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				sb.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func labelName(name string) string {
+	if _, err := strconv.Atoi(name); err == nil {
+		return "L" + name
+	}
+	return csName(name)
+}
+
+var csTypes = map[string]string{
+	"integer":       "int",
+	"long":          "int",
+	"byte":          "byte",
+	"single":        "float",
+	"double":        "double",
+	"currency":      "decimal",
+	"string":        "string",
+	"boolean":       "bool",
+	"date":          "DateTime",
+	"variant":       "dynamic",
+	"object":        "dynamic",
+	"collection":    "Microsoft.VisualBasic.Collection",
+	"stdpicture":    "System.Drawing.Image",
+	"ipicturedisp":  "System.Drawing.Image",
+	"control":       "System.Windows.Forms.Control",
+	"form":          "System.Windows.Forms.Form",
+	"textbox":       "System.Windows.Forms.TextBox",
+	"label":         "System.Windows.Forms.Label",
+	"commandbutton": "System.Windows.Forms.Button",
+	"checkbox":      "System.Windows.Forms.CheckBox",
+	"optionbutton":  "System.Windows.Forms.RadioButton",
+	"combobox":      "System.Windows.Forms.ComboBox",
+	"listbox":       "System.Windows.Forms.ListBox",
+	"frame":         "System.Windows.Forms.GroupBox",
+	"picturebox":    "System.Windows.Forms.PictureBox",
+	"timer":         "System.Windows.Forms.Timer",
+	"listview":      "System.Windows.Forms.ListView",
+	"listitem":      "System.Windows.Forms.ListViewItem",
+	"columnheader":  "System.Windows.Forms.ColumnHeader",
+	"treeview":      "System.Windows.Forms.TreeView",
+	"node":          "System.Windows.Forms.TreeNode",
+	"imagelist":     "System.Windows.Forms.ImageList",
+	"toolbar":       "System.Windows.Forms.ToolStrip",
+	"button":        "System.Windows.Forms.ToolStripItem",
+	"statusbar":     "System.Windows.Forms.StatusStrip",
+	"panel":         "System.Windows.Forms.ToolStripStatusLabel",
+	"progressbar":   "System.Windows.Forms.ProgressBar",
+	"slider":        "System.Windows.Forms.TrackBar",
+	"tabstrip":      "System.Windows.Forms.TabControl",
+	"commondialog":  "CommonDialogControl",
+	"msflexgrid":    "System.Windows.Forms.DataGridView",
+	"mshflexgrid":   "System.Windows.Forms.DataGridView",
+	"richtextbox":   "System.Windows.Forms.RichTextBox",
+	"sstab":         "System.Windows.Forms.TabControl",
+	"dtpicker":      "System.Windows.Forms.DateTimePicker",
+	"monthview":     "System.Windows.Forms.MonthCalendar",
+	"updown":        "System.Windows.Forms.NumericUpDown",
+	"animation":     "System.Windows.Forms.PictureBox",
+	"winsock":       "WinsockControl",
+}
+
+// controlLibraries are the type libraries of the supported controls.
+var controlLibraries = []string{
+	"VB", "MSComctlLib", "ComctlLib", "MSComCtl2", "MSComDlg", "MSFlexGridLib",
+	"MSHierarchicalFlexGridLib", "RichTextLib", "TabDlg", "MSWinsockLib",
+}
+
+// csTypeName converts a VB6 type name into a C# type name.
+func csTypeName(name string) string {
+	if dot := strings.Index(name, "."); dot != -1 && slices.Contains(controlLibraries, name[:dot]) {
+		name = name[dot+1:]
+	}
+	if typ, ok := csTypes[lower(name)]; ok {
+		return typ
+	}
+	return name
+}
+
+// csType converts a VB6 As clause into a C# type. Variables declared without
+// a type are Variants.
+func csType(ref *ast.TypeRef, dims int) string {
+	typ := "dynamic"
+	if ref != nil {
+		typ = csTypeName(ref.Name)
+	}
+	return arrayType(typ, dims)
+}
+
+// refType converts a VB6 As clause into a C# type, including the user defined
+// types and enums of the project.
+func (t *translator) refType(ref *ast.TypeRef, dims int) string {
+	typ := "dynamic"
+	if ref != nil {
+		typ = t.typeName(ref.Name)
+	}
+	return arrayType(typ, dims)
+}
+
+// arrayType returns the type of an array of typ with dims dimensions, or typ
+// when dims is 0.
+func arrayType(typ string, dims int) string {
+	if dims > 0 {
+		if typ == "dynamic" {
+			typ = "object"
+		}
+		typ += "[" + strings.Repeat(",", dims-1) + "]"
+	}
+	return typ
+}
+
+// typeFromSuffix returns the type implied by a type declaration character.
+func typeFromSuffix(name string) *ast.TypeRef {
+	suffixes := map[byte]string{
+		'$': "String", '%': "Integer", '&': "Long", '!': "Single", '#': "Double", '@': "Currency",
+	}
+	if len(name) > 0 {
+		if typ, ok := suffixes[name[len(name)-1]]; ok {
+			return &ast.TypeRef{Name: typ}
+		}
+	}
+	return nil
+}
+
+func (t *translator) specType(spec *ast.VarSpec) string {
+	ref := spec.Type
+	if ref == nil {
+		ref = typeFromSuffix(spec.Name)
+	}
+	dims := 0
+	if spec.IsArray {
+		dims = max(len(spec.Bounds), 1)
+	}
+	return t.refType(ref, dims)
+}
+
+func (t *translator) paramType(p *ast.Param) string {
+	if p.ParamArray {
+		return "object[]"
+	}
+	ref := p.Type
+	if ref == nil {
+		ref = typeFromSuffix(p.Name)
+	}
+	dims := 0
+	if p.IsArray {
+		dims = 1
+	}
+	return t.refType(ref, dims)
+}
+
+func isPrimitive(typ string) bool {
+	switch typ {
+	case "string", "bool", "DateTime":
+		return true
+	}
+	return isNumeric(typ)
+}
+
+// zeroValue returns the value VB6 initializes variables of type typ with.
+func zeroValue(typ string) string {
+	switch {
+	case typ == "string":
+		return `""`
+	case typ == "bool":
+		return "false"
+	case typ == "DateTime":
+		return "default(DateTime)"
+	case isNumeric(typ):
+		return "0"
+	}
+	return "null"
+}
+
+// initValue returns the initializer of a declared variable.
+func (t *translator) initValue(spec *ast.VarSpec, typ string) string {
+	if spec.IsArray {
+		if len(spec.Bounds) == 0 {
+			return "null"
+		}
+		return fmt.Sprintf("new %s", t.arraySize(typ, spec.Bounds))
+	}
+	if spec.Type != nil && spec.Type.New {
+		return fmt.Sprintf("new %s()", typ)
+	}
+	return t.newValue(typ)
+}
+
+// arraySize returns T[n, m] for an array of type typ (T[,]) with the given
+// bounds. Lower bounds are ignored; arrays always start at 0 so that indices
+// of arrays with a lower bound of 1 stay valid.
+func (t *translator) arraySize(typ string, bounds []ast.Bound) string {
+	elem := typ
+	if i := strings.Index(elem, "["); i != -1 {
+		elem = elem[:i]
+	}
+	if elem == "dynamic" || elem == "" {
+		elem = "object"
+	}
+	sizes := make([]string, 0, len(bounds))
+	for _, b := range bounds {
+		upper := coerce(t.value(b.Upper), "int")
+		if n, err := strconv.Atoi(upper.code); err == nil {
+			sizes = append(sizes, strconv.Itoa(n+1))
+		} else {
+			sizes = append(sizes, wrap(upper, precAdditive)+" + 1")
+		}
+	}
+	return fmt.Sprintf("%s[%s]", elem, strings.Join(sizes, ", "))
+}
+
+func (t *translator) lookup(name string) *symbol {
+	k := key(name)
+	if s, ok := t.locals[k]; ok {
+		return s
+	}
+	if s, ok := t.globals[k]; ok {
+		return s
+	}
+	if s, ok := t.shared[k]; ok {
+		return s
+	}
+	return nil
+}
+
+// accessor returns the name of the Get or Set method of an indexed property.
+func accessor(prefix string, code string) string {
+	dot := strings.LastIndex(code, ".")
+	return code[:dot+1] + prefix + code[dot+1:]
+}
+
+// isResult reports whether name refers to the return value of the function
+// being translated.
+func (t *translator) isResult(name string) bool {
+	return t.proc != nil && t.result.code != "" && strings.EqualFold(key(name), key(t.proc.Name))
+}
+
+func (t *translator) temp(prefix string) string {
+	t.temps++
+	return fmt.Sprintf("%s%d", prefix, t.temps)
+}
+
+// addControls declares the controls of a form.
+func (t *translator) addControls(c *Control, root bool) {
+	if root {
+		t.form = c
+		t.globals[key(c.Name)] = &symbol{Kind: controlSymbol, Code: "this", Type: c.Name, Control: c}
+		if shortType(c.TypeName) == "UserControl" {
+			t.globals["usercontrol"] = t.globals[key(c.Name)]
+		}
+	} else if c.ArrayName != "" {
+		if _, ok := t.globals[key(c.ArrayName)]; !ok {
+			t.globals[key(c.ArrayName)] = &symbol{Kind: controlSymbol, Code: c.ArrayName, Type: c.TypeName, Control: c, Indexed: true}
+		}
+	} else {
+		t.globals[key(c.Name)] = &symbol{Kind: controlSymbol, Code: c.Name, Type: c.TypeName, Control: c}
+	}
+	for _, child := range c.Children {
+		t.addControls(child, false)
+	}
+}
+
+// findControl returns the control of a form or user control with the given
+// name, or an element of the control array with that name.
+func findControl(root *Control, name string) *Control {
+	switch shortType(root.TypeName) {
+	case "Form", "UserControl":
+		if found := findControls(root, name, nil); len(found) > 0 {
+			return found[0]
+		}
+	}
+	return nil
+}
+
+// declareFile adds the module level declarations to the symbol table.
+func (t *translator) declareFile() {
+	for _, d := range t.file.Decls {
+		switch d := d.(type) {
+		case *ast.VarDecl:
+			for _, spec := range d.Vars {
+				t.globals[key(spec.Name)] = &symbol{Kind: varSymbol, Code: csName(spec.Name), Type: t.specType(spec)}
+			}
+		case *ast.ConstDecl:
+			for _, spec := range d.Consts {
+				t.globals[key(spec.Name)] = &symbol{Kind: constSymbol, Code: csName(spec.Name), Type: t.constType(spec)}
+			}
+		case *ast.ProcDecl:
+			s := &symbol{Kind: procSymbol, Code: csName(d.Name), Type: "void", Proc: d}
+			switch d.Kind {
+			case ast.FunctionProc:
+				s.Type = t.returnType(d)
+			case ast.PropertyGet, ast.PropertyLet, ast.PropertySet:
+				s.Kind = propSymbol
+				if prev, ok := t.globals[key(d.Name)]; ok && prev.Kind == propSymbol {
+					s = prev
+				}
+				s.Type = t.propertyType(d, s.Type)
+				s.Indexed = t.propertyParams(d) > 0
+				if d.Kind == ast.PropertyGet || s.Proc == nil {
+					s.Proc = d
+				}
+			}
+			t.globals[key(d.Name)] = s
+		case *ast.DeclareDecl:
+			t.globals[key(d.Name)] = t.declareSymbol(d)
+		}
+	}
+}
+
+// shareSymbols adds the public declarations of a module to symbols, qualified
+// with the name of the class.
+func (t *translator) shareSymbols(symbols map[string]*symbol) {
+	for _, d := range t.file.Decls {
+		var name, vis string
+		switch d := d.(type) {
+		case *ast.VarDecl:
+			vis = visibility(d.Keyword, "private")
+			for _, spec := range d.Vars {
+				if vis == "public" {
+					t.share(symbols, spec.Name)
+				}
+			}
+			continue
+		case *ast.ConstDecl:
+			vis = visibility(d.Visibility, "private")
+			for _, spec := range d.Consts {
+				if vis == "public" {
+					t.share(symbols, spec.Name)
+				}
+			}
+			continue
+		case *ast.ProcDecl:
+			name, vis = d.Name, visibility(d.Visibility, "public")
+		case *ast.DeclareDecl:
+			name, vis = d.Name, visibility(d.Visibility, "public")
+		}
+		if vis != "private" {
+			t.share(symbols, name)
+		}
+	}
+}
+
+func (t *translator) share(symbols map[string]*symbol, name string) {
+	if name == "" {
+		return
+	}
+	k := key(name)
+	s := *t.globals[k]
+	s.Code = t.name + "." + s.Code
+	symbols[k] = &s
+}
+
+func (t *translator) returnType(proc *ast.ProcDecl) string {
+	ref := proc.Returns
+	if ref == nil {
+		ref = typeFromSuffix(proc.Name)
+	}
+	return t.refType(ref, 0)
+}
+
+// propertyParams returns the number of index parameters of a property.
+func (t *translator) propertyParams(proc *ast.ProcDecl) int {
+	if proc.Kind == ast.PropertyGet {
+		return len(proc.Params)
+	}
+	return max(len(proc.Params)-1, 0)
+}
+
+func (t *translator) propertyType(proc *ast.ProcDecl, prev string) string {
+	switch {
+	case proc.Kind == ast.PropertyGet:
+		return t.returnType(proc)
+	case prev != "":
+		return prev
+	case len(proc.Params) > 0:
+		return t.paramType(proc.Params[len(proc.Params)-1])
+	}
+	return "dynamic"
+}
+
+func (t *translator) constType(spec *ast.ConstSpec) string {
+	if spec.Type != nil {
+		return t.refType(spec.Type, 0)
+	}
+	if ref := typeFromSuffix(spec.Name); ref != nil {
+		return t.refType(ref, 0)
+	}
+	// The type of values that cannot be translated is unknown, the constant
+	// itself is reported when it is written
+	typ := "dynamic"
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				if _, ok := r.(untranslatable); !ok {
+					panic(r)
+				}
+			}
+		}()
+		typ = t.value(spec.Value).typ
+	}()
+	if typ == "" {
+		typ = "dynamic"
+	}
+	return typ
+}
+
+func visibility(v string, fallback string) string {
+	switch lower(v) {
+	case "public", "global":
+		return "public"
+	case "friend":
+		return "internal"
+	case "private", "dim":
+		return "private"
+	}
+	return fallback
+}
+
+func (t *translator) modifiers(v string, fallback string) string {
+	mod := visibility(v, fallback)
+	if t.static {
+		mod += " static"
+	}
+	return mod
+}
+
+// todo writes a statement that cannot be translated as commented out VB6.
+func (t *translator) todo(n ast.Node, reason string) {
+	t.w.Writef("// TODO: %s", reason)
+	for _, line := range strings.Split(t.file.Text(n), "\n") {
+		t.w.Writef("// %s", strings.TrimSpace(line))
+	}
+	t.todos = append(t.todos, CodeIssue{Line: n.Pos().Line, Reason: reason})
+}
+
+// separate writes a blank line between members, keeping comments together
+// with the member that follows them.
+func (t *translator) separate() {
+	if !t.comment {
+		t.w.Writeln()
+	}
+	t.comment = false
+}
+
+// endBlock separates fields from the multi-line member written before them.
+func (t *translator) endBlock() {
+	if t.block && !t.comment {
+		t.w.Writeln()
+	}
+	t.block = false
+	t.comment = false
+}
+
+// splitDecls splits the declarations into the declarations section and the
+// procedures. Comments directly above the first procedure belong to it.
+func (t *translator) splitDecls() ([]ast.Decl, []ast.Decl) {
+	first := len(t.file.Decls)
+	for i, d := range t.file.Decls {
+		if _, ok := d.(*ast.ProcDecl); ok {
+			first = i
+			break
+		}
+	}
+	for first > 0 {
+		if _, ok := t.file.Decls[first-1].(*ast.CommentStmt); !ok {
+			break
+		}
+		first--
+	}
+	decls := make([]ast.Decl, 0, first)
+	for _, d := range t.file.Decls[:first] {
+		switch d.(type) {
+		case *ast.OptionDecl, *ast.AttributeDecl:
+		default:
+			decls = append(decls, d)
+		}
+	}
+	return decls, t.file.Decls[first:]
+}
+
+// writeDecls writes the translated declarations as class members.
+func (t *translator) writeDecls(decls []ast.Decl) {
+	written := make(map[string]bool)
+	for _, d := range decls {
+		switch d := d.(type) {
+		case *ast.OptionDecl, *ast.AttributeDecl:
+		case *ast.CommentStmt:
+			if !t.comment && !d.Trailing {
+				t.w.Writeln()
+			}
+			t.w.Writef("//%s", d.Text)
+			t.comment = true
+			t.block = false
+		case *ast.VarDecl:
+			t.writeFields(d)
+		case *ast.ConstDecl:
+			t.writeConsts(d)
+		case *ast.ProcDecl:
+			switch d.Kind {
+			case ast.SubProc, ast.FunctionProc:
+				t.separate()
+				if reason, ok := t.persistenceEvent(d); ok {
+					t.todo(d, reason)
+				} else {
+					t.writeProc(d)
+				}
+				t.block = true
+			default:
+				if written[key(d.Name)] {
+					continue
+				}
+				written[key(d.Name)] = true
+				t.separate()
+				t.writeProperty(d.Name)
+				t.block = true
+			}
+		case *ast.DeclareDecl:
+			t.separate()
+			t.writeDeclare(d)
+			t.block = true
+		case *ast.TypeDecl:
+			if t.namespaceType(d.Visibility) {
+				continue
+			}
+			t.separate()
+			t.writeType(d)
+			t.block = true
+		case *ast.EnumDecl:
+			if t.namespaceType(d.Visibility) {
+				continue
+			}
+			t.separate()
+			t.writeEnum(d)
+			t.block = true
+		case *ast.EventDecl:
+			t.separate()
+			t.writeEvent(d)
+			t.block = true
+		case *ast.ImplementsDecl:
+			t.writeDeclTodo(d, "Implements is not supported")
+		case *ast.DirectiveStmt:
+			t.writeDeclTodo(d, "conditional compilation is not supported")
+		case *ast.BadStmt:
+			t.writeDeclTodo(d, "unable to parse declaration")
+		}
+	}
+}
+
+// writeEvent declares an event together with its delegate type.
+func (t *translator) writeEvent(d *ast.EventDecl) {
+	t.tryDecl(d, func() {
+		name := csName(d.Name)
+		mod := visibility(d.Visibility, "public")
+		t.w.Writef("%s delegate void %sEventHandler(%s);", mod, name, t.params(d.Params))
+		t.w.Writef("%s event %sEventHandler %s;", mod, name, name)
+	})
+}
+
+func (t *translator) writeDeclTodo(n ast.Node, reason string) {
+	t.separate()
+	t.todo(n, reason)
+	t.block = true
+}
+
+// tryDecl runs write and writes the declaration as a TODO when it cannot be
+// translated.
+func (t *translator) tryDecl(n ast.Node, write func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			u, ok := r.(untranslatable)
+			if !ok {
+				panic(r)
+			}
+			t.todo(n, u.reason)
+		}
+	}()
+	write()
+}
+
+func (t *translator) writeFields(d *ast.VarDecl) {
+	for _, spec := range d.Vars {
+		t.endBlock()
+		t.tryDecl(spec, func() {
+			if spec.WithEvents {
+				t.writeWithEvents(d, spec)
+				return
+			}
+			typ := t.specType(spec)
+			init := t.initValue(spec, typ)
+			if init == zeroValue(typ) && typ != "string" {
+				t.w.Writef("%s %s %s;", t.modifiers(d.Keyword, "private"), typ, csName(spec.Name))
+				return
+			}
+			t.w.Writef("%s %s %s = %s;", t.modifiers(d.Keyword, "private"), typ, csName(spec.Name), init)
+		})
+	}
+}
+
+func (t *translator) writeConsts(d *ast.ConstDecl) {
+	t.endBlock()
+	for _, spec := range d.Consts {
+		t.tryDecl(spec, func() {
+			s := t.globals[key(spec.Name)]
+			v := coerce(t.value(spec.Value), s.Type)
+			mod := visibility(d.Visibility, "private")
+			if v.konst && s.Type != "dynamic" {
+				t.w.Writef("%s const %s %s = %s;", mod, s.Type, s.Code, v.code)
+			} else {
+				t.w.Writef("%s static readonly %s %s = %s;", mod, s.Type, s.Code, v.code)
+			}
+		})
+	}
+}
+
+// beginProc prepares the symbol table for translating a procedure.
+func (t *translator) beginProc(proc *ast.ProcDecl) {
+	t.proc = proc
+	t.locals = make(map[string]*symbol)
+	t.result = value{}
+	t.with = nil
+	t.temps = 0
+
+	for _, p := range proc.Params {
+		t.locals[key(p.Name)] = &symbol{Kind: varSymbol, Code: csName(p.Name), Type: t.paramType(p)}
+	}
+
+	if proc.Kind == ast.FunctionProc || proc.Kind == ast.PropertyGet {
+		name := "result"
+		for t.lookup(name) != nil {
+			name += "_"
+		}
+		typ := t.globals[key(proc.Name)].Type
+		t.result = value{code: name, typ: typ, prec: precPrimary, lvalue: true}
+	}
+}
+
+func (t *translator) paramDefault(p *ast.Param) string {
+	typ := t.paramType(p)
+	if p.Default != nil {
+		return coerce(t.value(p.Default), typ).code
+	}
+	return zeroValue(typ)
+}
+
+func (t *translator) params(params []*ast.Param) string {
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		typ := t.paramType(p)
+		decl := fmt.Sprintf("%s %s", typ, csName(p.Name))
+		switch {
+		case p.ParamArray:
+			decl = "params " + decl
+		case p.Optional:
+			decl += " = " + t.paramDefault(p)
+		case p.ByRef && !p.IsArray:
+			decl = "ref " + decl
+		}
+		parts = append(parts, decl)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeProc writes a Sub or Function as a method.
+func (t *translator) writeProc(proc *ast.ProcDecl) {
+	t.beginProc(proc)
+	t.tryDecl(proc, func() {
+		h := t.handlers[proc]
+
+		var sig string
+		switch {
+		case h != nil && h.event.Custom:
+			// Events of user controls are raised with their VB6 parameters
+			if h.control.ArrayName != "" {
+				t.fail("events of control arrays of user controls are not supported")
+			}
+			sig = fmt.Sprintf("private void %s(%s)", csName(proc.Name), t.params(proc.Params))
+		case h != nil:
+			sig = fmt.Sprintf("private void %s(object sender, %s e)", csName(proc.Name), h.event.Args)
+		case proc.Kind == ast.FunctionProc:
+			sig = fmt.Sprintf("%s %s %s(%s)", t.modifiers(proc.Visibility, "public"), t.result.typ, csName(proc.Name), t.params(proc.Params))
+		default:
+			sig = fmt.Sprintf("%s void %s(%s)", t.modifiers(proc.Visibility, "public"), csName(proc.Name), t.params(proc.Params))
+		}
+
+		prologue, epilogue := make([]string, 0), make([]string, 0)
+		if h != nil && !h.event.Custom {
+			prologue, epilogue = t.bindParams(proc, h)
+		}
+
+		t.writeStatics(proc)
+		t.w.Write(sig)
+		t.writeBody(proc.Body, prologue, epilogue)
+	})
+	t.proc = nil
+}
+
+// bindParams turns the parameters of an event handler into locals.
+// Handlers of control arrays receive the index of the control as the first
+// parameter.
+func (t *translator) bindParams(proc *ast.ProcDecl, h *handler) ([]string, []string) {
+	in, out := h.event.In, h.event.Out
+	if h.control.ArrayName != "" {
+		in = append([]string{fmt.Sprintf("Array.IndexOf(this.%s, sender)", h.control.ArrayName)}, in...)
+		out = append([]string{""}, out...)
+	}
+
+	prologue, epilogue := make([]string, 0), make([]string, 0)
+	if h.event.Guard != "" {
+		prologue = append(prologue, fmt.Sprintf("if (!%s) return;", h.event.Guard))
+	}
+	for i, p := range proc.Params {
+		typ := t.paramType(p)
+		init := zeroValue(typ)
+		if i < len(in) {
+			init = in[i]
+		}
+		prologue = append(prologue, fmt.Sprintf("%s %s = %s;", typ, csName(p.Name), init))
+		if i < len(out) && out[i] != "" {
+			epilogue = append(epilogue, fmt.Sprintf(out[i], csName(p.Name)))
+		}
+	}
+	return prologue, epilogue
+}
+
+// writeProperty writes the Property Get, Let and Set procedures of a property
+// as a C# property, or as Get and Set methods for properties with parameters.
+func (t *translator) writeProperty(name string) {
+	var get, let, set *ast.ProcDecl
+	for _, proc := range t.file.Procedures() {
+		if !strings.EqualFold(proc.Name, name) {
+			continue
+		}
+		switch proc.Kind {
+		case ast.PropertyGet:
+			get = proc
+		case ast.PropertyLet:
+			let = proc
+		case ast.PropertySet:
+			set = proc
+		}
+	}
+	if let == nil {
+		let = set
+	} else if set != nil {
+		t.todo(set, "Property Set is ignored in favour of Property Let")
+	}
+
+	s := t.globals[key(name)]
+	first := get
+	if first == nil {
+		first = let
+	}
+	mod := t.modifiers(first.Visibility, "public")
+
+	if s.Indexed {
+		if get != nil {
+			t.beginProc(get)
+			t.tryDecl(get, func() {
+				t.w.Writef("%s %s Get%s(%s)", mod, s.Type, s.Code, t.params(get.Params))
+				t.writeBody(get.Body, nil, nil)
+			})
+		}
+		if let != nil {
+			if get != nil {
+				t.w.Writeln()
+			}
+			t.beginProc(let)
+			t.tryDecl(let, func() {
+				t.w.Writef("%s void Set%s(%s)", mod, s.Code, t.params(let.Params))
+				t.writeBody(let.Body, nil, nil)
+			})
+		}
+		t.proc = nil
+		return
+	}
+
+	if get != nil {
+		t.beginProc(get)
+		t.writeStatics(get)
+	}
+	if let != nil {
+		t.beginProc(let)
+		t.writeStatics(let)
+	}
+
+	t.w.Writef("%s %s %s", mod, s.Type, s.Code)
+	t.w.Write("{")
+	t.w.WriteIndent(func() {
+		if get != nil {
+			t.beginProc(get)
+			t.tryDecl(get, func() {
+				t.w.Write("get")
+				t.writeBody(get.Body, nil, nil)
+			})
+		}
+		if let != nil {
+			t.beginProc(let)
+			if len(let.Params) > 0 {
+				t.locals[key(let.Params[0].Name)].Code = "value"
+			}
+			t.tryDecl(let, func() {
+				t.w.Write("set")
+				t.writeBody(let.Body, nil, nil)
+			})
+		}
+	})
+	t.w.Write("}")
+	t.proc = nil
+}
+
+// walk calls fn for every statement in stmts, including nested statements.
+func walk(stmts []ast.Stmt, fn func(ast.Stmt)) {
+	for _, s := range stmts {
+		fn(s)
+		switch s := s.(type) {
+		case *ast.IfStmt:
+			walk(s.Then, fn)
+			for _, e := range s.ElseIfs {
+				walk(e.Body, fn)
+			}
+			walk(s.Else, fn)
+		case *ast.SelectStmt:
+			for _, c := range s.Cases {
+				walk(c.Body, fn)
+			}
+		case *ast.ForStmt:
+			walk(s.Body, fn)
+		case *ast.ForEachStmt:
+			walk(s.Body, fn)
+		case *ast.DoStmt:
+			walk(s.Body, fn)
+		case *ast.WhileStmt:
+			walk(s.Body, fn)
+		case *ast.WithStmt:
+			walk(s.Body, fn)
+		}
+	}
+}
+
+func (t *translator) isStatic(d *ast.VarDecl) bool {
+	return t.proc.Static || strings.EqualFold(d.Keyword, "Static")
+}
+
+// writeStatics declares the Static locals of a procedure as fields, because
+// C# has no static locals.
+func (t *translator) writeStatics(proc *ast.ProcDecl) {
+	walk(proc.Body, func(s ast.Stmt) {
+		d, ok := s.(*ast.VarDecl)
+		if !ok || !t.isStatic(d) {
+			return
+		}
+		for _, spec := range d.Vars {
+			typ := t.specType(spec)
+			name := csName(proc.Name) + "_" + csName(spec.Name)
+			mod := "private"
+			if t.static {
+				mod += " static"
+			}
+			t.tryDecl(spec, func() {
+				t.w.Writef("%s %s %s = %s;", mod, typ, name, t.initValue(spec, typ))
+			})
+			t.locals[key(spec.Name)] = &symbol{Kind: varSymbol, Code: name, Type: typ}
+		}
+	})
+}
+
+// writeLocals declares all local variables at the top of the procedure, since
+// VB6 locals are visible in the whole procedure.
+func (t *translator) writeLocals(body []ast.Stmt) {
+	walk(body, func(s ast.Stmt) {
+		switch d := s.(type) {
+		case *ast.VarDecl:
+			if t.isStatic(d) {
+				return
+			}
+			for _, spec := range d.Vars {
+				typ := t.specType(spec)
+				t.tryDecl(spec, func() {
+					t.w.Writef("%s %s = %s;", typ, csName(spec.Name), t.initValue(spec, typ))
+				})
+				t.locals[key(spec.Name)] = &symbol{Kind: varSymbol, Code: csName(spec.Name), Type: typ}
+			}
+		case *ast.ConstDecl:
+			for _, spec := range d.Consts {
+				typ := t.constType(spec)
+				t.tryDecl(spec, func() {
+					v := coerce(t.value(spec.Value), typ)
+					if v.konst && typ != "dynamic" {
+						t.w.Writef("const %s %s = %s;", typ, csName(spec.Name), v.code)
+					} else {
+						t.w.Writef("%s %s = %s;", typ, csName(spec.Name), v.code)
+					}
+				})
+				t.locals[key(spec.Name)] = &symbol{Kind: constSymbol, Code: csName(spec.Name), Type: typ}
+			}
+		}
+	})
+}
+
+func hasExit(body []ast.Stmt) bool {
+	found := false
+	walk(body, func(s ast.Stmt) {
+		if e, ok := s.(*ast.ExitStmt); ok {
+			switch lower(e.Kind) {
+			case "sub", "function", "property":
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// writeBody writes the body of a procedure. The prologue and epilogue lines
+// are written before and after the statements.
+func (t *translator) writeBody(body []ast.Stmt, prologue []string, epilogue []string) {
+	t.w.Write("{")
+	t.w.WriteIndent(func() {
+		for _, line := range prologue {
+			t.w.Write(line)
+		}
+		if t.result.code != "" {
+			t.w.Writef("%s %s = %s;", t.result.typ, t.result.code, t.newValue(t.result.typ))
+		}
+		t.writeLocals(body)
+
+		if len(epilogue) > 0 && hasExit(body) {
+			t.w.Write("try")
+			t.w.Write("{")
+			t.w.WriteIndent(func() {
+				t.writeProcStmts(body)
+			})
+			t.w.Write("}")
+			t.w.Write("finally")
+			t.w.Write("{")
+			t.w.WriteIndent(func() {
+				for _, line := range epilogue {
+					t.w.Write(line)
+				}
+			})
+			t.w.Write("}")
+		} else {
+			t.writeProcStmts(body)
+			for _, line := range epilogue {
+				t.w.Write(line)
+			}
+		}
+
+		if t.result.code != "" {
+			t.w.Writef("return %s;", t.result.code)
+		}
+	})
+	t.w.Write("}")
+}
+
+// writeProcStmts writes the statements of a procedure. The common error
+// handling pattern
+//
+//	On Error GoTo Handler
+//	...
+//	Exit Sub
+//	Handler:
+//	...
+//
+// is translated into a try/catch block.
+func (t *translator) writeProcStmts(body []ast.Stmt) {
+	onError, handler := -1, -1
+	for i, s := range body {
+		switch s := s.(type) {
+		case *ast.OnErrorStmt:
+			if onError == -1 && !s.ResumeNext && s.Label != "0" {
+				onError = i
+			}
+		case *ast.LabelStmt:
+			if onError != -1 && handler == -1 && strings.EqualFold(s.Name, body[onError].(*ast.OnErrorStmt).Label) {
+				handler = i
+			}
+		}
+	}
+
+	if onError == -1 || handler == -1 {
+		t.writeStmts(body)
+		return
+	}
+
+	tryBody := body[onError+1 : handler]
+	if n := len(tryBody); n > 0 {
+		if exit, ok := tryBody[n-1].(*ast.ExitStmt); ok && lower(exit.Kind) != "for" && lower(exit.Kind) != "do" {
+			tryBody = tryBody[:n-1]
+		}
+	}
+
+	t.writeStmts(body[:onError])
+	t.w.Write("try")
+	t.w.Write("{")
+	t.w.WriteIndent(func() {
+		t.writeStmts(tryBody)
+	})
+	t.w.Write("}")
+	t.w.Write("catch (Exception ex)")
+	t.w.Write("{")
+	t.w.WriteIndent(func() {
+		t.w.Write("ProjectData.SetProjectError(ex);")
+		t.writeStmts(body[handler+1:])
+	})
+	t.w.Write("}")
+}
+
+func (t *translator) writeStmts(stmts []ast.Stmt) {
+	for _, s := range stmts {
+		t.writeStmt(s)
+	}
+}
+
+// writeBlock writes statements enclosed in braces.
+func (t *translator) writeBlock(stmts []ast.Stmt) {
+	t.w.Write("{")
+	t.w.WriteIndent(func() {
+		t.writeStmts(stmts)
+	})
+	t.w.Write("}")
+}
+
+func (t *translator) writeStmt(s ast.Stmt) {
+	depth := len(t.with)
+	defer func() {
+		if r := recover(); r != nil {
+			u, ok := r.(untranslatable)
+			if !ok {
+				panic(r)
+			}
+			t.with = t.with[:depth]
+			t.todo(s, u.reason)
+		}
+	}()
+	t.stmt(s)
+}
+
+func (t *translator) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.CommentStmt:
+		t.w.Writef("//%s", s.Text)
+	case *ast.VarDecl, *ast.ConstDecl, *ast.AttributeDecl:
+		// Declared at the top of the procedure
+	case *ast.DirectiveStmt:
+		t.fail("conditional compilation is not supported")
+	case *ast.BadStmt:
+		t.fail("unable to parse statement")
+	case *ast.LabelStmt:
+		t.w.Writef("%s: ;", labelName(s.Name))
+	case *ast.AssignStmt:
+		t.w.Write(t.assign(s.Target, s.Value, s.Set))
+	case *ast.CallStmt:
+		if id, ok := s.Fn.(*ast.Ident); ok && key(id.Name) == "propertychanged" && t.isUserControl() && t.lookup(id.Name) == nil {
+			// The designer saves the properties of user controls itself
+			t.w.Writef("// %s", strings.TrimSpace(t.file.Text(s)))
+			break
+		}
+		t.w.Write(t.call(s.Fn, s.Args, true).code + ";")
+	case *ast.IfStmt:
+		t.writeIf(s)
+	case *ast.SelectStmt:
+		t.writeSelect(s)
+	case *ast.ForStmt:
+		t.writeFor(s)
+	case *ast.ForEachStmt:
+		v := t.expr(s.Var)
+		in := t.value(s.In)
+		typ := v.typ
+		if typ == "" {
+			typ = "var"
+		}
+		item := t.temp("item")
+		t.w.Writef("foreach (%s %s in %s)", typ, item, in.code)
+		t.w.Write("{")
+		t.w.WriteIndent(func() {
+			t.w.Writef("%s = %s;", v.code, item)
+			t.writeStmts(s.Body)
+		})
+		t.w.Write("}")
+	case *ast.DoStmt:
+		t.writeDo(s)
+	case *ast.WhileStmt:
+		t.w.Writef("while (%s)", t.cond(s.Cond))
+		t.writeBlock(s.Body)
+	case *ast.WithStmt:
+		v := t.expr(s.X)
+		switch s.X.(type) {
+		case *ast.Ident, *ast.MeExpr:
+		default:
+			tmp := t.temp("with")
+			t.w.Writef("var %s = %s;", tmp, v.code)
+			v = value{code: tmp, typ: v.typ, prec: precPrimary, ctl: v.ctl}
+		}
+		t.with = append(t.with, v)
+		t.writeStmts(s.Body)
+		t.with = t.with[:len(t.with)-1]
+	case *ast.ExitStmt:
+		switch lower(s.Kind) {
+		case "for", "do":
+			t.w.Write("break;")
+		default:
+			if t.result.code != "" {
+				t.w.Writef("return %s;", t.result.code)
+			} else {
+				t.w.Write("return;")
+			}
+		}
+	case *ast.GotoStmt:
+		if s.GoSub {
+			t.fail("GoSub is not supported")
+		}
+		t.w.Writef("goto %s;", labelName(s.Label))
+	case *ast.ReturnStmt:
+		t.fail("GoSub is not supported")
+	case *ast.RaiseEventStmt:
+		t.w.Writef("%s?.Invoke(%s);", csName(s.Name), t.args(s.Args, nil))
+	case *ast.OnErrorStmt:
+		if s.ResumeNext {
+			t.fail("On Error Resume Next is not supported")
+		}
+		t.fail("unstructured error handling is not supported")
+	case *ast.ResumeStmt:
+		t.fail("Resume is not supported")
+	case *ast.EndStmt:
+		t.w.Write("Environment.Exit(0);")
+	case *ast.StopStmt:
+		t.w.Write("System.Diagnostics.Debugger.Break();")
+	case *ast.EraseStmt:
+		for _, x := range s.Vars {
+			v := t.expr(x)
+			t.w.Writef("Array.Clear(%s, 0, %s.Length);", v.code, wrap(v, precPrimary))
+		}
+	case *ast.ReDimStmt:
+		t.writeReDim(s)
+	case *ast.PrintStmt:
+		t.writePrint(s)
+	case *ast.OpenStmt:
+		t.writeOpen(s)
+	case *ast.FileStmt:
+		t.writeFileStmt(s)
+	default:
+		t.fail("unsupported statement")
+	}
+}
+
+// target translates the target of an assignment. It returns the translated
+// target and the format applied to the assigned value.
+func (t *translator) target(x ast.Expr, set bool) (value, string) {
+	switch x := x.(type) {
+	case *ast.Ident:
+		if t.isResult(x.Name) {
+			return t.result, "%s"
+		}
+		if s := t.lookup(x.Name); s != nil {
+			switch s.Kind {
+			case constSymbol, procSymbol:
+				t.fail("cannot assign to %s", x.Name)
+			case controlSymbol:
+				if set {
+					t.fail("cannot assign to %s", x.Name)
+				}
+				return t.defaultTarget(t.expr(x))
+			}
+		} else if m, ok := t.formMember(x.Name); ok {
+			return t.target(m, set)
+		}
+		return t.expr(x), "%s"
+	case *ast.MemberExpr:
+		if x.X != nil && !x.Bang {
+			recv := t.expr(x.X)
+			if recv.ctl != nil {
+				if m, ok := controlMember(recv.ctl, key(x.Name)); ok && !m.Method {
+					return primary(wrap(recv, precPrimary)+"."+m.Name, m.Type), setFormat(m)
+				}
+			}
+		}
+	case *ast.CallExpr:
+		if id, ok := x.Fn.(*ast.Ident); ok {
+			if s := t.lookup(id.Name); s != nil && s.Kind == propSymbol && s.Indexed {
+				args := t.args(x.Args, nil)
+				return value{typ: s.Type}, fmt.Sprintf("%s(%s, %%s)", accessor("Set", s.Code), args)
+			}
+			if key(id.Name) == "mid" {
+				t.fail("the Mid statement is not supported")
+			}
+		}
+	}
+	v := t.expr(x)
+	if v.ctl != nil && !set {
+		return t.defaultTarget(v)
+	}
+	return v, "%s"
+}
+
+func setFormat(m member) string {
+	if m.Set != "" {
+		return m.Set
+	}
+	return "%s"
+}
+
+func (t *translator) defaultTarget(v value) (value, string) {
+	name, ok := defaultMembers[shortType(v.ctl.TypeName)]
+	if !ok {
+		t.fail("%s has no default property", v.code)
+	}
+	m, _ := controlMember(v.ctl, name)
+	return primary(wrap(v, precPrimary)+"."+m.Name, m.Type), setFormat(m)
+}
+
+func (t *translator) assign(target ast.Expr, x ast.Expr, set bool) string {
+	var v value
+	if set {
+		v = t.expr(x)
+	} else {
+		v = t.value(x)
+	}
+	lhs, format := t.target(target, set)
+	code := fmt.Sprintf(format, coerce(v, lhs.typ).code)
+	if lhs.code == "" {
+		return code + ";"
+	}
+	return lhs.code + " = " + code + ";"
+}
+
+func (t *translator) writeIf(s *ast.IfStmt) {
+	cond := t.cond(s.Cond)
+	conds := make([]string, 0, len(s.ElseIfs))
+	for _, e := range s.ElseIfs {
+		conds = append(conds, t.cond(e.Cond))
+	}
+
+	t.w.Writef("if (%s)", cond)
+	t.writeBlock(s.Then)
+	for i, e := range s.ElseIfs {
+		t.w.Writef("else if (%s)", conds[i])
+		t.writeBlock(e.Body)
+	}
+	if len(s.Else) > 0 {
+		t.w.Write("else")
+		t.writeBlock(s.Else)
+	}
+}
+
+// writeSelect writes a Select Case statement as an if/else chain, so that
+// Exit For and Exit Do inside a Case keep breaking out of the loop.
+func (t *translator) writeSelect(s *ast.SelectStmt) {
+	subject := t.value(s.X)
+	var decl string
+	switch s.X.(type) {
+	case *ast.Ident, *ast.MemberExpr:
+	default:
+		tmp := t.temp("select")
+		decl = fmt.Sprintf("var %s = %s;", tmp, subject.code)
+		subject = primary(tmp, subject.typ)
+	}
+
+	conds := make([]string, len(s.Cases))
+	for i, c := range s.Cases {
+		if c.Conds == nil {
+			continue
+		}
+		parts := make([]string, 0, len(c.Conds))
+		for _, cond := range c.Conds {
+			var v value
+			switch {
+			case cond.To != nil:
+				lo := t.compare(">=", subject, t.value(cond.Value))
+				hi := t.compare("<=", subject, t.value(cond.To))
+				v = binary(lo, "&&", hi, precAnd, "bool")
+			case cond.Op != "":
+				v = t.compare(cond.Op, subject, t.value(cond.Value))
+			default:
+				v = t.compare("=", subject, t.value(cond.Value))
+			}
+			if len(c.Conds) > 1 {
+				parts = append(parts, wrap(v, precOr+1))
+			} else {
+				parts = append(parts, v.code)
+			}
+		}
+		conds[i] = strings.Join(parts, " || ")
+	}
+
+	if decl != "" {
+		t.w.Write(decl)
+	}
+	first := true
+	for i, c := range s.Cases {
+		switch {
+		case c.Conds == nil && first:
+			t.writeBlock(c.Body)
+		case c.Conds == nil:
+			t.w.Write("else")
+			t.writeBlock(c.Body)
+		case first:
+			t.w.Writef("if (%s)", conds[i])
+			t.writeBlock(c.Body)
+		default:
+			t.w.Writef("else if (%s)", conds[i])
+			t.writeBlock(c.Body)
+		}
+		first = false
+	}
+}
+
+func (t *translator) writeFor(s *ast.ForStmt) {
+	v := t.expr(s.Var)
+	from := coerce(t.value(s.From), v.typ)
+	to := coerce(t.value(s.To), v.typ)
+
+	var cond, next string
+	switch {
+	case s.Step == nil:
+		cond = fmt.Sprintf("%s <= %s", v.code, wrap(to, precRelational+1))
+		next = v.code + "++"
+	default:
+		step := t.value(s.Step)
+		next = fmt.Sprintf("%s += %s", v.code, step.code)
+		switch {
+		case strings.HasPrefix(step.code, "-"):
+			cond = fmt.Sprintf("%s >= %s", v.code, wrap(to, precRelational+1))
+		case step.konst:
+			cond = fmt.Sprintf("%s <= %s", v.code, wrap(to, precRelational+1))
+		default:
+			cond = fmt.Sprintf("%s >= 0 ? %s <= %s : %s >= %s", wrap(step, precRelational+1), v.code, wrap(to, precRelational+1), v.code, wrap(to, precRelational+1))
+		}
+	}
+
+	t.w.Writef("for (%s = %s; %s; %s)", v.code, from.code, cond, next)
+	t.writeBlock(s.Body)
+}
+
+func (t *translator) writeDo(s *ast.DoStmt) {
+	cond := "true"
+	if s.Cond != nil {
+		v := coerce(t.value(s.Cond), "bool")
+		if s.Until {
+			v = value{code: "!" + wrap(v, precUnary), typ: "bool", prec: precUnary}
+		}
+		cond = v.code
+	}
+
+	if s.Post {
+		t.w.Write("do")
+		t.writeBlock(s.Body)
+		t.w.Writef("while (%s);", cond)
+		return
+	}
+	t.w.Writef("while (%s)", cond)
+	t.writeBlock(s.Body)
+}
+
+func (t *translator) writeReDim(s *ast.ReDimStmt) {
+	for _, spec := range s.Vars {
+		sym := t.lookup(spec.Name)
+		if sym == nil || sym.Kind != varSymbol {
+			t.fail("ReDim of undeclared array %s", spec.Name)
+		}
+		typ := sym.Type
+		if spec.Type != nil {
+			typ = t.refType(spec.Type, len(spec.Bounds))
+		}
+		size := t.arraySize(typ, spec.Bounds)
+		switch {
+		case s.Preserve && len(spec.Bounds) > 1:
+			t.fail("ReDim Preserve of multi-dimensional arrays is not supported")
+		case s.Preserve:
+			n := size[strings.Index(size, "[")+1 : len(size)-1]
+			t.w.Writef("Array.Resize(ref %s, %s);", sym.Code, n)
+		default:
+			t.w.Writef("%s = new %s;", sym.Code, size)
+		}
+	}
+}
+
+// printText concatenates the items of a Print statement. It returns the text
+// and whether the statement ends the line.
+func (t *translator) printText(items []*ast.PrintItem) (string, bool) {
+	parts := make([]string, 0, len(items))
+	newline := true
+	for _, item := range items {
+		if item.X != nil {
+			v := t.value(item.X)
+			if v.typ != "string" {
+				v = convert("Convert.ToString", v, "string")
+			}
+			parts = append(parts, wrap(v, precAdditive+1))
+		}
+		if item.Sep == "," {
+			parts = append(parts, `"\t"`)
+		}
+		newline = item.Sep == ""
+	}
+	if len(parts) == 0 {
+		return `""`, newline
+	}
+	return strings.Join(parts, " + "), newline
+}
+
+func (t *translator) writePrint(s *ast.PrintStmt) {
+	text, newline := t.printText(s.Items)
+	if s.File != nil {
+		file := t.value(s.File)
+		if newline {
+			t.w.Writef("FileSystem.PrintLine(%s, %s);", file.code, text)
+		} else {
+			t.w.Writef("FileSystem.Print(%s, %s);", file.code, text)
+		}
+		return
+	}
+	if id, ok := s.Object.(*ast.Ident); !ok || !strings.EqualFold(id.Name, "Debug") {
+		t.fail("Print is only supported for Debug and files")
+	}
+	if newline {
+		t.w.Writef("System.Diagnostics.Debug.WriteLine(%s);", text)
+	} else {
+		t.w.Writef("System.Diagnostics.Debug.Write(%s);", text)
+	}
+}
+
+var (
+	openModes = map[string]string{
+		"input":  "OpenMode.Input",
+		"output": "OpenMode.Output",
+		"append": "OpenMode.Append",
+		"binary": "OpenMode.Binary",
+		"random": "OpenMode.Random",
+	}
+	openAccess = map[string]string{
+		"":           "OpenAccess.Default",
+		"read":       "OpenAccess.Read",
+		"write":      "OpenAccess.Write",
+		"read write": "OpenAccess.ReadWrite",
+	}
+	openShare = map[string]string{
+		"":                "OpenShare.Default",
+		"shared":          "OpenShare.Shared",
+		"lock read":       "OpenShare.LockRead",
+		"lock write":      "OpenShare.LockWrite",
+		"lock read write": "OpenShare.LockReadWrite",
+	}
+)
+
+func (t *translator) writeOpen(s *ast.OpenStmt) {
+	mode, ok := openModes[lower(s.Mode)]
+	if !ok {
+		t.fail("unsupported file mode %s", s.Mode)
+	}
+	args := []string{
+		t.value(s.File).code,
+		coerce(t.value(s.Path), "string").code,
+		mode,
+		openAccess[lower(s.Access)],
+		openShare[lower(s.Lock)],
+	}
+	if s.Len != nil {
+		args = append(args, coerce(t.value(s.Len), "int").code)
+	}
+	t.w.Writef("FileSystem.FileOpen(%s);", strings.Join(args, ", "))
+}
+
+func (t *translator) writeFileStmt(s *ast.FileStmt) {
+	var file string
+	if s.File != nil {
+		file = t.value(s.File).code
+	}
+	arg := func(i int) value {
+		if i >= len(s.Args) || s.Args[i] == nil {
+			t.fail("missing argument")
+		}
+		return t.value(s.Args[i])
+	}
+	variable := func(i int) value {
+		if i >= len(s.Args) || s.Args[i] == nil {
+			t.fail("%s without a variable", s.Op)
+		}
+		target, _ := t.target(s.Args[i], false)
+		return target
+	}
+
+	switch lower(s.Op) {
+	case "close":
+		files := make([]string, 0, len(s.Args)+1)
+		if s.File != nil {
+			files = append(files, file)
+		}
+		for i := range s.Args {
+			files = append(files, arg(i).code)
+		}
+		t.w.Writef("FileSystem.FileClose(%s);", strings.Join(files, ", "))
+	case "input":
+		targets := make([]string, 0, len(s.Args))
+		for i := range s.Args {
+			targets = append(targets, variable(i).code)
+		}
+		for _, target := range targets {
+			t.w.Writef("FileSystem.Input(%s, ref %s);", file, target)
+		}
+	case "line input":
+		if len(s.Args) == 0 || s.Args[0] == nil {
+			t.fail("%s without a variable", s.Op)
+		}
+		target, format := t.target(s.Args[0], false)
+		line := primary(fmt.Sprintf("FileSystem.LineInput(%s)", file), "string")
+		t.w.Writef("%s = %s;", target.code, fmt.Sprintf(format, coerce(line, target.typ).code))
+	case "write":
+		values := []string{file}
+		for i := range s.Args {
+			values = append(values, arg(i).code)
+		}
+		t.w.Writef("FileSystem.WriteLine(%s);", strings.Join(values, ", "))
+	case "get", "put":
+		// The record number can be left out, the variable cannot
+		if len(s.Args) != 2 {
+			t.fail("%s without a variable", s.Op)
+		}
+		pos := "-1"
+		if s.Args[0] != nil {
+			pos = coerce(arg(0), "int").code
+		}
+		target := variable(1)
+		if lower(s.Op) == "get" {
+			t.w.Writef("FileSystem.FileGet(%s, ref %s, %s);", file, target.code, pos)
+		} else {
+			t.w.Writef("FileSystem.FilePut(%s, %s, %s);", file, target.code, pos)
+		}
+	case "seek":
+		t.w.Writef("FileSystem.Seek(%s, %s);", file, coerce(arg(0), "int").code)
+	default:
+		t.fail("unsupported file statement %s", s.Op)
+	}
+}
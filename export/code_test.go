@@ -0,0 +1,602 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+// testForm is the form the code of the form tests is added to.
+const testForm = `VERSION 5.00
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin VB.CommandButton cmdOk
+      Caption         =   "OK"
+      Height          =   375
+      Left            =   120
+      TabIndex        =   1
+      Top             =   120
+      Width           =   1000
+   End
+   Begin VB.TextBox txtName
+      Height          =   285
+      Index           =   0
+      Left            =   120
+      TabIndex        =   0
+      Top             =   600
+      Width           =   2000
+   End
+   Begin VB.Menu mnuPopup
+      Caption         =   "Popup"
+      Visible         =   0   'False
+      Begin VB.Menu mnuPopupCopy
+         Caption         =   "&Copy"
+      End
+   End
+End
+Attribute VB_Name = "frmTest"
+`
+
+// testClass is a class of the project the code of the tests can use.
+const testClass = `Option Explicit
+Public Event Changed(ByVal n As Long)
+Public Event Done()
+`
+
+// translate exports VB6 code as a module, class or form and returns the
+// members of the generated C# class, unindented.
+func translate(t *testing.T, kind string, script string) string {
+	t.Helper()
+	p := &ProjectInfo{Name: "Test", Namespace: "Test", Output: t.TempDir()}
+	p.RegisterClass(&vb6.Class{Name: "CThing", Script: testClass})
+
+	var name string
+	switch kind {
+	case "module":
+		name = "modTest"
+		ExportModules(p, []*vb6.Module{{Name: name, Script: script}})
+	case "class":
+		name = "CTest"
+		ExportClass(p, &vb6.Class{Name: name, Script: script})
+	case "form":
+		name = "frmTest"
+		filename := filepath.Join(t.TempDir(), name+".frm")
+		if err := os.WriteFile(filename, []byte(testForm+script), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := vb6.Load(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.RegisterForm(f)
+		Export(p, f)
+	}
+
+	code, err := os.ReadFile(filepath.Join(p.Output, name+".cs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The types of modules are written before the class
+	lines := strings.Split(string(code), "\n")
+	start := slices.IndexFunc(lines, func(line string) bool {
+		return strings.Contains(line, " class "+name)
+	})
+	end := slices.Index(lines[start+1:], "}")
+	if start == -1 || end == -1 {
+		t.Fatalf("no class in\n%s", code)
+	}
+	members := lines[start+2 : start+1+end]
+	for i, line := range members {
+		members[i] = strings.TrimPrefix(line, "\t")
+	}
+	return strings.Join(members, "\n") + "\n"
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		script string
+		want   string
+	}{
+		{
+			name: "locals and loops",
+			kind: "module",
+			script: `Public Function Sum(ByVal n As Integer) As Long
+    Dim i As Integer
+    For i = 1 To n
+        Sum = Sum + i
+    Next
+End Function
+`,
+			want: `public static int Sum(int n)
+{
+	int result = 0;
+	int i = 0;
+	for (i = 1; i <= n; i++)
+	{
+		result = result + i;
+	}
+	return result;
+}
+`,
+		},
+		{
+			name: "conditions",
+			kind: "module",
+			script: `Private Function Grade(ByVal score As Integer) As String
+    If score >= 90 Then
+        Grade = "A"
+    ElseIf score >= 50 And score < 90 Then
+        Grade = "B"
+    Else
+        Grade = "C" & score
+    End If
+End Function
+`,
+			want: `private static string Grade(int score)
+{
+	string result = "";
+	if (score >= 90)
+	{
+		result = "A";
+	}
+	else if (score >= 50 & score < 90)
+	{
+		result = "B";
+	}
+	else
+	{
+		result = "C" + score;
+	}
+	return result;
+}
+`,
+		},
+		{
+			name: "select case",
+			kind: "module",
+			script: `Public Sub Check(ByVal n As Long)
+    Select Case n
+    Case 1, 2
+        Debug.Print "low"
+    Case 3 To 5
+        MsgBox "mid"
+    Case Is > 5
+        Exit Sub
+    Case Else
+        n = -n
+    End Select
+End Sub
+`,
+			want: `public static void Check(int n)
+{
+	if (n == 1 || n == 2)
+	{
+		System.Diagnostics.Debug.WriteLine("low");
+	}
+	else if (n >= 3 && n <= 5)
+	{
+		Interaction.MsgBox("mid");
+	}
+	else if (n > 5)
+	{
+		return;
+	}
+	else
+	{
+		n = -n;
+	}
+}
+`,
+		},
+		{
+			name: "constants and globals",
+			kind: "module",
+			script: `Public Const MAX_ITEMS = 10
+Private Const TITLE As String = "Test"
+Public Count As Long
+Private mNames() As String
+
+Public Sub Grow()
+    ReDim Preserve mNames(Count + MAX_ITEMS)
+    mNames(0) = TITLE
+End Sub
+`,
+			want: `public const int MAX_ITEMS = 10;
+private const string TITLE = "Test";
+public static int Count;
+private static string[] mNames;
+
+public static void Grow()
+{
+	Array.Resize(ref mNames, Count + MAX_ITEMS + 1);
+	mNames[0] = TITLE;
+}
+`,
+		},
+		{
+			name: "byref arguments",
+			kind: "module",
+			script: `Private Sub Swap(ByRef a As Long, ByRef b As Long)
+    Dim t As Long
+    t = a: a = b: b = t
+End Sub
+
+Public Sub Test()
+    Dim x As Long, y As Long
+    Swap x, y
+    Call Swap(x, y)
+End Sub
+`,
+			want: `private static void Swap(ref int a, ref int b)
+{
+	int t = 0;
+	t = a;
+	a = b;
+	b = t;
+}
+
+public static void Test()
+{
+	int x = 0;
+	int y = 0;
+	Swap(ref x, ref y);
+	Swap(ref x, ref y);
+}
+`,
+		},
+		{
+			name: "do loops and strings",
+			kind: "module",
+			script: `Public Function Trimmed(ByVal s As String) As String
+    Do While Left$(s, 1) = " "
+        s = Mid$(s, 2)
+    Loop
+    Trimmed = UCase(s) & Len(s)
+End Function
+`,
+			want: `public static string Trimmed(string s)
+{
+	string result = "";
+	while (Strings.Left(s, 1) == " ")
+	{
+		s = Strings.Mid(s, 2);
+	}
+	result = Strings.UCase(s) + Strings.Len(s);
+	return result;
+}
+`,
+		},
+		{
+			name: "file statements",
+			kind: "module",
+			script: `Public Sub Load(ByVal path As String)
+    Dim f As Integer, rec As Long
+    f = FreeFile
+    Open path For Binary As #f
+    Get #f, , rec
+    Put #f, ,
+    Close #f
+End Sub
+`,
+			want: `public static void Load(string path)
+{
+	int f = 0;
+	int rec = 0;
+	f = FileSystem.FreeFile();
+	FileSystem.FileOpen(f, path, OpenMode.Binary, OpenAccess.Default, OpenShare.Default);
+	FileSystem.FileGet(f, ref rec, -1);
+	// TODO: Put without a variable
+	// Put #f, ,
+	FileSystem.FileClose(f);
+}
+`,
+		},
+		{
+			name: "untranslatable statement",
+			kind: "module",
+			script: `Public Sub Fail()
+    On Error GoTo Handler
+    Exit Sub
+Handler:
+    Resume Next
+End Sub
+`,
+			want: `public static void Fail()
+{
+	try
+	{
+	}
+	catch (Exception ex)
+	{
+		ProjectData.SetProjectError(ex);
+		// TODO: Resume is not supported
+		// Resume Next
+	}
+}
+`,
+		},
+		{
+			name: "properties",
+			kind: "class",
+			script: `Option Explicit
+Private mValue As Long
+
+Public Property Get Value() As Long
+    Value = mValue
+End Property
+
+Public Property Let Value(ByVal v As Long)
+    mValue = v
+End Property
+`,
+			want: `private int mValue;
+
+public int Value
+{
+	get
+	{
+		int result = 0;
+		result = mValue;
+		return result;
+	}
+	set
+	{
+		mValue = value;
+	}
+}
+`,
+		},
+		{
+			name: "initialize and events",
+			kind: "class",
+			script: `Option Explicit
+Public Event Changed(ByVal n As Long)
+Private mCount As Long
+
+Private Sub Class_Initialize()
+    mCount = 1
+End Sub
+
+Public Sub Touch()
+    mCount = mCount + 1
+    RaiseEvent Changed(mCount)
+End Sub
+`,
+			want: `public delegate void ChangedEventHandler(int n);
+public event ChangedEventHandler Changed;
+
+private int mCount;
+
+public CTest()
+{
+	Class_Initialize();
+}
+
+private void Class_Initialize()
+{
+	mCount = 1;
+}
+
+public void Touch()
+{
+	mCount = mCount + 1;
+	Changed?.Invoke(mCount);
+}
+`,
+		},
+		{
+			name: "types and enums",
+			kind: "class",
+			script: `Option Explicit
+Private Type Item
+    Name As String
+    Values(1 To 3) As Long
+End Type
+
+Private Enum Kind
+    None
+    Big = 10
+End Enum
+
+Private mItem As Item
+Private mKind As Kind
+
+Public Sub Fill()
+    mItem.Name = "x"
+    mItem.Values(1) = Big
+    mKind = Kind.None
+End Sub
+`,
+			want: `private struct Item
+{
+	public string Name;
+	public int[] Values;
+
+	public static Item Create()
+	{
+		Item value = new Item();
+		value.Name = "";
+		value.Values = new int[4];
+		return value;
+	}
+}
+
+private enum Kind
+{
+	None,
+	Big = 10,
+}
+
+private Item mItem = Item.Create();
+private int mKind;
+
+public void Fill()
+{
+	mItem.Name = "x";
+	mItem.Values[1] = ((int)Kind.Big);
+	mKind = ((int)Kind.None);
+}
+`,
+		},
+		{
+			name: "declare",
+			kind: "module",
+			script: `Public Declare Function GetTickCount Lib "kernel32" () As Long
+Private Declare Sub Sleep Lib "kernel32" Alias "Sleep" (ByVal ms As Long)
+Private Declare Function SendMessage Lib "user32" Alias "SendMessageA" (ByVal hWnd As Long, ByVal msg As Long, ByVal wParam As Long, lParam As Any) As Long
+
+Public Sub Wait()
+    Sleep GetTickCount Mod 10
+    SendMessage 0, 0, 0, 0
+End Sub
+`,
+			want: `[System.Runtime.InteropServices.DllImport("kernel32")]
+public static extern int GetTickCount();
+
+[System.Runtime.InteropServices.DllImport("kernel32", EntryPoint = "Sleep")]
+private static extern void Sleep(int ms);
+
+// TODO: As Any parameters are not supported
+// Private Declare Function SendMessage Lib "user32" Alias "SendMessageA" (ByVal hWnd As Long, ByVal msg As Long, ByVal wParam As Long, lParam As Any) As Long
+
+public static void Wait()
+{
+	Sleep(GetTickCount() % 10);
+	// TODO: SendMessage has As Any parameters, which are not supported
+	// SendMessage 0, 0, 0, 0
+}
+`,
+		},
+		{
+			name: "withevents",
+			kind: "class",
+			script: `Option Explicit
+Private WithEvents mThing As CThing
+Private WithEvents mBox As TextBox
+
+Private Sub mThing_Changed(ByVal n As Long)
+    Set mThing = Nothing
+End Sub
+`,
+			want: `private CThing _mThing;
+
+private CThing mThing
+{
+	get { return _mThing; }
+	set
+	{
+		if (_mThing != null)
+		{
+			_mThing.Changed -= mThing_Changed;
+		}
+		_mThing = value;
+		if (_mThing != null)
+		{
+			_mThing.Changed += mThing_Changed;
+		}
+	}
+}
+
+// TODO: the events of mBox are not handled, WithEvents is only supported for the classes of the project
+private System.Windows.Forms.TextBox mBox;
+
+private void mThing_Changed(int n)
+{
+	mThing = null;
+}
+`,
+		},
+		{
+			name: "control properties",
+			kind: "form",
+			script: `Private Sub Form_Load()
+    Me.Caption = "Loaded"
+    txtName(0).Text = Caption
+    cmdOk.Enabled = False
+End Sub
+`,
+			want: `private void Form_Load(object sender, EventArgs e)
+{
+	this.Text = "Loaded";
+	txtName[0].Text = this.Text;
+	cmdOk.Enabled = false;
+}
+`,
+		},
+		{
+			name: "event handlers",
+			kind: "form",
+			script: `Private Sub cmdOk_Click()
+    Unload Me
+End Sub
+
+Private Sub txtName_KeyPress(Index As Integer, KeyAscii As Integer)
+    If KeyAscii = 13 Then KeyAscii = 0
+End Sub
+`,
+			want: `private void cmdOk_Click(object sender, EventArgs e)
+{
+	this.Close();
+}
+
+private void txtName_KeyPress(object sender, KeyPressEventArgs e)
+{
+	int Index = Array.IndexOf(this.txtName, sender);
+	int KeyAscii = e.KeyChar;
+	if (KeyAscii == 13)
+	{
+		KeyAscii = 0;
+	}
+	if (KeyAscii == 0) e.Handled = true; else e.KeyChar = (char)KeyAscii;
+}
+`,
+		},
+		{
+			name: "popup menu",
+			kind: "form",
+			script: `Private Sub txtName_MouseUp(Index As Integer, Button As Integer, Shift As Integer, X As Single, Y As Single)
+    If Button = vbRightButton Then PopupMenu mnuPopup
+End Sub
+
+Private Sub mnuPopupCopy_Click()
+    Clipboard.SetText txtName(0).SelText
+End Sub
+`,
+			want: `private void txtName_MouseUp(object sender, MouseEventArgs e)
+{
+	int Index = Array.IndexOf(this.txtName, sender);
+	int Button = (int)e.Button >> 20;
+	int Shift = (int)Control.ModifierKeys >> 16;
+	float X = e.X;
+	float Y = e.Y;
+	if (Button == 2)
+	{
+		mnuPopup.Show(Cursor.Position);
+	}
+}
+
+private void mnuPopupCopy_Click(object sender, EventArgs e)
+{
+	Clipboard.SetText(txtName[0].SelectedText);
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := translate(t, test.kind, test.script)
+			if !strings.Contains(got, test.want) {
+				t.Errorf("want\n%s\nin\n%s", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,607 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/guthius/vb6conv/vb6"
+	"github.com/guthius/vb6conv/vb6/frx"
+)
+
+// getItems returns the numbered sub-blocks of a property block, such as
+// Button1, Button2, ... of the Buttons of a toolbar.
+func getItems(props vb6.PropertyMap, format string) []vb6.PropertyMap {
+	items := make([]vb6.PropertyMap, 0)
+	for i := 1; ; i++ {
+		item, ok := vb6.GetBlock(fmt.Sprintf(format, i), props)
+		if !ok {
+			return items
+		}
+		items = append(items, item)
+	}
+}
+
+// getSubItems returns the numbered sub-blocks of a nested property block.
+func getSubItems(c *vb6.Control, name string, format string) []vb6.PropertyMap {
+	block, ok := vb6.GetBlock(name, c.Properties)
+	if !ok {
+		return nil
+	}
+	return getItems(block, format)
+}
+
+// applyImageList references the ImageList named by a property of the control.
+func applyImageList(c *vb6.Control, key string, props map[string]string, target string) {
+	if name, ok := vb6.GetStr(key, c.Properties); ok && name != "" {
+		props[target] = fmt.Sprintf("this.%s", name)
+	}
+}
+
+// applyLabelEdit maps the LabelEdit property, where 0 means automatic editing.
+// Automatic is the default when the property is missing.
+func applyLabelEdit(c *vb6.Control, props map[string]string) {
+	labelEdit, ok := vb6.GetInt("LabelEdit", c.Properties)
+	props["LabelEdit"] = toBool(!ok || labelEdit == 0)
+}
+
+func toHorizontalAlignment(alignment int) string {
+	switch alignment {
+	case 1:
+		return "System.Windows.Forms.HorizontalAlignment.Right"
+	case 2:
+		return "System.Windows.Forms.HorizontalAlignment.Center"
+	}
+	return "System.Windows.Forms.HorizontalAlignment.Left"
+}
+
+func ListViewBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	if view, ok := vb6.GetInt("View", c.Properties); ok {
+		switch view {
+		case 0:
+			props["View"] = "System.Windows.Forms.View.LargeIcon"
+		case 1:
+			props["View"] = "System.Windows.Forms.View.SmallIcon"
+		case 2:
+			props["View"] = "System.Windows.Forms.View.List"
+		case 3:
+			props["View"] = "System.Windows.Forms.View.Details"
+		}
+	} else {
+		props["View"] = "System.Windows.Forms.View.LargeIcon"
+	}
+
+	applyLabelEdit(c, props)
+
+	for _, name := range []string{"FullRowSelect", "GridLines", "HideSelection", "MultiSelect", "Checkboxes"} {
+		if v, ok := vb6.GetBool(name, c.Properties); ok {
+			props[name] = toBool(v)
+		}
+	}
+
+	if hide, _ := vb6.GetBool("HideColumnHeaders", c.Properties); hide {
+		props["HeaderStyle"] = "System.Windows.Forms.ColumnHeaderStyle.None"
+	}
+
+	if sorted, _ := vb6.GetBool("Sorted", c.Properties); sorted {
+		if order, _ := vb6.GetInt("SortOrder", c.Properties); order == 1 {
+			props["Sorting"] = "System.Windows.Forms.SortOrder.Descending"
+		} else {
+			props["Sorting"] = "System.Windows.Forms.SortOrder.Ascending"
+		}
+	}
+
+	applyImageList(c, "Icons", props, "LargeImageList")
+	applyImageList(c, "SmallIcons", props, "SmallImageList")
+
+	calls := make([]string, 0)
+	for i := 1; ; i++ {
+		column, ok := vb6.GetBlock(fmt.Sprintf("ColumnHeader(%d)", i), c.Properties)
+		if !ok {
+			break
+		}
+		text, _ := vb6.GetStr("Text", column)
+		width, ok := vb6.GetTwips("Object.Width", column)
+		if !ok {
+			width = 96
+		}
+		alignment, _ := vb6.GetInt("Alignment", column)
+		if key, _ := vb6.GetStr("Key", column); key != "" {
+			calls = append(calls, fmt.Sprintf("Columns.Add(%s, %s, %s, %s, -1)", toStr(key), toStr(text), toInt(width), toHorizontalAlignment(alignment)))
+		} else {
+			calls = append(calls, fmt.Sprintf("Columns.Add(%s, %s, %s)", toStr(text), toInt(width), toHorizontalAlignment(alignment)))
+		}
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.ListView",
+		Resources: make(map[string]any),
+		Props:     props,
+		Calls:     calls,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+func TreeViewBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	// Styles 0-3 have no lines, styles 2, 3, 6 and 7 show the plus/minus signs
+	style, ok := vb6.GetInt("Style", c.Properties)
+	if !ok {
+		style = 7
+	}
+	props["ShowLines"] = toBool(style >= 4)
+	props["ShowPlusMinus"] = toBool(style == 2 || style == 3 || style == 6 || style == 7)
+	if lineStyle, _ := vb6.GetInt("LineStyle", c.Properties); lineStyle == 0 {
+		props["ShowRootLines"] = toBool(false)
+	}
+
+	if indentation, ok := vb6.GetTwips("Indentation", c.Properties); ok {
+		props["Indent"] = toInt(indentation)
+	}
+
+	applyLabelEdit(c, props)
+
+	for _, name := range []string{"HideSelection", "Checkboxes", "FullRowSelect", "Sorted"} {
+		if v, ok := vb6.GetBool(name, c.Properties); ok {
+			props[name] = toBool(v)
+		}
+	}
+
+	if hotTracking, ok := vb6.GetBool("HotTracking", c.Properties); ok {
+		props["HotTracking"] = toBool(hotTracking)
+	}
+
+	applyImageList(c, "ImageList", props, "ImageList")
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.TreeView",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+func ImageListBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+	resources := make(map[string]any)
+	calls := make([]string, 0)
+
+	w, wok := vb6.GetInt("ImageWidth", c.Properties)
+	h, hok := vb6.GetInt("ImageHeight", c.Properties)
+	if wok && hok && w > 0 && h > 0 {
+		props["ImageSize"] = toSize(w, h)
+	}
+
+	if useMaskColor, ok := vb6.GetBool("UseMaskColor", c.Properties); !ok || useMaskColor {
+		if maskColor, ok := vb6.GetColor("MaskColor", c.Properties); ok {
+			props["TransparentColor"] = toColor(maskColor)
+		}
+	}
+
+	props["ColorDepth"] = "System.Windows.Forms.ColorDepth.Depth32Bit"
+
+	addImage := func(i int, image []byte, key string) {
+		resource := fmt.Sprintf("%s.ListImage%d", controlName(c), i)
+		resources[resource] = image
+		source := fmt.Sprintf("((System.Drawing.Image)(resources.GetObject(\"%s\")))", resource)
+		if key != "" {
+			calls = append(calls, fmt.Sprintf("Images.Add(%s, %s)", toStr(key), source))
+		} else {
+			calls = append(calls, fmt.Sprintf("Images.Add(%s)", source))
+		}
+	}
+
+	// MSCOMCTL.OCX stores each image in the FRX, COMCTL32.OCX stores all of
+	// them in a single OLE object blob
+	if images := getSubItems(c, "Images", "ListImage%d"); len(images) > 0 {
+		for i, image := range images {
+			locator, ok := vb6.GetProp("Picture", image)
+			if !ok {
+				continue
+			}
+			bytes, err := frx.LoadBinary(c.Form.Folder, locator)
+			if err != nil {
+				ctx.resourceFailed(c, fmt.Sprintf("ListImage%d", i+1), locator, err)
+				continue
+			}
+			key, _ := vb6.GetStr("Key", image)
+			addImage(i+1, bytes, key)
+		}
+	} else if locator, ok := vb6.GetProp("OleObjectBlob", c.Properties); ok {
+		blob, err := frx.LoadBlob(c.Form.Folder, locator)
+		if err != nil {
+			ctx.resourceFailed(c, "OleObjectBlob", locator, err)
+		} else {
+			for i, image := range frx.ExtractImages(blob) {
+				addImage(i+1, image, "")
+			}
+		}
+	}
+
+	return &Control{
+		Name:        c.Name,
+		TypeName:    "System.Windows.Forms.ImageList",
+		Resources:   resources,
+		Props:       props,
+		Calls:       calls,
+		Children:    make([]*Control, 0),
+		MustInit:    false,
+		SkipAdd:     true,
+		SkipName:    true,
+		IsComponent: true,
+	}
+}
+
+// toolbarButtonBuilder builds a toolbar button from its property block.
+func toolbarButtonBuilder(c *vb6.Control, i int, button vb6.PropertyMap) *Control {
+	props := make(map[string]string)
+
+	typeName := "System.Windows.Forms.ToolStripButton"
+	style, _ := vb6.GetInt("Style", button)
+	switch style {
+	case 1, 2: // Check, ButtonGroup
+		props["CheckOnClick"] = toBool(true)
+	case 3, 4: // Separator, Placeholder
+		typeName = "System.Windows.Forms.ToolStripSeparator"
+	case 5: // DropDown
+		typeName = "System.Windows.Forms.ToolStripDropDownButton"
+	}
+
+	if key, ok := vb6.GetStr("Key", button); ok && key != "" {
+		props["Name"] = toStr(key)
+	}
+
+	if typeName != "System.Windows.Forms.ToolStripSeparator" {
+		if caption, ok := vb6.GetStr("Caption", button); ok {
+			props["Text"] = toStr(caption)
+			props["DisplayStyle"] = "System.Windows.Forms.ToolStripItemDisplayStyle.ImageAndText"
+			props["TextImageRelation"] = "System.Windows.Forms.TextImageRelation.ImageAboveText"
+		} else {
+			props["DisplayStyle"] = "System.Windows.Forms.ToolStripItemDisplayStyle.Image"
+		}
+
+		if toolTipText, ok := vb6.GetStr("Object.ToolTipText", button); ok {
+			props["ToolTipText"] = toStr(toolTipText)
+		}
+
+		if imageIndex, ok := vb6.GetInt("ImageIndex", button); ok && imageIndex > 0 {
+			props["ImageIndex"] = toInt(imageIndex - 1)
+		} else if imageKey, ok := vb6.GetStr("ImageKey", button); ok && imageKey != "" {
+			props["ImageKey"] = toStr(imageKey)
+		}
+
+		if value, _ := vb6.GetInt("Value", button); value == 1 && style != 0 {
+			props["Checked"] = toBool(true)
+		}
+	}
+
+	if enabled, ok := vb6.GetBool("Enabled", button); ok {
+		props["Enabled"] = toBool(enabled)
+	}
+
+	if visible, ok := vb6.GetBool("Visible", button); ok {
+		props["Visible"] = toBool(visible)
+	}
+
+	return &Control{
+		Name:      fmt.Sprintf("%s_Button%d", controlName(c), i),
+		TypeName:  typeName,
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  make([]*Control, 0),
+		MustInit:  false,
+		SkipAdd:   true,
+		SkipName:  props["Name"] != "",
+	}
+}
+
+func ToolbarBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+	propCalls := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	applyImageList(c, "ImageList", props, "ImageList")
+
+	children := make([]*Control, 0)
+	childNames := make([]string, 0)
+	for i, button := range getSubItems(c, "Buttons", "Button%d") {
+		child := toolbarButtonBuilder(c, i+1, button)
+		children = append(children, child)
+		childNames = append(childNames, fmt.Sprintf("this.%s", child.Name))
+	}
+	if len(children) > 0 {
+		propCalls["Items"] = fmt.Sprintf("AddRange(%s)", toArrayOfType(childNames, "System.Windows.Forms.ToolStripItem"))
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.ToolStrip",
+		Resources: make(map[string]any),
+		Props:     props,
+		PropCalls: propCalls,
+		Children:  append(children, buildControlSlice(ctx, c.Children)...),
+		MustInit:  false,
+	}
+}
+
+// statusBarPanelBuilder builds a status bar panel from its property block.
+func statusBarPanelBuilder(c *vb6.Control, i int, panel vb6.PropertyMap) *Control {
+	props := make(map[string]string)
+
+	if key, ok := vb6.GetStr("Key", panel); ok && key != "" {
+		props["Name"] = toStr(key)
+	}
+
+	if text, ok := vb6.GetStr("Text", panel); ok {
+		props["Text"] = toStr(text)
+	}
+
+	if toolTipText, ok := vb6.GetStr("Object.ToolTipText", panel); ok {
+		props["ToolTipText"] = toStr(toolTipText)
+	}
+
+	switch autoSize, _ := vb6.GetInt("AutoSize", panel); autoSize {
+	case 1: // Spring
+		props["Spring"] = toBool(true)
+	case 2: // Contents
+		props["AutoSize"] = toBool(true)
+	default:
+		props["AutoSize"] = toBool(false)
+		if width, ok := vb6.GetTwips("Object.Width", panel); ok {
+			props["Width"] = toInt(width)
+		}
+	}
+
+	switch bevel, ok := vb6.GetInt("Bevel", panel); {
+	case ok && bevel == 0:
+	case ok && bevel == 2:
+		props["BorderSides"] = "System.Windows.Forms.ToolStripStatusLabelBorderSides.All"
+		props["BorderStyle"] = "System.Windows.Forms.Border3DStyle.Raised"
+	default:
+		props["BorderSides"] = "System.Windows.Forms.ToolStripStatusLabelBorderSides.All"
+		props["BorderStyle"] = "System.Windows.Forms.Border3DStyle.SunkenOuter"
+	}
+
+	if alignment, ok := vb6.GetInt("Alignment", panel); ok {
+		switch alignment {
+		case 1:
+			props["TextAlign"] = "System.Drawing.ContentAlignment.MiddleCenter"
+		case 2:
+			props["TextAlign"] = "System.Drawing.ContentAlignment.MiddleRight"
+		default:
+			props["TextAlign"] = "System.Drawing.ContentAlignment.MiddleLeft"
+		}
+	} else {
+		props["TextAlign"] = "System.Drawing.ContentAlignment.MiddleLeft"
+	}
+
+	if enabled, ok := vb6.GetBool("Enabled", panel); ok {
+		props["Enabled"] = toBool(enabled)
+	}
+
+	if visible, ok := vb6.GetBool("Visible", panel); ok {
+		props["Visible"] = toBool(visible)
+	}
+
+	return &Control{
+		Name:      fmt.Sprintf("%s_Panel%d", controlName(c), i),
+		TypeName:  "System.Windows.Forms.ToolStripStatusLabel",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  make([]*Control, 0),
+		MustInit:  false,
+		SkipAdd:   true,
+		SkipName:  props["Name"] != "",
+	}
+}
+
+func StatusBarBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+	propCalls := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	children := make([]*Control, 0)
+	childNames := make([]string, 0)
+
+	// In simple style the status bar shows a single text instead of the panels
+	if style, _ := vb6.GetInt("Style", c.Properties); style == 1 {
+		simpleText, _ := vb6.GetStr("SimpleText", c.Properties)
+		children = append(children, &Control{
+			Name:      fmt.Sprintf("%s_SimpleText", controlName(c)),
+			TypeName:  "System.Windows.Forms.ToolStripStatusLabel",
+			Resources: make(map[string]any),
+			Props:     map[string]string{"Text": toStr(simpleText), "Spring": toBool(true)},
+			Children:  make([]*Control, 0),
+			SkipAdd:   true,
+		})
+	} else {
+		for i, panel := range getSubItems(c, "Panels", "Panel%d") {
+			children = append(children, statusBarPanelBuilder(c, i+1, panel))
+		}
+	}
+	for _, child := range children {
+		childNames = append(childNames, fmt.Sprintf("this.%s", child.Name))
+	}
+	if len(children) > 0 {
+		propCalls["Items"] = fmt.Sprintf("AddRange(%s)", toArrayOfType(childNames, "System.Windows.Forms.ToolStripItem"))
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.StatusStrip",
+		Resources: make(map[string]any),
+		Props:     props,
+		PropCalls: propCalls,
+		Children:  children,
+		MustInit:  false,
+	}
+}
+
+func ProgressBarBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "TabIndex")
+
+	// Min, Max and Value are singles in VB6. They are set in order, the
+	// setters check the value against the range
+	calls := make([]string, 0, 3)
+	for _, name := range []string{"Min", "Max", "Value"} {
+		if v, ok := vb6.GetFloat32(name, c.Properties); ok {
+			switch name {
+			case "Min":
+				calls = append(calls, "Minimum = "+toInt(int(v)))
+			case "Max":
+				calls = append(calls, "Maximum = "+toInt(int(v)))
+			default:
+				calls = append(calls, "Value = "+toInt(int(v)))
+			}
+		}
+	}
+
+	if scrolling, _ := vb6.GetInt("Scrolling", c.Properties); scrolling == 1 {
+		props["Style"] = "System.Windows.Forms.ProgressBarStyle.Continuous"
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.ProgressBar",
+		Resources: make(map[string]any),
+		Props:     props,
+		Calls:     calls,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+func SliderBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	// The range is set before the value, the setters clamp the value to it
+	calls := make([]string, 0, 5)
+	if min, ok := vb6.GetInt("Min", c.Properties); ok {
+		calls = append(calls, "Minimum = "+toInt(min))
+	}
+
+	max, ok := vb6.GetInt("Max", c.Properties)
+	if !ok {
+		max = 10
+	}
+	calls = append(calls, "Maximum = "+toInt(max))
+
+	for _, name := range []string{"SmallChange", "LargeChange", "Value"} {
+		if v, ok := vb6.GetInt(name, c.Properties); ok {
+			calls = append(calls, name+" = "+toInt(v))
+		}
+	}
+
+	if tickFrequency, ok := vb6.GetInt("TickFrequency", c.Properties); ok {
+		props["TickFrequency"] = toInt(tickFrequency)
+	}
+
+	if tickStyle, ok := vb6.GetInt("TickStyle", c.Properties); ok {
+		switch tickStyle {
+		case 0:
+			props["TickStyle"] = "System.Windows.Forms.TickStyle.BottomRight"
+		case 1:
+			props["TickStyle"] = "System.Windows.Forms.TickStyle.TopLeft"
+		case 2:
+			props["TickStyle"] = "System.Windows.Forms.TickStyle.Both"
+		case 3:
+			props["TickStyle"] = "System.Windows.Forms.TickStyle.None"
+		}
+	}
+
+	if orientation, _ := vb6.GetInt("Orientation", c.Properties); orientation == 1 {
+		props["Orientation"] = "System.Windows.Forms.Orientation.Vertical"
+	}
+
+	// The size of a track bar is fixed by default
+	props["AutoSize"] = toBool(false)
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.TrackBar",
+		Resources: make(map[string]any),
+		Props:     props,
+		Calls:     calls,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  true,
+	}
+}
+
+// TabStripBuilder builds a TabControl with empty pages. Unlike SSTab, the VB6
+// TabStrip does not contain the controls shown on its tabs; they are siblings
+// drawn on top of it, so the tab control is sent to the back.
+func TabStripBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+	propCalls := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	applyImageList(c, "ImageList", props, "ImageList")
+
+	if style, _ := vb6.GetInt("Style", c.Properties); style != 0 {
+		props["Appearance"] = "System.Windows.Forms.TabAppearance.Buttons"
+	}
+
+	if multiRow, ok := vb6.GetBool("MultiRow", c.Properties); ok {
+		props["Multiline"] = toBool(multiRow)
+	}
+
+	children := make([]*Control, 0)
+	childNames := make([]string, 0)
+	for i, tab := range getSubItems(c, "Tabs", "Tab%d") {
+		tabProps := make(map[string]string)
+		if caption, ok := vb6.GetStr("Caption", tab); ok {
+			tabProps["Text"] = toStr(caption)
+		}
+		if key, ok := vb6.GetStr("Key", tab); ok && key != "" {
+			tabProps["Tag"] = toStr(key)
+		}
+		if toolTipText, ok := vb6.GetStr("Object.ToolTipText", tab); ok {
+			tabProps["ToolTipText"] = toStr(toolTipText)
+		}
+		if image, ok := vb6.GetInt("ImageIndex", tab); ok && image > 0 {
+			tabProps["ImageIndex"] = toInt(image - 1)
+		}
+		child := &Control{
+			Name:      fmt.Sprintf("%s_Tab%d", controlName(c), i+1),
+			TypeName:  "System.Windows.Forms.TabPage",
+			Resources: make(map[string]any),
+			Props:     tabProps,
+			Children:  make([]*Control, 0),
+			MustInit:  false,
+			SkipAdd:   true,
+		}
+		children = append(children, child)
+		childNames = append(childNames, fmt.Sprintf("this.%s", child.Name))
+	}
+	if len(children) > 0 {
+		propCalls["TabPages"] = fmt.Sprintf("AddRange(%s)", toArrayOfType(childNames, "System.Windows.Forms.TabPage"))
+	}
+
+	return &Control{
+		Name:       c.Name,
+		TypeName:   "System.Windows.Forms.TabControl",
+		Resources:  make(map[string]any),
+		Props:      props,
+		PropCalls:  propCalls,
+		Children:   children,
+		MustInit:   false,
+		SendToBack: true,
+	}
+}
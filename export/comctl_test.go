@@ -0,0 +1,224 @@
+package export
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommonControls(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Object = "{831FDD16-0C5C-11D2-A9FC-0000F8754DA1}#2.0#0"; "MSCOMCTL.OCX"
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   4000
+   ClientWidth     =   6000
+   Begin MSComctlLib.ListView lvwItems
+      Height          =   1500
+      Left            =   120
+      TabIndex        =   0
+      Top             =   120
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   2646
+      View            =   3
+      LabelEdit       =   1
+      FullRowSelect   =   -1  'True
+      GridLines       =   -1  'True
+      _Version        =   393217
+      NumItems        =   2
+      BeginProperty ColumnHeader(1) {BDD1F052-858B-11D1-B16A-00C0F0283628}
+         Text            =   "Name"
+         Object.Width           =   2540
+      EndProperty
+      BeginProperty ColumnHeader(2) {BDD1F052-858B-11D1-B16A-00C0F0283628}
+         Alignment       =   1
+         SubItemIndex    =   1
+         Text            =   "Size"
+         Object.Width           =   1270
+      EndProperty
+   End
+   Begin MSComctlLib.TreeView tvwTree
+      Height          =   1500
+      Left            =   3240
+      TabIndex        =   1
+      Top             =   120
+      Width           =   2000
+      _ExtentX        =   3528
+      _ExtentY        =   2646
+      _Version        =   393217
+      LineStyle       =   1
+      Style           =   7
+      HideSelection   =   0   'False
+   End
+   Begin MSComctlLib.ProgressBar prgLoad
+      Height          =   255
+      Left            =   120
+      TabIndex        =   2
+      Top             =   1800
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   450
+      _Version        =   393216
+      Min             =   10
+      Max             =   50
+      Value           =   20
+   End
+   Begin MSComctlLib.Slider sldZoom
+      Height          =   495
+      Left            =   120
+      TabIndex        =   3
+      Top             =   2160
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   873
+      _Version        =   393216
+      Max             =   20
+      TickFrequency   =   5
+   End
+   Begin MSComctlLib.StatusBar sbrMain
+      Align           =   2  'Align Bottom
+      Height          =   375
+      Left            =   0
+      TabIndex        =   4
+      Top             =   3625
+      Width           =   6000
+      _ExtentX        =   10583
+      _ExtentY        =   661
+      _Version        =   393216
+      BeginProperty Panels {8E3867A5-8586-11D1-B16A-00C0F0283628}
+         NumPanels       =   2
+         BeginProperty Panel1 {8E3867AB-8586-11D1-B16A-00C0F0283628}
+            AutoSize        =   1
+            Text            =   "Ready"
+         EndProperty
+         BeginProperty Panel2 {8E3867AB-8586-11D1-B16A-00C0F0283628}
+            Style           =   6
+         EndProperty
+      EndProperty
+   End
+   Begin MSComctlLib.Toolbar tbrMain
+      Align           =   1  'Align Top
+      Height          =   420
+      Left            =   0
+      TabIndex        =   5
+      Top             =   0
+      Width           =   6000
+      _ExtentX        =   10583
+      _ExtentY        =   741
+      _Version        =   393216
+      BeginProperty Buttons {66833FE8-8583-11D1-B16A-00C0F0283628}
+         NumButtons      =   2
+         BeginProperty Button1 {66833FEA-8583-11D1-B16A-00C0F0283628}
+            Caption         =   "Open"
+            Key             =   "open"
+            ToolTipText     =   "Open a file"
+         EndProperty
+         BeginProperty Button2 {66833FEA-8583-11D1-B16A-00C0F0283628}
+            Style           =   3
+         EndProperty
+      EndProperty
+   End
+   Begin MSComctlLib.TabStrip tabMain
+      Height          =   1000
+      Left            =   3240
+      TabIndex        =   6
+      Top             =   1800
+      Width           =   2000
+      _ExtentX        =   3528
+      _ExtentY        =   1764
+      _Version        =   393216
+      BeginProperty Tabs {1EFB6598-857C-11D1-B16A-00C0F0283628}
+         NumTabs         =   2
+         BeginProperty Tab1 {1EFB659A-857C-11D1-B16A-00C0F0283628}
+            Caption         =   "First"
+         EndProperty
+         BeginProperty Tab2 {1EFB659A-857C-11D1-B16A-00C0F0283628}
+            Caption         =   "Second"
+         EndProperty
+      EndProperty
+   End
+End
+`)
+
+	tests := []struct {
+		name     string
+		typeName string
+		props    map[string]string
+	}{
+		{"lvwItems", "System.Windows.Forms.ListView", map[string]string{
+			"View":          "System.Windows.Forms.View.Details",
+			"LabelEdit":     "false",
+			"FullRowSelect": "true",
+			"GridLines":     "true",
+		}},
+		{"tvwTree", "System.Windows.Forms.TreeView", map[string]string{
+			"ShowLines":     "true",
+			"ShowPlusMinus": "true",
+			"HideSelection": "false",
+		}},
+		{"prgLoad", "System.Windows.Forms.ProgressBar", nil},
+		{"sldZoom", "System.Windows.Forms.TrackBar", map[string]string{
+			"TickFrequency": "5",
+			"AutoSize":      "false",
+		}},
+		{"sbrMain", "System.Windows.Forms.StatusStrip", map[string]string{
+			"Dock": "System.Windows.Forms.DockStyle.Bottom",
+		}},
+		{"sbrMain_Panel1", "System.Windows.Forms.ToolStripStatusLabel", map[string]string{
+			"Text":   `"Ready"`,
+			"Spring": "true",
+		}},
+		{"sbrMain_Panel2", "System.Windows.Forms.ToolStripStatusLabel", map[string]string{
+			"Spring": "",
+		}},
+		{"tbrMain", "System.Windows.Forms.ToolStrip", map[string]string{
+			"Dock": "System.Windows.Forms.DockStyle.Top",
+		}},
+		{"tbrMain_Button1", "System.Windows.Forms.ToolStripButton", map[string]string{
+			"Name": `"open"`,
+			"Text": `"Open"`,
+		}},
+		{"tbrMain_Button2", "System.Windows.Forms.ToolStripSeparator", nil},
+		{"tabMain", "System.Windows.Forms.TabControl", nil},
+		{"tabMain_Tab1", "System.Windows.Forms.TabPage", map[string]string{
+			"Text": `"First"`,
+		}},
+		{"tabMain_Tab2", "System.Windows.Forms.TabPage", map[string]string{
+			"Text": `"Second"`,
+		}},
+	}
+	for _, test := range tests {
+		c := testControl(t, form, test.name)
+		if c.TypeName != test.typeName {
+			t.Errorf("%s is a %s, want %s", test.name, c.TypeName, test.typeName)
+		}
+		checkProps(t, c, test.props)
+	}
+
+	// Columns keep their order, with their widths in pixels
+	calls := testControl(t, form, "lvwItems").Calls
+	want := []string{
+		`Columns.Add("Name", 169, System.Windows.Forms.HorizontalAlignment.Left)`,
+		`Columns.Add("Size", 84, System.Windows.Forms.HorizontalAlignment.Right)`,
+	}
+	if !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	// The range is set before the value, which must lie within it
+	calls = testControl(t, form, "prgLoad").Calls
+	want = []string{"Minimum = 10", "Maximum = 50", "Value = 20"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	// Items and tab pages are added by the parent, not the form
+	for _, name := range []string{"sbrMain_Panel1", "tbrMain_Button1", "tabMain_Tab1"} {
+		if !testControl(t, form, name).SkipAdd {
+			t.Errorf("%s is added to the form", name)
+		}
+	}
+	if _, ok := testControl(t, form, "tabMain").PropCalls["TabPages"]; !ok {
+		t.Error("the tab pages are not added to tabMain")
+	}
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+// CommonDialog flags, the same bit has a different meaning for each dialog
+const (
+	cdlOFNReadOnly         = 0x1
+	cdlOFNOverwritePrompt  = 0x2
+	cdlOFNHideReadOnly     = 0x4
+	cdlOFNNoChangeDir      = 0x8
+	cdlOFNHelpButton       = 0x10
+	cdlOFNAllowMultiselect = 0x200
+	cdlOFNPathMustExist    = 0x800
+	cdlOFNFileMustExist    = 0x1000
+	cdlOFNCreatePrompt     = 0x2000
+	cdlOFNNoDereference    = 0x100000
+
+	cdlCCFullOpen        = 0x2
+	cdlCCPreventFullOpen = 0x4
+	cdlCCHelpButton      = 0x8
+
+	cdlCFHelpButton     = 0x4
+	cdlCFEffects        = 0x100
+	cdlCFApply          = 0x200
+	cdlCFANSIOnly       = 0x400
+	cdlCFNoVectorFonts  = 0x800
+	cdlCFNoSimulations  = 0x1000
+	cdlCFLimitSize      = 0x2000
+	cdlCFFixedPitchOnly = 0x4000
+	cdlCFForceFontExist = 0x10000
+
+	cdlPDNoSelection     = 0x4
+	cdlPDNoPageNums      = 0x8
+	cdlPDPrintToFile     = 0x20
+	cdlPDHelpButton      = 0x800
+	cdlPDHidePrintToFile = 0x100000
+)
+
+// dialogBuilder builds one of the .NET dialogs used by the CommonDialog. The
+// dialogs are components, but have no constructor taking a container.
+func dialogBuilder(c *vb6.Control, suffix string, typeName string, props map[string]string) *Control {
+	return &Control{
+		Name:      fmt.Sprintf("%s_%s", controlName(c), suffix),
+		TypeName:  typeName,
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  make([]*Control, 0),
+		MustInit:  false,
+		SkipAdd:   true,
+		SkipName:  true,
+	}
+}
+
+// applyFileDialogProps maps the properties shared by the open and save dialogs.
+func applyFileDialogProps(c *vb6.Control, flags int, props map[string]string) {
+	if filter, ok := vb6.GetStr("Filter", c.Properties); ok {
+		props["Filter"] = toStr(filter)
+	}
+
+	if filterIndex, ok := vb6.GetInt("FilterIndex", c.Properties); ok {
+		props["FilterIndex"] = toInt(filterIndex)
+	}
+
+	if defaultExt, ok := vb6.GetStr("DefaultExt", c.Properties); ok {
+		props["DefaultExt"] = toStr(defaultExt)
+	}
+
+	if dialogTitle, ok := vb6.GetStr("DialogTitle", c.Properties); ok {
+		props["Title"] = toStr(dialogTitle)
+	}
+
+	if initDir, ok := vb6.GetStr("InitDir", c.Properties); ok {
+		props["InitialDirectory"] = toStr(initDir)
+	}
+
+	if fileName, ok := vb6.GetStr("FileName", c.Properties); ok {
+		props["FileName"] = toStr(fileName)
+	}
+
+	props["CheckFileExists"] = toBool(flags&cdlOFNFileMustExist != 0)
+	props["CheckPathExists"] = toBool(flags&(cdlOFNPathMustExist|cdlOFNFileMustExist) != 0)
+	props["RestoreDirectory"] = toBool(flags&cdlOFNNoChangeDir != 0)
+	props["ShowHelp"] = toBool(flags&cdlOFNHelpButton != 0)
+	if flags&cdlOFNNoDereference != 0 {
+		props["DereferenceLinks"] = toBool(false)
+	}
+}
+
+// CommonDialogBuilder builds the compatibility wrapper of the CommonDialog
+// control, with one .NET dialog component for each of the VB6 dialogs.
+func CommonDialogBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	flags, _ := vb6.GetInt("Flags", c.Properties)
+
+	openProps := make(map[string]string)
+	applyFileDialogProps(c, flags, openProps)
+	openProps["Multiselect"] = toBool(flags&cdlOFNAllowMultiselect != 0)
+	openProps["ReadOnlyChecked"] = toBool(flags&cdlOFNReadOnly != 0)
+	openProps["ShowReadOnly"] = toBool(flags&cdlOFNHideReadOnly == 0)
+
+	saveProps := make(map[string]string)
+	applyFileDialogProps(c, flags, saveProps)
+	saveProps["OverwritePrompt"] = toBool(flags&cdlOFNOverwritePrompt != 0)
+	saveProps["CreatePrompt"] = toBool(flags&cdlOFNCreatePrompt != 0)
+
+	colorProps := make(map[string]string)
+	if color, ok := vb6.GetColor("Color", c.Properties); ok {
+		colorProps["Color"] = toColor(color)
+	}
+	colorProps["FullOpen"] = toBool(flags&cdlCCFullOpen != 0)
+	colorProps["AllowFullOpen"] = toBool(flags&cdlCCPreventFullOpen == 0)
+	colorProps["ShowHelp"] = toBool(flags&cdlCCHelpButton != 0)
+
+	fontProps := make(map[string]string)
+	if name, ok := vb6.GetStr("FontName", c.Properties); ok && name != "" {
+		size, ok := vb6.GetFloat32("FontSize", c.Properties)
+		if !ok || size <= 0 {
+			size = 8.25
+		}
+		fontProps["Font"] = toFont(&vb6.Font{Family: name, Size: size})
+	}
+	fontProps["ShowEffects"] = toBool(flags&cdlCFEffects != 0)
+	fontProps["FontMustExist"] = toBool(flags&cdlCFForceFontExist != 0)
+	fontProps["FixedPitchOnly"] = toBool(flags&cdlCFFixedPitchOnly != 0)
+	fontProps["AllowSimulations"] = toBool(flags&cdlCFNoSimulations == 0)
+	fontProps["AllowVectorFonts"] = toBool(flags&cdlCFNoVectorFonts == 0)
+	fontProps["ScriptsOnly"] = toBool(flags&cdlCFANSIOnly != 0)
+	fontProps["ShowApply"] = toBool(flags&cdlCFApply != 0)
+	fontProps["ShowHelp"] = toBool(flags&cdlCFHelpButton != 0)
+	if flags&cdlCFLimitSize != 0 {
+		if min, ok := vb6.GetInt("Min", c.Properties); ok {
+			fontProps["MinSize"] = toInt(min)
+		}
+		if max, ok := vb6.GetInt("Max", c.Properties); ok {
+			fontProps["MaxSize"] = toInt(max)
+		}
+	}
+
+	printProps := make(map[string]string)
+	printProps["UseEXDialog"] = toBool(true)
+	printProps["AllowSomePages"] = toBool(flags&cdlPDNoPageNums == 0)
+	printProps["AllowSelection"] = toBool(flags&cdlPDNoSelection == 0)
+	printProps["AllowPrintToFile"] = toBool(flags&cdlPDHidePrintToFile == 0)
+	printProps["PrintToFile"] = toBool(flags&cdlPDPrintToFile != 0)
+	printProps["ShowHelp"] = toBool(flags&cdlPDHelpButton != 0)
+
+	children := make([]*Control, 0, 5)
+	for _, d := range []struct {
+		name     string
+		typeName string
+		props    map[string]string
+	}{
+		{"Open", "System.Windows.Forms.OpenFileDialog", openProps},
+		{"Save", "System.Windows.Forms.SaveFileDialog", saveProps},
+		{"Color", "System.Windows.Forms.ColorDialog", colorProps},
+		{"Font", "System.Windows.Forms.FontDialog", fontProps},
+		{"Print", "System.Windows.Forms.PrintDialog", printProps},
+	} {
+		child := dialogBuilder(c, d.name, d.typeName, d.props)
+		props[d.name+"Dialog"] = fmt.Sprintf("this.%s", child.Name)
+		children = append(children, child)
+	}
+
+	if cancelError, ok := vb6.GetBool("CancelError", c.Properties); ok {
+		props["CancelError"] = toBool(cancelError)
+	}
+
+	return &Control{
+		Name:        c.Name,
+		TypeName:    "CommonDialogControl",
+		Resources:   make(map[string]any),
+		Props:       props,
+		Children:    children,
+		MustInit:    false,
+		SkipAdd:     true,
+		SkipName:    true,
+		IsComponent: true,
+	}
+}
@@ -0,0 +1,100 @@
+package export
+
+import "testing"
+
+func TestCommonDialog(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Object = "{F9043C88-F6F2-101A-A3C9-08002B2F49FB}#1.2#0"; "COMDLG32.OCX"
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin MSComDlg.CommonDialog dlgMain
+      Left            =   120
+      Top             =   120
+      _ExtentX        =   847
+      _ExtentY        =   847
+      _Version        =   393216
+      CancelError     =   -1  'True
+      Color           =   255
+      DefaultExt      =   "txt"
+      DialogTitle     =   "Pick a file"
+      Filter          =   "Text files|*.txt|All files|*.*"
+      FilterIndex     =   2
+      Flags           =   12550
+      FontName        =   "Arial"
+      FontSize        =   10
+      Max             =   24
+      Min             =   8
+   End
+End
+`)
+
+	dlg := testControl(t, form, "dlgMain")
+	if dlg.TypeName != "CommonDialogControl" || !dlg.IsComponent {
+		t.Errorf("dlgMain is a %s, want the CommonDialogControl component", dlg.TypeName)
+	}
+	checkProps(t, dlg, map[string]string{
+		"OpenDialog":  "this.dlgMain_Open",
+		"PrintDialog": "this.dlgMain_Print",
+		"CancelError": "true",
+		"Location":    "",
+	})
+
+	// The flags are read by each dialog, the same bit has a different
+	// meaning for each of them
+	tests := []struct {
+		name     string
+		typeName string
+		props    map[string]string
+	}{
+		{"dlgMain_Open", "System.Windows.Forms.OpenFileDialog", map[string]string{
+			"Filter":          `"Text files|*.txt|All files|*.*"`,
+			"FilterIndex":     "2",
+			"DefaultExt":      `"txt"`,
+			"Title":           `"Pick a file"`,
+			"CheckFileExists": "true",
+			"ShowReadOnly":    "false",
+			"Multiselect":     "false",
+		}},
+		{"dlgMain_Save", "System.Windows.Forms.SaveFileDialog", map[string]string{
+			"Title":           `"Pick a file"`,
+			"OverwritePrompt": "true",
+			"CreatePrompt":    "true",
+			"Multiselect":     "",
+		}},
+		{"dlgMain_Color", "System.Windows.Forms.ColorDialog", map[string]string{
+			"Color":         "System.Drawing.Color.FromArgb(255, 0, 0)",
+			"FullOpen":      "true",
+			"AllowFullOpen": "false",
+		}},
+		{"dlgMain_Font", "System.Windows.Forms.FontDialog", map[string]string{
+			"Font":             `new System.Drawing.Font("Arial", 10F, System.Drawing.FontStyle.Regular, System.Drawing.GraphicsUnit.Point, ((byte)(0)))`,
+			"ShowEffects":      "true",
+			"ShowHelp":         "true",
+			"AllowSimulations": "false",
+			"MinSize":          "8",
+			"MaxSize":          "24",
+		}},
+		{"dlgMain_Print", "System.Windows.Forms.PrintDialog", map[string]string{
+			"AllowSelection": "false",
+			"AllowSomePages": "true",
+			"PrintToFile":    "false",
+		}},
+	}
+	for _, test := range tests {
+		c := testControl(t, form, test.name)
+		if c.TypeName != test.typeName {
+			t.Errorf("%s is a %s, want %s", test.name, c.TypeName, test.typeName)
+		}
+		if !c.SkipAdd || c.IsComponent {
+			t.Errorf("%s is added to the form or the components", test.name)
+		}
+		checkProps(t, c, test.props)
+	}
+
+	used := getSupportClasses(form, make(map[string]bool))
+	if !used["CommonDialogControl"] {
+		t.Errorf("got support classes %v, want CommonDialogControl", used)
+	}
+}
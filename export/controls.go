@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
+	"github.com/guthius/vb6conv/resx"
 	"github.com/guthius/vb6conv/vb6"
 	"github.com/guthius/vb6conv/vb6/frx"
 )
@@ -15,14 +17,31 @@ type Control struct {
 	Resources   map[string]any
 	Props       map[string]string
 	PropCalls   map[string]string
+	Calls       []string // Statements on members of the control, written in order after PropCalls
 	Children    []*Control
+	Events      []EventHandler
 	MustInit    bool
 	SkipAdd     bool // Indicates that the control should not be added to the parent's control collection
 	SkipName    bool // Indicates that the control's name property should not be generated
 	IsComponent bool
+	MdiChild    bool   // Form that is shown inside the MDI form
+	Fixed       bool   // Form that can not be moved by the user
+	ArrayName   string // Name of the control array the control belongs to
+	ArrayIndex  int
+	SendToBack  bool         // Control is drawn behind its siblings, like VB6 graphical controls
+	Original    *vb6.Control // VB6 control the control was built from
+	Unsupported bool         // Control is a placeholder for a control that could not be converted
+	ToolTip     string       // Text shown by the ToolTip of the form
+	HelpTopic   int          // HelpContextID shown by the HelpProvider of the form
 }
 
-type ControlBuilder func(c *vb6.Control) *Control
+// BuildContext is the state of the conversion of the controls of a form.
+type BuildContext struct {
+	project         *ProjectInfo
+	failedResources []ResourceIssue // resources that could not be loaded
+}
+
+type ControlBuilder func(ctx *BuildContext, c *vb6.Control) *Control
 
 func applyDefaultProps(c *vb6.Control, props map[string]string) {
 	if visible, ok := vb6.GetBool("Visible", c.Properties); ok {
@@ -33,6 +52,10 @@ func applyDefaultProps(c *vb6.Control, props map[string]string) {
 		props["BackColor"] = toColor(backColor)
 	}
 
+	if foreColor, ok := vb6.GetColor("ForeColor", c.Properties); ok {
+		props["ForeColor"] = toColor(foreColor)
+	}
+
 	if font, ok := vb6.GetFont("Font", c.Properties); ok {
 		props["Font"] = toFont(font)
 	}
@@ -41,11 +64,11 @@ func applyDefaultProps(c *vb6.Control, props map[string]string) {
 func applyDefaultPropsForControl(c *vb6.Control, props map[string]string) {
 	applyDefaultProps(c, props)
 
-	if x, y, ok := vb6.GetVector2("Left", "Top", c.Properties); ok {
+	if x, y, ok := vb6.GetPoint("Left", "Top", c); ok {
 		props["Location"] = toPoint(x, y)
 	}
 
-	if w, h, ok := vb6.GetVector2("Width", "Height", c.Properties); ok {
+	if w, h, ok := vb6.GetExtent("Width", "Height", c); ok {
 		props["Size"] = toSize(w, h)
 	}
 
@@ -54,8 +77,108 @@ func applyDefaultPropsForControl(c *vb6.Control, props map[string]string) {
 	}
 }
 
-func FormBuilder(c *vb6.Control) *Control {
+// cursors maps the MousePointer values to the .NET cursors.
+var cursors = map[int]string{
+	1:  "Arrow",
+	2:  "Cross",
+	3:  "IBeam",
+	4:  "Default",
+	5:  "SizeAll",
+	6:  "SizeNESW",
+	7:  "SizeNS",
+	8:  "SizeNWSE",
+	9:  "SizeWE",
+	10: "UpArrow",
+	11: "WaitCursor",
+	12: "No",
+	13: "AppStarting",
+	14: "Help",
+	15: "SizeAll",
+}
+
+// applyCommonProps maps the properties shared by the controls and forms, the
+// properties mapped by the builder are left alone.
+func applyCommonProps(ctx *BuildContext, c *vb6.Control, control *Control) {
+	if control.Props == nil {
+		control.Props = make(map[string]string)
+	}
+	set := func(key string, value string) {
+		if _, ok := control.Props[key]; !ok {
+			control.Props[key] = value
+		}
+	}
+
+	// Aligned controls are docked, their position is ignored
+	if align, ok := vb6.GetInt("Align", c.Properties); ok {
+		if dock, ok := toDock(align); ok {
+			set("Dock", dock)
+			delete(control.Props, "Location")
+		}
+	}
+
+	if enabled, ok := vb6.GetBool("Enabled", c.Properties); ok {
+		set("Enabled", toBool(enabled))
+	}
+
+	if tabStop, ok := vb6.GetBool("TabStop", c.Properties); ok {
+		set("TabStop", toBool(tabStop))
+	}
+
+	if tag, ok := vb6.GetStr("Tag", c.Properties); ok {
+		set("Tag", toStr(tag))
+	}
+
+	if rightToLeft, ok := vb6.GetBool("RightToLeft", c.Properties); ok && rightToLeft {
+		set("RightToLeft", "System.Windows.Forms.RightToLeft.Yes")
+	}
+
+	if causesValidation, ok := vb6.GetBool("CausesValidation", c.Properties); ok {
+		set("CausesValidation", toBool(causesValidation))
+	}
+
+	// A custom MousePointer uses the MouseIcon
+	mousePointer, _ := vb6.GetInt("MousePointer", c.Properties)
+	if cursor, ok := cursors[mousePointer]; ok {
+		set("Cursor", fmt.Sprintf("System.Windows.Forms.Cursors.%s", cursor))
+	} else if locator, ok := vb6.GetProp("MouseIcon", c.Properties); ok && mousePointer == 99 {
+		bytes, err := frx.LoadBinary(c.Form.Folder, locator)
+		if err != nil {
+			ctx.resourceFailed(c, "MouseIcon", locator, err)
+		} else {
+			resource := fmt.Sprintf("%s.Cursor", controlName(c))
+			control.Resources[resource] = resx.Binary(bytes)
+			set("Cursor", fmt.Sprintf("new System.Windows.Forms.Cursor(new System.IO.MemoryStream((byte[])resources.GetObject(\"%s\")))", resource))
+		}
+	}
+
+	// Controls dragged automatically start dragging when the mouse is pressed
+	if dragMode, _ := vb6.GetInt("DragMode", c.Properties); dragMode == 1 {
+		control.Calls = append(control.Calls, "MouseDown += (sender, e) => ((System.Windows.Forms.Control)sender).DoDragDrop(sender, System.Windows.Forms.DragDropEffects.Move)")
+	}
+
+	if oleDropMode, _ := vb6.GetInt("OLEDropMode", c.Properties); oleDropMode != 0 {
+		set("AllowDrop", toBool(true))
+	}
+
+	if toolTip, ok := vb6.GetStr("ToolTipText", c.Properties); ok && toolTip != "" {
+		control.ToolTip = toolTip
+	}
+
+	if helpContextID, ok := vb6.GetInt("HelpContextID", c.Properties); ok && helpContextID != 0 {
+		control.HelpTopic = helpContextID
+	}
+}
+
+func FormBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	form := buildForm(ctx, c)
+	form.Children = buildControlSlice(ctx, c.Children)
+	return form
+}
+
+// buildForm builds a form without its controls.
+func buildForm(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
+	resources := make(map[string]any)
 
 	applyDefaultProps(c, props)
 
@@ -76,47 +199,204 @@ func FormBuilder(c *vb6.Control) *Control {
 		props["BackColor"] = toColor(backColor)
 	}
 
-	if startUpPosition, ok := vb6.GetInt("StartUpPosition", c.Properties); ok {
-		switch startUpPosition {
-		case 0:
-			props["StartPosition"] = "System.Windows.Forms.FormStartPosition.Manual"
-		case 1:
-			props["StartPosition"] = "System.Windows.Forms.FormStartPosition.CenterParent"
-		case 2:
-			props["StartPosition"] = "System.Windows.Forms.FormStartPosition.CenterScreen"
-		case 3:
-			props["StartPosition"] = "System.Windows.Forms.FormStartPosition.WindowsDefaultLocation"
+	// Forms without a StartUpPosition are placed manually
+	startUpPosition, _ := vb6.GetInt("StartUpPosition", c.Properties)
+	switch startUpPosition {
+	case 0:
+		props["StartPosition"] = "System.Windows.Forms.FormStartPosition.Manual"
+		if x, y, ok := vb6.GetVector2("Left", "Top", c.Properties); ok {
+			props["Location"] = toPoint(x, y)
+		} else if x, y, ok := vb6.GetVector2("ClientLeft", "ClientTop", c.Properties); ok {
+			props["Location"] = toPoint(x, y)
 		}
+	case 1:
+		props["StartPosition"] = "System.Windows.Forms.FormStartPosition.CenterParent"
+	case 2:
+		props["StartPosition"] = "System.Windows.Forms.FormStartPosition.CenterScreen"
+	case 3:
+		props["StartPosition"] = "System.Windows.Forms.FormStartPosition.WindowsDefaultLocation"
 	}
 
+	borderStyle, ok := vb6.GetInt("BorderStyle", c.Properties)
+	if !ok {
+		borderStyle = 2
+	}
+	switch borderStyle {
+	case 0:
+		props["FormBorderStyle"] = "System.Windows.Forms.FormBorderStyle.None"
+	case 1:
+		props["FormBorderStyle"] = "System.Windows.Forms.FormBorderStyle.FixedSingle"
+	case 3:
+		props["FormBorderStyle"] = "System.Windows.Forms.FormBorderStyle.FixedDialog"
+	case 4:
+		props["FormBorderStyle"] = "System.Windows.Forms.FormBorderStyle.FixedToolWindow"
+	case 5:
+		props["FormBorderStyle"] = "System.Windows.Forms.FormBorderStyle.SizableToolWindow"
+	}
+
+	// Dialogs and tool windows have no minimize and maximize buttons and are
+	// not shown in the taskbar, unless the form says otherwise
+	dialog := borderStyle >= 3
+
 	if controlBox, ok := vb6.GetBool("ControlBox", c.Properties); ok {
 		props["ControlBox"] = toBool(controlBox)
 	}
 
-	// TODO: WindowState
-	// TODO: ShowInTaskbar   =   0   'False
+	minButton, ok := vb6.GetBool("MinButton", c.Properties)
+	if !ok {
+		minButton = !dialog
+	}
+	props["MinimizeBox"] = toBool(minButton)
+
+	maxButton, ok := vb6.GetBool("MaxButton", c.Properties)
+	if !ok {
+		maxButton = !dialog
+	}
+	props["MaximizeBox"] = toBool(maxButton)
+
+	showInTaskbar, ok := vb6.GetBool("ShowInTaskbar", c.Properties)
+	if !ok {
+		showInTaskbar = !dialog
+	}
+	if !showInTaskbar {
+		props["ShowInTaskbar"] = toBool(false)
+	}
+
+	switch windowState, _ := vb6.GetInt("WindowState", c.Properties); windowState {
+	case 1:
+		props["WindowState"] = "System.Windows.Forms.FormWindowState.Minimized"
+	case 2:
+		props["WindowState"] = "System.Windows.Forms.FormWindowState.Maximized"
+	}
 
-	if minButton, ok := vb6.GetBool("MinButton", c.Properties); ok {
-		props["MinimizeBox"] = toBool(minButton)
+	if keyPreview, ok := vb6.GetBool("KeyPreview", c.Properties); ok {
+		props["KeyPreview"] = toBool(keyPreview)
 	}
 
-	if maxButton, ok := vb6.GetBool("MaxButton", c.Properties); ok {
-		props["MaximizeBox"] = toBool(maxButton)
+	if locator, ok := vb6.GetProp("Icon", c.Properties); ok {
+		bytes, err := frx.LoadBinary(c.Form.Folder, locator)
+		if err != nil {
+			ctx.resourceFailed(c, "Icon", locator, err)
+		} else {
+			resources["$this.Icon"] = resx.Icon(bytes)
+			props["Icon"] = "((System.Drawing.Icon)(resources.GetObject(\"$this.Icon\")))"
+		}
 	}
 
-	// TODO: KeyPreview
+	mdiChild, _ := vb6.GetBool("MDIChild", c.Properties)
+	moveable, ok := vb6.GetBool("Moveable", c.Properties)
 
 	return &Control{
 		Name:      c.Name,
 		TypeName:  "System.Windows.Forms.Form",
+		Resources: resources,
+		Props:     props,
+		MustInit:  false,
+		MdiChild:  mdiChild,
+		Fixed:     ok && !moveable,
+	}
+}
+
+func MDIFormBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	form := buildForm(ctx, c)
+	form.Props["IsMdiContainer"] = toBool(true)
+
+	// Only aligned PictureBoxes can be placed on MDI forms, they become docked panels
+	form.Children = make([]*Control, 0, len(c.Children))
+	for _, child := range c.Children {
+		var control *Control
+		if child.TypeName == "VB.PictureBox" {
+			control = buildControlWith(ctx, child, AlignedPictureBoxBuilder)
+		} else {
+			control = buildControl(ctx, child)
+		}
+		if control != nil {
+			form.Children = append(form.Children, control)
+		}
+	}
+
+	return form
+}
+
+func AlignedPictureBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "Location")
+
+	if appearance, ok := vb6.GetInt("Appearance", c.Properties); ok && appearance != 0 {
+		props["BorderStyle"] = "System.Windows.Forms.BorderStyle.Fixed3D"
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.Panel",
 		Resources: make(map[string]any),
 		Props:     props,
-		Children:  buildControlSlice(c.Children),
+		Children:  buildControlSlice(ctx, c.Children),
 		MustInit:  false,
 	}
 }
 
-func PictureBoxBuilder(c *vb6.Control) *Control {
+func UserControlBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultProps(c, props)
+
+	props["AutoScaleDimensions"] = toSizeF(6, 13)
+	props["AutoScaleMode"] = "System.Windows.Forms.AutoScaleMode.None"
+
+	if w, h, ok := vb6.GetVector2("ClientWidth", "ClientHeight", c.Properties); ok {
+		props["Size"] = toSize(w, h)
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.UserControl",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// UserControlInstanceBuilder builds an instance of a UserControl from the
+// project (e.g. Begin Project1.ucFoo).
+func UserControlInstanceBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	uc := ctx.project.userControlType(c.TypeName)
+	applyUserControlProps(uc, c, props)
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  uc.Name,
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// loadPicture adds the Picture of a control from the FRX to the resources.
+func loadPicture(ctx *BuildContext, c *vb6.Control, resources map[string]any, props map[string]string) {
+	locator, ok := vb6.GetProp("Picture", c.Properties)
+	if !ok {
+		return
+	}
+	bytes, err := frx.LoadBinary(c.Form.Folder, locator)
+	if err != nil {
+		ctx.resourceFailed(c, "Picture", locator, err)
+		return
+	}
+	resource := fmt.Sprintf("%s.Image", controlName(c))
+	resources[resource] = bytes
+	props["Image"] = fmt.Sprintf("((System.Drawing.Image)(resources.GetObject(\"%s\")))", resource)
+}
+
+func PictureBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
 
 	applyDefaultPropsForControl(c, props)
@@ -134,16 +414,7 @@ func PictureBoxBuilder(c *vb6.Control) *Control {
 	}
 
 	resources := make(map[string]any)
-	if locator, ok := vb6.GetProp("Picture", c.Properties); ok {
-		bytes, err := frx.LoadBinary(c.Form.Folder, locator)
-		if err == nil {
-			resource := fmt.Sprintf("%s.Image", c.Name)
-			resources[resource] = bytes
-			props["Image"] = fmt.Sprintf("((System.Drawing.Image)(resources.GetObject(\"%s\")))", resource)
-		} else {
-			fmt.Printf("unable to load resource: %s (%v)\n", locator, err)
-		}
-	}
+	loadPicture(ctx, c, resources, props)
 
 	props["TabStop"] = "false"
 
@@ -152,12 +423,12 @@ func PictureBoxBuilder(c *vb6.Control) *Control {
 		TypeName:  "System.Windows.Forms.PictureBox",
 		Resources: resources,
 		Props:     props,
-		Children:  buildControlSlice(c.Children),
+		Children:  buildControlSlice(ctx, c.Children),
 		MustInit:  true,
 	}
 }
 
-func LabelBuilder(c *vb6.Control) *Control {
+func LabelBuilder(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
 
 	applyDefaultPropsForControl(c, props)
@@ -173,10 +444,6 @@ func LabelBuilder(c *vb6.Control) *Control {
 		}
 	}
 
-	if foreColor, ok := vb6.GetColor("ForeColor", c.Properties); ok {
-		props["ForeColor"] = toColor(foreColor)
-	}
-
 	if caption, ok := vb6.GetStr("Caption", c.Properties); ok {
 		props["Text"] = toStr(caption)
 	}
@@ -192,12 +459,12 @@ func LabelBuilder(c *vb6.Control) *Control {
 		TypeName:  "System.Windows.Forms.Label",
 		Resources: make(map[string]any),
 		Props:     props,
-		Children:  buildControlSlice(c.Children),
+		Children:  buildControlSlice(ctx, c.Children),
 		MustInit:  false,
 	}
 }
 
-func TextBoxBuilder(c *vb6.Control) *Control {
+func TextBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
 
 	applyDefaultPropsForControl(c, props)
@@ -215,10 +482,6 @@ func TextBoxBuilder(c *vb6.Control) *Control {
 		}
 	}
 
-	if foreColor, ok := vb6.GetColor("ForeColor", c.Properties); ok {
-		props["ForeColor"] = toColor(foreColor)
-	}
-
 	if maxLength, ok := vb6.GetInt("MaxLength", c.Properties); ok {
 		props["MaxLength"] = toInt(maxLength)
 	}
@@ -241,12 +504,12 @@ func TextBoxBuilder(c *vb6.Control) *Control {
 		TypeName:  "System.Windows.Forms.TextBox",
 		Resources: make(map[string]any),
 		Props:     props,
-		Children:  buildControlSlice(c.Children),
+		Children:  buildControlSlice(ctx, c.Children),
 		MustInit:  false,
 	}
 }
 
-func FrameBuilder(c *vb6.Control) *Control {
+func FrameBuilder(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
 
 	applyDefaultPropsForControl(c, props)
@@ -260,12 +523,12 @@ func FrameBuilder(c *vb6.Control) *Control {
 		TypeName:  "System.Windows.Forms.GroupBox",
 		Resources: make(map[string]any),
 		Props:     props,
-		Children:  buildControlSlice(c.Children),
+		Children:  buildControlSlice(ctx, c.Children),
 		MustInit:  false,
 	}
 }
 
-func CommandButtonBuilder(c *vb6.Control) *Control {
+func CommandButtonBuilder(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
 
 	applyDefaultPropsForControl(c, props)
@@ -279,12 +542,12 @@ func CommandButtonBuilder(c *vb6.Control) *Control {
 		TypeName:  "System.Windows.Forms.Button",
 		Resources: make(map[string]any),
 		Props:     props,
-		Children:  buildControlSlice(c.Children),
+		Children:  buildControlSlice(ctx, c.Children),
 		MustInit:  false,
 	}
 }
 
-func ComboBoxBuilder(c *vb6.Control) *Control {
+func ComboBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
 	propCalls := make(map[string]string)
 
@@ -301,38 +564,421 @@ func ComboBoxBuilder(c *vb6.Control) *Control {
 		}
 	}
 
+	loadList(ctx, c, props, propCalls)
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.ComboBox",
+		Resources: make(map[string]any),
+		Props:     props,
+		PropCalls: propCalls,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// loadList adds the List items of a ComboBox or ListBox from the FRX. The
+// ItemData values are stored in the Tag of the control.
+func loadList(ctx *BuildContext, c *vb6.Control, props map[string]string, propCalls map[string]string) {
 	if list, ok := vb6.GetProp("List", c.Properties); ok {
 		items, err := frx.LoadList(c.Form.Folder, list)
 		if err == nil {
 			props["FormattingEnabled"] = toBool(true)
 			propCalls["Items"] = fmt.Sprintf("AddRange(%s)", toObjectArray(items))
+		} else {
+			ctx.resourceFailed(c, "List", list, err)
 		}
 	}
 
+	if itemData, ok := vb6.GetProp("ItemData", c.Properties); ok {
+		values, err := frx.LoadItemData(c.Form.Folder, itemData)
+		if err == nil {
+			items := make([]string, 0, len(values))
+			for _, v := range values {
+				items = append(items, toInt(int(v)))
+			}
+			props["Tag"] = toArrayOfType(items, "int")
+		} else {
+			ctx.resourceFailed(c, "ItemData", itemData, err)
+		}
+	}
+}
+
+func ListBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+	propCalls := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	typeName := "System.Windows.Forms.ListBox"
+	if style, ok := vb6.GetInt("Style", c.Properties); ok && style == 1 {
+		typeName = "System.Windows.Forms.CheckedListBox"
+		props["CheckOnClick"] = toBool(true)
+	}
+
+	// CheckedListBox does not support selecting multiple items
+	if multiSelect, ok := vb6.GetInt("MultiSelect", c.Properties); ok && typeName == "System.Windows.Forms.ListBox" {
+		switch multiSelect {
+		case 1:
+			props["SelectionMode"] = "System.Windows.Forms.SelectionMode.MultiSimple"
+		case 2:
+			props["SelectionMode"] = "System.Windows.Forms.SelectionMode.MultiExtended"
+		}
+	}
+
+	if sorted, ok := vb6.GetBool("Sorted", c.Properties); ok {
+		props["Sorted"] = toBool(sorted)
+	}
+
+	// VB6 list boxes are sized exactly, .NET rounds down to a whole number of items
+	props["IntegralHeight"] = toBool(false)
+
+	loadList(ctx, c, props, propCalls)
+
 	return &Control{
 		Name:      c.Name,
-		TypeName:  "System.Windows.Forms.ComboBox",
+		TypeName:  typeName,
 		Resources: make(map[string]any),
 		Props:     props,
 		PropCalls: propCalls,
-		Children:  buildControlSlice(c.Children),
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// applyButtonProps applies the properties shared by check boxes and option buttons.
+func applyButtonProps(c *vb6.Control, props map[string]string) {
+	if caption, ok := vb6.GetStr("Caption", c.Properties); ok {
+		props["Text"] = toStr(caption)
+	}
+
+	if alignment, ok := vb6.GetInt("Alignment", c.Properties); ok && alignment == 1 {
+		props["CheckAlign"] = "System.Drawing.ContentAlignment.MiddleRight"
+		props["TextAlign"] = "System.Drawing.ContentAlignment.MiddleRight"
+	}
+
+	if style, ok := vb6.GetInt("Style", c.Properties); ok && style == 1 {
+		props["Appearance"] = "System.Windows.Forms.Appearance.Button"
+		props["TextAlign"] = "System.Drawing.ContentAlignment.MiddleCenter"
+	}
+}
+
+func CheckBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	applyButtonProps(c, props)
+
+	if value, ok := vb6.GetInt("Value", c.Properties); ok {
+		switch value {
+		case 1:
+			props["CheckState"] = "System.Windows.Forms.CheckState.Checked"
+		case 2:
+			props["CheckState"] = "System.Windows.Forms.CheckState.Indeterminate"
+		}
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.CheckBox",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+func OptionButtonBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	applyButtonProps(c, props)
+
+	if value, ok := vb6.GetBool("Value", c.Properties); ok {
+		props["Checked"] = toBool(value)
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.RadioButton",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+func scrollBarBuilder(ctx *BuildContext, c *vb6.Control, typeName string) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	min, ok := vb6.GetInt("Min", c.Properties)
+	if !ok {
+		min = 0
+	}
+	max, ok := vb6.GetInt("Max", c.Properties)
+	if !ok {
+		max = 32767
+	}
+	largeChange, ok := vb6.GetInt("LargeChange", c.Properties)
+	if !ok {
+		largeChange = 1
+	}
+
+	// The largest value a .NET scroll bar can be scrolled to is Maximum - LargeChange + 1.
+	// The range is set in order, the setters clamp the value to the range
+	calls := []string{
+		"Minimum = " + toInt(min),
+		"Maximum = " + toInt(max+largeChange-1),
+		"LargeChange = " + toInt(largeChange),
+	}
+
+	if smallChange, ok := vb6.GetInt("SmallChange", c.Properties); ok {
+		calls = append(calls, "SmallChange = "+toInt(smallChange))
+	}
+
+	if value, ok := vb6.GetInt("Value", c.Properties); ok {
+		calls = append(calls, "Value = "+toInt(value))
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  typeName,
+		Resources: make(map[string]any),
+		Props:     props,
+		Calls:     calls,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+func HScrollBarBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	return scrollBarBuilder(ctx, c, "System.Windows.Forms.HScrollBar")
+}
+
+func VScrollBarBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	return scrollBarBuilder(ctx, c, "System.Windows.Forms.VScrollBar")
+}
+
+func ImageBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "TabIndex")
+
+	if stretch, _ := vb6.GetBool("Stretch", c.Properties); stretch {
+		props["SizeMode"] = "System.Windows.Forms.PictureBoxSizeMode.StretchImage"
+	} else {
+		props["SizeMode"] = "System.Windows.Forms.PictureBoxSizeMode.AutoSize"
+	}
+
+	if borderStyle, ok := vb6.GetInt("BorderStyle", c.Properties); ok && borderStyle == 1 {
+		props["BorderStyle"] = "System.Windows.Forms.BorderStyle.FixedSingle"
+	}
+
+	// Image controls are windowless and transparent in VB6
+	props["BackColor"] = "System.Drawing.Color.Transparent"
+	props["TabStop"] = "false"
+
+	resources := make(map[string]any)
+	loadPicture(ctx, c, resources, props)
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.PictureBox",
+		Resources: resources,
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  true,
+	}
+}
+
+func DriveListBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "Size")
+
+	if w, ok := vb6.GetLength("Width", c); ok {
+		props["Width"] = toInt(w)
+	}
+
+	// Placeholder, the drives have to be filled in by the application
+	props["DropDownStyle"] = "System.Windows.Forms.ComboBoxStyle.DropDownList"
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.ComboBox",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// FileSystemListBoxBuilder builds a placeholder for DirListBox and FileListBox,
+// the entries have to be filled in by the application.
+func FileSystemListBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	props["IntegralHeight"] = toBool(false)
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.ListBox",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
 		MustInit:  false,
 	}
 }
 
-func TimerBuilder(c *vb6.Control) *Control {
+// PlaceholderBuilder builds a bordered panel in place of controls without a
+// .NET equivalent, such as the Data and OLE controls.
+func PlaceholderBuilder(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
 
-	if interval, ok := vb6.GetInt("Interval", c.Properties); ok {
+	applyDefaultPropsForControl(c, props)
+	delete(props, "TabIndex")
+
+	props["BorderStyle"] = "System.Windows.Forms.BorderStyle.FixedSingle"
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.Panel",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// UnknownControlBuilder builds a placeholder for controls of a type that can
+// not be converted. The panel keeps the position of the control and shows its
+// type, the original properties are kept as a comment in the designer file.
+func UnknownControlBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	control := PlaceholderBuilder(ctx, c)
+	control.Unsupported = true
+
+	labelProps := make(map[string]string)
+	labelProps["Dock"] = "System.Windows.Forms.DockStyle.Fill"
+	labelProps["TextAlign"] = "System.Drawing.ContentAlignment.MiddleCenter"
+	labelProps["ForeColor"] = "System.Drawing.SystemColors.GrayText"
+	labelProps["Text"] = toStr(fmt.Sprintf("%s %s", c.TypeName, c.Name))
+
+	// Added last so it stays behind the children of the control
+	control.Children = append(control.Children, &Control{
+		Name:      fmt.Sprintf("%s_Label", controlName(c)),
+		TypeName:  "System.Windows.Forms.Label",
+		Resources: make(map[string]any),
+		Props:     labelProps,
+		Children:  make([]*Control, 0),
+		MustInit:  false,
+	})
+
+	return control
+}
+
+func ShapeBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "TabIndex")
+
+	if shape, ok := vb6.GetInt("Shape", c.Properties); ok {
+		props["Shape"] = toInt(shape)
+	}
+
+	applyLineProps(c, props)
+
+	if fillStyle, ok := vb6.GetInt("FillStyle", c.Properties); ok {
+		props["FillStyle"] = toInt(fillStyle)
+	}
+
+	if fillColor, ok := vb6.GetColor("FillColor", c.Properties); ok {
+		props["FillColor"] = toColor(fillColor)
+	}
+
+	if backStyle, ok := vb6.GetInt("BackStyle", c.Properties); ok {
+		props["BackStyle"] = toInt(backStyle)
+	}
+
+	return &Control{
+		Name:       c.Name,
+		TypeName:   "ShapeControl",
+		Resources:  make(map[string]any),
+		Props:      props,
+		Children:   buildControlSlice(ctx, c.Children),
+		MustInit:   false,
+		SendToBack: true,
+	}
+}
+
+func LineBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultProps(c, props)
+	delete(props, "BackColor")
+
+	if x, y, ok := vb6.GetPoint("X1", "Y1", c); ok {
+		props["X1"] = toInt(x)
+		props["Y1"] = toInt(y)
+	}
+
+	if x, y, ok := vb6.GetPoint("X2", "Y2", c); ok {
+		props["X2"] = toInt(x)
+		props["Y2"] = toInt(y)
+	}
+
+	applyLineProps(c, props)
+
+	return &Control{
+		Name:       c.Name,
+		TypeName:   "LineControl",
+		Resources:  make(map[string]any),
+		Props:      props,
+		Children:   buildControlSlice(ctx, c.Children),
+		MustInit:   false,
+		SendToBack: true,
+	}
+}
+
+// applyLineProps applies the border properties shared by shapes and lines.
+func applyLineProps(c *vb6.Control, props map[string]string) {
+	if borderColor, ok := vb6.GetColor("BorderColor", c.Properties); ok {
+		props["BorderColor"] = toColor(borderColor)
+	}
+
+	if borderWidth, ok := vb6.GetInt("BorderWidth", c.Properties); ok {
+		props["BorderWidth"] = toInt(borderWidth)
+	}
+
+	if borderStyle, ok := vb6.GetInt("BorderStyle", c.Properties); ok {
+		props["BorderStyle"] = toInt(borderStyle)
+	}
+}
+
+func TimerBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	interval, ok := vb6.GetInt("Interval", c.Properties)
+	if ok {
 		props["Interval"] = toInt(interval)
 	}
 
+	// VB6 timers are enabled by default, but do not run without an interval
+	enabled, ok := vb6.GetBool("Enabled", c.Properties)
+	props["Enabled"] = toBool((!ok || enabled) && interval > 0)
+
 	return &Control{
 		Name:        c.Name,
 		TypeName:    "System.Windows.Forms.Timer",
 		Resources:   make(map[string]any),
 		Props:       props,
-		Children:    buildControlSlice(c.Children),
+		Children:    buildControlSlice(ctx, c.Children),
 		MustInit:    false,
 		SkipAdd:     true,
 		SkipName:    true,
@@ -340,46 +986,170 @@ func TimerBuilder(c *vb6.Control) *Control {
 	}
 }
 
-func buildControlSlice(controls []*vb6.Control) []*Control {
+func buildControlSlice(ctx *BuildContext, controls []*vb6.Control) []*Control {
 	result := make([]*Control, 0, len(controls))
+	graphics := make([]*Control, 0)
 	for _, c := range controls {
-		if control := buildControl(c); control != nil {
+		control := buildControl(ctx, c)
+		switch {
+		case control == nil:
+		case control.SendToBack:
+			graphics = append(graphics, control)
+		default:
 			result = append(result, control)
 		}
 	}
-	return result
+	// Controls added last end up at the bottom of the z-order
+	return append(result, graphics...)
 }
 
-func buildControl(c *vb6.Control) *Control {
-	var builder ControlBuilder
-
-	switch {
-	case c.TypeName == "VB.Form":
-		builder = FormBuilder
-	case c.TypeName == "VB.Menu":
-		builder = MenuItemBuilder
-	case c.TypeName == "VB.PictureBox":
-		builder = PictureBoxBuilder
-	case c.TypeName == "VB.Label":
-		builder = LabelBuilder
-	case c.TypeName == "VB.TextBox":
-		builder = TextBoxBuilder
-	case c.TypeName == "VB.Frame":
-		builder = FrameBuilder
-	case c.TypeName == "VB.CommandButton":
-		builder = CommandButtonBuilder
-	case c.TypeName == "VB.ComboBox":
-		builder = ComboBoxBuilder
-	case c.TypeName == "VB.Timer":
-		builder = TimerBuilder
-	default:
-		return nil
-	}
-
-	return builder(c)
-}
-
-func MenuItemBuilder(c *vb6.Control) *Control {
+// builders maps VB6 type names to the builders of their controls.
+var builders = make(map[string]ControlBuilder)
+
+// Register sets the builder used for controls of a VB6 type (e.g.
+// MSComctlLib.ListView), replacing the builder registered before.
+func Register(typeName string, b ControlBuilder) {
+	builders[typeName] = b
+}
+
+func init() {
+	Register("VB.Form", FormBuilder)
+	Register("VB.MDIForm", MDIFormBuilder)
+	Register("VB.UserControl", UserControlBuilder)
+	Register("VB.Menu", MenuItemBuilder)
+	Register("VB.PictureBox", PictureBoxBuilder)
+	Register("VB.Label", LabelBuilder)
+	Register("VB.TextBox", TextBoxBuilder)
+	Register("VB.Frame", FrameBuilder)
+	Register("VB.CommandButton", CommandButtonBuilder)
+	Register("VB.ComboBox", ComboBoxBuilder)
+	Register("VB.Timer", TimerBuilder)
+	Register("VB.ListBox", ListBoxBuilder)
+	Register("VB.CheckBox", CheckBoxBuilder)
+	Register("VB.OptionButton", OptionButtonBuilder)
+	Register("VB.HScrollBar", HScrollBarBuilder)
+	Register("VB.VScrollBar", VScrollBarBuilder)
+	Register("VB.Image", ImageBuilder)
+	Register("VB.DriveListBox", DriveListBoxBuilder)
+	Register("VB.DirListBox", FileSystemListBoxBuilder)
+	Register("VB.FileListBox", FileSystemListBoxBuilder)
+	Register("VB.Shape", ShapeBuilder)
+	Register("VB.Line", LineBuilder)
+	Register("VB.Data", PlaceholderBuilder)
+	Register("VB.OLE", PlaceholderBuilder)
+
+	Register("MSComDlg.CommonDialog", CommonDialogBuilder)
+	Register("MSFlexGridLib.MSFlexGrid", FlexGridBuilder)
+	Register("MSHierarchicalFlexGridLib.MSHFlexGrid", FlexGridBuilder)
+
+	// MSCOMCTL.OCX and the older COMCTL32.OCX have the same controls
+	for _, library := range []string{"MSComctlLib", "ComctlLib"} {
+		Register(library+".ListView", ListViewBuilder)
+		Register(library+".TreeView", TreeViewBuilder)
+		Register(library+".ImageList", ImageListBuilder)
+		Register(library+".Toolbar", ToolbarBuilder)
+		Register(library+".StatusBar", StatusBarBuilder)
+		Register(library+".ProgressBar", ProgressBarBuilder)
+		Register(library+".Slider", SliderBuilder)
+		Register(library+".TabStrip", TabStripBuilder)
+	}
+
+	Register("RichTextLib.RichTextBox", RichTextBoxBuilder)
+	Register("TabDlg.SSTab", SSTabBuilder)
+	Register("MSComCtl2.DTPicker", DTPickerBuilder)
+	Register("MSComCtl2.MonthView", MonthViewBuilder)
+	Register("MSComCtl2.UpDown", UpDownBuilder)
+	Register("MSComCtl2.Animation", AnimationBuilder)
+	Register("MSWinsockLib.Winsock", WinsockBuilder)
+}
+
+func buildControl(ctx *BuildContext, c *vb6.Control) *Control {
+	builder, ok := builders[c.TypeName]
+	if !ok {
+		if ctx.project.userControlType(c.TypeName) != nil {
+			builder = UserControlInstanceBuilder
+		} else {
+			builder = UnknownControlBuilder
+		}
+	}
+	return buildControlWith(ctx, c, builder)
+}
+
+// buildControlWith builds a control with the given builder instead of the
+// builder registered for its type.
+func buildControlWith(ctx *BuildContext, c *vb6.Control, builder ControlBuilder) *Control {
+	control := builder(ctx, c)
+	if control != nil {
+		control.Original = c
+		applyControlArray(c, control)
+		if !control.SkipAdd {
+			applyCommonProps(ctx, c, control)
+		}
+	}
+	return control
+}
+
+// controlName returns the name of the generated control, which is unique for
+// the elements of control arrays.
+func controlName(c *vb6.Control) string {
+	if index, ok := vb6.GetInt("Index", c.Properties); ok {
+		return fmt.Sprintf("%s_%d", c.Name, index)
+	}
+	return c.Name
+}
+
+// applyControlArray gives the elements of a control array a unique name.
+func applyControlArray(c *vb6.Control, control *Control) {
+	if index, ok := vb6.GetInt("Index", c.Properties); ok {
+		control.ArrayName = control.Name
+		control.ArrayIndex = index
+		control.Name = controlName(c)
+	}
+}
+
+// controlArray is a control array of a form. Elements are stored by index, with
+// nil for the unused indices.
+type controlArray struct {
+	Name     string
+	TypeName string
+	Elements []*Control
+}
+
+func getControlArrays(f *Control, arrays []*controlArray) []*controlArray {
+	for _, c := range f.Children {
+		if c.ArrayName != "" {
+			var array *controlArray
+			for _, a := range arrays {
+				if a.Name == c.ArrayName {
+					array = a
+				}
+			}
+			if array == nil {
+				array = &controlArray{Name: c.ArrayName, TypeName: c.TypeName}
+				arrays = append(arrays, array)
+			} else if array.TypeName != c.TypeName {
+				array.TypeName = commonBaseType(array.TypeName, c.TypeName)
+			}
+			for len(array.Elements) <= c.ArrayIndex {
+				array.Elements = append(array.Elements, nil)
+			}
+			array.Elements[c.ArrayIndex] = c
+		}
+		arrays = getControlArrays(c, arrays)
+	}
+	return arrays
+}
+
+// commonBaseType returns a base type of the elements of a control array whose
+// elements are of different types, such as the items and separators of a menu.
+func commonBaseType(a string, b string) string {
+	if strings.HasPrefix(a, "System.Windows.Forms.ToolStrip") && strings.HasPrefix(b, "System.Windows.Forms.ToolStrip") {
+		return "System.Windows.Forms.ToolStripItem"
+	}
+	return "System.Windows.Forms.Control"
+}
+
+func MenuItemBuilder(ctx *BuildContext, c *vb6.Control) *Control {
 	props := make(map[string]string)
 	propCalls := make(map[string]string)
 
@@ -387,7 +1157,19 @@ func MenuItemBuilder(c *vb6.Control) *Control {
 		props["Text"] = toStr(caption)
 	}
 
-	children := buildMenuItemSlice(c.Children)
+	applyMenuProps(c, props)
+
+	if shortcut, ok := vb6.GetProp("Shortcut", c.Properties); ok {
+		if _, shortcut, ok := parseShortcut(shortcut); ok {
+			props["Shortcut"] = shortcut
+		}
+	}
+
+	if windowList, ok := vb6.GetBool("WindowList", c.Properties); ok {
+		props["MdiList"] = toBool(windowList)
+	}
+
+	children := buildMenuItemSlice(ctx, c.Children)
 	if len(children) > 0 {
 		childNames := make([]string, 0, len(children))
 		for i, child := range children {
@@ -409,22 +1191,33 @@ func MenuItemBuilder(c *vb6.Control) *Control {
 	}
 }
 
-func buildMenuItemSlice(controls []*vb6.Control) []*Control {
+func buildMenuItemSlice(ctx *BuildContext, controls []*vb6.Control) []*Control {
 	result := make([]*Control, 0, len(controls))
 	for _, c := range controls {
 		if c.TypeName != "VB.Menu" {
 			fmt.Fprintf(os.Stderr, "unexpected control type in menu: %s\n", c.TypeName)
 			continue
 		}
-		control := MenuItemBuilder(c)
+		control := MenuItemBuilder(ctx, c)
 		if control != nil {
+			control.Original = c
+			applyControlArray(c, control)
 			result = append(result, control)
 		}
 	}
 	return result
 }
 
-func buildMenu(f *Control) {
+// buildMenu moves the menu items of a form into a MainMenu, or into a
+// MenuStrip when the project uses them or targets .NET 5 or later. Invisible menus become context menus.
+func buildMenu(p *ProjectInfo, f *Control) {
+	buildContextMenus(f)
+
+	if p.useMenuStrip() {
+		buildMenuStrip(f)
+		return
+	}
+
 	menuItems := make([]*Control, 0)
 	menuItemNames := make([]string, 0, len(f.Children))
 
@@ -461,3 +1254,92 @@ func buildMenu(f *Control) {
 	f.Props["Menu"] = "this.mainMenu1"
 	f.Children = append(f.Children, menu)
 }
+
+func getToolTipControls(f *Control, controls []*Control) []*Control {
+	if f.ToolTip != "" {
+		controls = append(controls, f)
+	}
+	for _, c := range f.Children {
+		controls = getToolTipControls(c, controls)
+	}
+	return controls
+}
+
+// buildToolTip adds a ToolTip component to the form showing the ToolTipText of
+// the controls.
+func buildToolTip(f *Control) {
+	controls := getToolTipControls(f, nil)
+	if len(controls) == 0 {
+		return
+	}
+
+	calls := make([]string, 0, len(controls))
+	for _, c := range controls {
+		name := fmt.Sprintf("this.%s", c.Name)
+		if c == f {
+			name = "this"
+		}
+		calls = append(calls, fmt.Sprintf("SetToolTip(%s, %s)", name, toStr(c.ToolTip)))
+	}
+
+	f.Children = append(f.Children, &Control{
+		Name:        "toolTip1",
+		TypeName:    "System.Windows.Forms.ToolTip",
+		Resources:   make(map[string]any),
+		Props:       make(map[string]string),
+		Calls:       calls,
+		Children:    make([]*Control, 0),
+		MustInit:    false,
+		SkipAdd:     true,
+		SkipName:    true,
+		IsComponent: true,
+	})
+}
+
+func getHelpControls(f *Control, controls []*Control) []*Control {
+	if f.HelpTopic != 0 {
+		controls = append(controls, f)
+	}
+	for _, c := range f.Children {
+		controls = getHelpControls(c, controls)
+	}
+	return controls
+}
+
+// buildHelpProvider adds a HelpProvider to the form that shows the topic of
+// the HelpContextID of the controls when F1 is pressed.
+func buildHelpProvider(p *ProjectInfo, f *Control) {
+	controls := getHelpControls(f, nil)
+	if len(controls) == 0 {
+		return
+	}
+
+	props := make(map[string]string)
+	if p.HelpFile != "" {
+		props["HelpNamespace"] = toStr(p.HelpFile)
+	}
+
+	calls := make([]string, 0, len(controls)*3)
+	for _, c := range controls {
+		name := fmt.Sprintf("this.%s", c.Name)
+		if c == f {
+			name = "this"
+		}
+		calls = append(calls,
+			fmt.Sprintf("SetHelpKeyword(%s, %s)", name, toStr(strconv.Itoa(c.HelpTopic))),
+			fmt.Sprintf("SetHelpNavigator(%s, System.Windows.Forms.HelpNavigator.TopicId)", name),
+			fmt.Sprintf("SetShowHelp(%s, true)", name))
+	}
+
+	f.Children = append(f.Children, &Control{
+		Name:      "helpProvider1",
+		TypeName:  "System.Windows.Forms.HelpProvider",
+		Resources: make(map[string]any),
+		Props:     props,
+		Calls:     calls,
+		Children:  make([]*Control, 0),
+		MustInit:  false,
+		SkipAdd:   true,
+		SkipName:  true,
+	})
+}
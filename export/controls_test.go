@@ -0,0 +1,576 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/guthius/vb6conv/vb6"
+	"github.com/guthius/vb6conv/vb6/ast"
+)
+
+// buildTestForm builds the controls of a form from the text of a .frm file.
+func buildTestForm(t *testing.T, frm string) (*Control, *BuildContext) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "test.frm")
+	if err := os.WriteFile(filename, []byte(frm), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := vb6.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := &BuildContext{project: &ProjectInfo{}}
+	form := buildControl(ctx, f.Root)
+	if form == nil {
+		t.Fatal("no form")
+	}
+	return form, ctx
+}
+
+// testControl returns the generated control called name.
+func testControl(t *testing.T, root *Control, name string) *Control {
+	t.Helper()
+	var find func(c *Control) *Control
+	find = func(c *Control) *Control {
+		if c.Name == name {
+			return c
+		}
+		for _, child := range c.Children {
+			if found := find(child); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	c := find(root)
+	if c == nil {
+		t.Fatalf("no control %s", name)
+	}
+	return c
+}
+
+// checkProps checks the properties of a control, an empty value checks that
+// the property is not set.
+func checkProps(t *testing.T, c *Control, want map[string]string) {
+	t.Helper()
+	for name, value := range want {
+		got, ok := c.Props[name]
+		switch {
+		case value == "" && ok:
+			t.Errorf("%s.%s = %s, want it unset", c.Name, name, got)
+		case value != "" && got != value:
+			t.Errorf("%s.%s = %q, want %q", c.Name, name, got, value)
+		}
+	}
+}
+
+func TestMDIFormBuilder(t *testing.T) {
+	form, ctx := buildTestForm(t, `VERSION 5.00
+Begin VB.MDIForm mdiMain
+   Caption         =   "Main"
+   ClientHeight    =   4000
+   ClientWidth     =   6000
+   Begin VB.PictureBox picBar
+      Align           =   1  'Align Top
+      Height          =   500
+      Index           =   0
+      TabIndex        =   0
+      Width           =   6000
+      Begin VB.CommandButton cmdNew
+         Caption         =   "New"
+         Height          =   375
+         MouseIcon       =   "missing.frx":0000
+         MousePointer    =   99  'Custom
+         TabIndex        =   1
+         Width           =   900
+      End
+   End
+   Begin VB.PictureBox picBar
+      Align           =   2  'Align Bottom
+      Height          =   300
+      Index           =   1
+      TabIndex        =   2
+      Width           =   6000
+   End
+End
+`)
+
+	checkProps(t, form, map[string]string{"IsMdiContainer": "true"})
+	if len(form.Children) != 2 {
+		t.Fatalf("got %d children, want 2", len(form.Children))
+	}
+	for i, c := range form.Children {
+		if c.TypeName != "System.Windows.Forms.Panel" || c.ArrayName != "picBar" || c.ArrayIndex != i {
+			t.Errorf("child %d is %s %s(%d), want a Panel in the picBar array", i, c.TypeName, c.ArrayName, c.ArrayIndex)
+		}
+	}
+	checkProps(t, testControl(t, form, "picBar_0"), map[string]string{"Dock": "System.Windows.Forms.DockStyle.Top", "Location": ""})
+	checkProps(t, testControl(t, form, "picBar_1"), map[string]string{"Dock": "System.Windows.Forms.DockStyle.Bottom"})
+
+	// The children are built once, so their resources are reported once
+	if len(ctx.failedResources) != 1 {
+		t.Errorf("got %d failed resources, want 1", len(ctx.failedResources))
+	}
+}
+
+func TestFormBuilderMdiChild(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Begin VB.Form frmChild
+   Caption         =   "Child"
+   ClientHeight    =   2000
+   ClientWidth     =   3000
+   MDIChild        =   -1  'True
+End
+`)
+	if !form.MdiChild {
+		t.Error("form is not an MDI child")
+	}
+}
+
+func TestControlArrays(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin VB.Frame fraName
+      Caption         =   "Name"
+      Height          =   2000
+      TabIndex        =   0
+      Width           =   3000
+      Begin VB.TextBox txtName
+         Height          =   285
+         Index           =   2
+         TabIndex        =   2
+         Top             =   600
+         Width           =   2000
+      End
+      Begin VB.TextBox txtName
+         Height          =   285
+         Index           =   0
+         TabIndex        =   1
+         Top             =   200
+         Width           =   2000
+      End
+   End
+End
+`)
+
+	arrays := getControlArrays(form, nil)
+	if len(arrays) != 1 {
+		t.Fatalf("got %d control arrays, want 1", len(arrays))
+	}
+	array := arrays[0]
+	if array.Name != "txtName" || array.TypeName != "System.Windows.Forms.TextBox" {
+		t.Errorf("got array %s of %s, want txtName of TextBox", array.Name, array.TypeName)
+	}
+	if len(array.Elements) != 3 || array.Elements[1] != nil {
+		t.Fatalf("got %d elements, want 3 with index 1 unused", len(array.Elements))
+	}
+	for _, i := range []int{0, 2} {
+		if c := array.Elements[i]; c.Name != fmt.Sprintf("txtName_%d", i) || c.ArrayIndex != i {
+			t.Errorf("element %d is %s(%d)", i, c.Name, c.ArrayIndex)
+		}
+	}
+
+	// The handler is shared by the elements, which pass their index
+	code, err := ast.Parse("Private Sub txtName_Change(Index As Integer)\nEnd Sub\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bindEvents(&ProjectInfo{}, form, code)
+	for _, i := range []int{0, 2} {
+		events := array.Elements[i].Events
+		if len(events) != 1 || events[0].Event != "TextChanged" || events[0].Method != "txtName_Change" {
+			t.Errorf("element %d handles %v, want TextChanged with txtName_Change", i, events)
+		}
+	}
+}
+
+func TestCommonBaseType(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"System.Windows.Forms.ToolStripMenuItem", "System.Windows.Forms.ToolStripSeparator", "System.Windows.Forms.ToolStripItem"},
+		{"System.Windows.Forms.Label", "System.Windows.Forms.TextBox", "System.Windows.Forms.Control"},
+	}
+	for _, test := range tests {
+		if got := commonBaseType(test.a, test.b); got != test.want {
+			t.Errorf("commonBaseType(%s, %s) = %s, want %s", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestIntrinsicControls(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin VB.ListBox lstItems
+      Height          =   1000
+      MultiSelect     =   2  'Extended
+      Sorted          =   -1  'True
+      Style           =   1  'Checkbox
+      TabIndex        =   0
+      Width           =   2000
+   End
+   Begin VB.CheckBox chkDone
+      Caption         =   "Done"
+      Value           =   2  'Grayed
+      Alignment       =   1  'Right Justify
+      Height          =   255
+      TabIndex        =   1
+      Width           =   1000
+   End
+   Begin VB.OptionButton optA
+      Caption         =   "A"
+      Value           =   -1  'True
+      Style           =   1  'Graphical
+      Height          =   255
+      TabIndex        =   2
+      Width           =   1000
+   End
+   Begin VB.HScrollBar hsbZoom
+      Height          =   255
+      LargeChange     =   10
+      Max             =   200
+      Min             =   10
+      SmallChange     =   5
+      TabIndex        =   3
+      Value           =   100
+      Width           =   2000
+   End
+   Begin VB.VScrollBar vsbPos
+      Height          =   2000
+      Max             =   50
+      TabIndex        =   4
+      Width           =   255
+   End
+   Begin VB.Image imgLogo
+      Height          =   500
+      Stretch         =   -1  'True
+      Width           =   500
+   End
+   Begin VB.DriveListBox drvList
+      Height          =   315
+      TabIndex        =   5
+      Width           =   2000
+   End
+   Begin VB.DirListBox dirList
+      Height          =   1000
+      TabIndex        =   6
+      Width           =   2000
+   End
+   Begin VB.FileListBox filList
+      Height          =   1000
+      Pattern         =   "*.txt"
+      TabIndex        =   7
+      Width           =   2000
+   End
+   Begin VB.Data datMain
+      Caption         =   "Data1"
+      Height          =   345
+      Width           =   2000
+   End
+End
+`)
+
+	tests := []struct {
+		name     string
+		typeName string
+		props    map[string]string
+	}{
+		{"lstItems", "System.Windows.Forms.CheckedListBox", map[string]string{
+			"CheckOnClick":   "true",
+			"Sorted":         "true",
+			"IntegralHeight": "false",
+		}},
+		{"chkDone", "System.Windows.Forms.CheckBox", map[string]string{
+			"CheckState": "System.Windows.Forms.CheckState.Indeterminate",
+			"CheckAlign": "System.Drawing.ContentAlignment.MiddleRight",
+		}},
+		{"optA", "System.Windows.Forms.RadioButton", map[string]string{
+			"Appearance": "System.Windows.Forms.Appearance.Button",
+			"Checked":    "true",
+		}},
+		{"hsbZoom", "System.Windows.Forms.HScrollBar", nil},
+		{"vsbPos", "System.Windows.Forms.VScrollBar", nil},
+		{"imgLogo", "System.Windows.Forms.PictureBox", map[string]string{
+			"SizeMode":  "System.Windows.Forms.PictureBoxSizeMode.StretchImage",
+			"BackColor": "System.Drawing.Color.Transparent",
+			"TabStop":   "false",
+		}},
+		{"drvList", "System.Windows.Forms.ComboBox", map[string]string{
+			"DropDownStyle": "System.Windows.Forms.ComboBoxStyle.DropDownList",
+		}},
+		{"dirList", "System.Windows.Forms.ListBox", nil},
+		{"filList", "System.Windows.Forms.ListBox", nil},
+		{"datMain", "System.Windows.Forms.Panel", nil},
+	}
+	for _, test := range tests {
+		c := testControl(t, form, test.name)
+		if c.TypeName != test.typeName {
+			t.Errorf("%s is a %s, want %s", test.name, c.TypeName, test.typeName)
+		}
+		checkProps(t, c, test.props)
+	}
+
+	// The range is set before the value, which must lie within it. The
+	// maximum of WinForms scroll bars includes the large change.
+	calls := testControl(t, form, "hsbZoom").Calls
+	want := []string{"Minimum = 10", "Maximum = 209", "LargeChange = 10", "SmallChange = 5", "Value = 100"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+}
+
+func TestShapeAndLine(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin VB.Shape shpBox
+      BorderColor     =   &H000000FF&
+      BorderWidth     =   2
+      FillColor       =   &H0000FF00&
+      FillStyle       =   0  'Solid
+      Height          =   600
+      Left            =   120
+      Shape           =   3  'Circle
+      Top             =   120
+      Width           =   600
+   End
+   Begin VB.Line linSep
+      BorderStyle     =   3  'Dot
+      X1              =   120
+      X2              =   3000
+      Y1              =   900
+      Y2              =   900
+   End
+   Begin VB.Label lblName
+      Caption         =   "Name"
+      Height          =   255
+      Left            =   120
+      TabIndex        =   0
+      Top             =   1200
+      Width           =   1000
+   End
+End
+`)
+
+	// Shapes and lines are drawn behind the other controls
+	names := make([]string, 0, len(form.Children))
+	for _, c := range form.Children {
+		names = append(names, c.Name)
+	}
+	if want := []string{"lblName", "shpBox", "linSep"}; !slices.Equal(names, want) {
+		t.Errorf("got children %v, want %v", names, want)
+	}
+
+	shape := testControl(t, form, "shpBox")
+	if shape.TypeName != "ShapeControl" {
+		t.Errorf("shpBox is a %s, want ShapeControl", shape.TypeName)
+	}
+	checkProps(t, shape, map[string]string{
+		"Shape":       "3",
+		"BorderColor": "System.Drawing.Color.FromArgb(255, 0, 0)",
+		"BorderWidth": "2",
+		"FillStyle":   "0",
+		"FillColor":   "System.Drawing.Color.FromArgb(0, 255, 0)",
+		"Location":    "new System.Drawing.Point(8, 8)",
+		"TabIndex":    "",
+	})
+
+	line := testControl(t, form, "linSep")
+	if line.TypeName != "LineControl" {
+		t.Errorf("linSep is a %s, want LineControl", line.TypeName)
+	}
+	checkProps(t, line, map[string]string{
+		"X1":          "8",
+		"Y1":          "60",
+		"X2":          "200",
+		"Y2":          "60",
+		"BorderStyle": "3",
+		"BackColor":   "",
+	})
+
+	used := getSupportClasses(form, make(map[string]bool))
+	if !used["ShapeControl"] || !used["LineControl"] || len(used) != 2 {
+		t.Errorf("got support classes %v, want ShapeControl and LineControl", used)
+	}
+}
+
+func TestUnknownControl(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Object = "{12345678-1234-1234-1234-123456789012}#1.0#0"; "ACME.OCX"
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin AcmeLib.Gauge ggeSpeed
+      Height          =   1500
+      Left            =   120
+      TabIndex        =   0
+      Top             =   120
+      Width           =   1500
+      _ExtentX        =   2646
+      _ExtentY        =   2646
+      Needle          =   3
+      Begin VB.Label lblSpeed
+         Caption         =   "km/h"
+         Height          =   255
+         Left            =   120
+         TabIndex        =   1
+         Top             =   120
+         Width           =   600
+      End
+   End
+   Begin VB.OLE oleDoc
+      Height          =   1000
+      Left            =   1800
+      TabIndex        =   2
+      Top             =   120
+      Width           =   1500
+   End
+End
+`)
+
+	gauge := testControl(t, form, "ggeSpeed")
+	if gauge.TypeName != "System.Windows.Forms.Panel" || !gauge.Unsupported {
+		t.Errorf("ggeSpeed is a %s, want an unsupported placeholder", gauge.TypeName)
+	}
+	checkProps(t, gauge, map[string]string{
+		"Location":    "new System.Drawing.Point(8, 8)",
+		"Size":        "new System.Drawing.Size(100, 100)",
+		"BorderStyle": "System.Windows.Forms.BorderStyle.FixedSingle",
+		"TabIndex":    "",
+	})
+
+	// The children are kept, the label showing the type is behind them
+	names := make([]string, 0, len(gauge.Children))
+	for _, c := range gauge.Children {
+		names = append(names, c.Name)
+	}
+	if want := []string{"lblSpeed", "ggeSpeed_Label"}; !slices.Equal(names, want) {
+		t.Errorf("got children %v, want %v", names, want)
+	}
+	checkProps(t, testControl(t, form, "ggeSpeed_Label"), map[string]string{
+		"Text": `"AcmeLib.Gauge ggeSpeed"`,
+		"Dock": "System.Windows.Forms.DockStyle.Fill",
+	})
+
+	// Controls without a .NET equivalent are placeholders, but supported
+	ole := testControl(t, form, "oleDoc")
+	if ole.TypeName != "System.Windows.Forms.Panel" || ole.Unsupported {
+		t.Errorf("oleDoc is a %s, want a supported placeholder", ole.TypeName)
+	}
+
+	sb := strings.Builder{}
+	writeControlSource(gauge.Original, NewExportWriter(&sb))
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	want := []string{
+		"// TODO: AcmeLib.Gauge could not be converted, the original definition was:",
+		"//   Begin AcmeLib.Gauge ggeSpeed",
+		"//      Height          =   1500",
+	}
+	if len(lines) < len(want) || !slices.Equal(lines[:len(want)], want) {
+		t.Errorf("got source comment:\n%s", sb.String())
+	}
+	if last := lines[len(lines)-1]; last != "//   End" {
+		t.Errorf("source comment ends with %q, want the End of the control", last)
+	}
+}
+
+func TestCommonProps(t *testing.T) {
+	form, ctx := buildTestForm(t, `VERSION 5.00
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   HelpContextID   =   10
+   Begin VB.CommandButton cmdOk
+      Caption         =   "OK"
+      CausesValidation=   0   'False
+      DragMode        =   1  'Automatic
+      Enabled         =   0   'False
+      Height          =   375
+      HelpContextID   =   20
+      MousePointer    =   11  'Hourglass
+      OLEDropMode     =   1  'Manual
+      RightToLeft     =   -1  'True
+      TabIndex        =   0
+      TabStop         =   0   'False
+      Tag             =   "default"
+      ToolTipText     =   "Close the form"
+      Width           =   1000
+   End
+   Begin VB.TextBox txtName
+      Height          =   285
+      MouseIcon       =   "frmTest.frx":0000
+      MousePointer    =   99  'Custom
+      TabIndex        =   1
+      Width           =   1000
+   End
+   Begin VB.Image imgLogo
+      Height          =   500
+      TabStop         =   -1  'True
+      Width           =   500
+   End
+End
+`)
+
+	cmd := testControl(t, form, "cmdOk")
+	checkProps(t, cmd, map[string]string{
+		"Enabled":          "false",
+		"TabStop":          "false",
+		"Tag":              `"default"`,
+		"RightToLeft":      "System.Windows.Forms.RightToLeft.Yes",
+		"CausesValidation": "false",
+		"Cursor":           "System.Windows.Forms.Cursors.WaitCursor",
+		"AllowDrop":        "true",
+	})
+	if cmd.ToolTip != "Close the form" || cmd.HelpTopic != 20 {
+		t.Errorf("got tool tip %q and help topic %d", cmd.ToolTip, cmd.HelpTopic)
+	}
+	if len(cmd.Calls) != 1 || !strings.Contains(cmd.Calls[0], "DoDragDrop") {
+		t.Errorf("got calls %v, want the automatic drag", cmd.Calls)
+	}
+
+	// Custom cursors are loaded from the MouseIcon
+	checkProps(t, testControl(t, form, "txtName"), map[string]string{"Cursor": ""})
+	if len(ctx.failedResources) != 1 || ctx.failedResources[0].Property != "MouseIcon" {
+		t.Errorf("got failed resources %v, want the missing MouseIcon", ctx.failedResources)
+	}
+
+	// The properties mapped by the builder are left alone
+	checkProps(t, testControl(t, form, "imgLogo"), map[string]string{"TabStop": "false"})
+
+	buildToolTip(form)
+	buildHelpProvider(&ProjectInfo{HelpFile: "app.chm"}, form)
+
+	toolTip := testControl(t, form, "toolTip1")
+	if want := []string{`SetToolTip(this.cmdOk, "Close the form")`}; !slices.Equal(toolTip.Calls, want) {
+		t.Errorf("got tool tip calls %v, want %v", toolTip.Calls, want)
+	}
+
+	help := testControl(t, form, "helpProvider1")
+	checkProps(t, help, map[string]string{"HelpNamespace": `"app.chm"`})
+	want := []string{
+		`SetHelpKeyword(this, "10")`,
+		"SetHelpNavigator(this, System.Windows.Forms.HelpNavigator.TopicId)",
+		"SetShowHelp(this, true)",
+		`SetHelpKeyword(this.cmdOk, "20")`,
+		"SetHelpNavigator(this.cmdOk, System.Windows.Forms.HelpNavigator.TopicId)",
+		"SetShowHelp(this.cmdOk, true)",
+	}
+	if !slices.Equal(help.Calls, want) {
+		t.Errorf("got help calls %v, want %v", help.Calls, want)
+	}
+}
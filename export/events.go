@@ -0,0 +1,413 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6/ast"
+)
+
+// EventHandler wires an event of a control to a method of the form.
+type EventHandler struct {
+	Event    string
+	Delegate string
+	Method   string
+}
+
+// event describes how a VB6 event maps to a .NET event. The parameters of the
+// VB6 handler become locals initialized from In and copied back to the event
+// arguments with Out.
+type event struct {
+	Name     string   // .NET event, empty for events that are not wired
+	Delegate string   // handler delegate type
+	Args     string   // event arguments type
+	In       []string // initial values of the VB6 parameters, by position
+	Out      []string // statements copying the parameters back, %[1]s is the parameter
+	Custom   bool     // event declared in VB6 code, the handler keeps its parameters
+	Guard    string   // condition for running the handler, when the .NET event is raised more often
+}
+
+var (
+	clickEvent = event{Name: "Click", Delegate: "System.EventHandler", Args: "EventArgs"}
+	mouseEvent = event{
+		Delegate: "System.Windows.Forms.MouseEventHandler",
+		Args:     "MouseEventArgs",
+		In:       []string{"(int)e.Button >> 20", "(int)Control.ModifierKeys >> 16", "e.X", "e.Y"},
+	}
+	keyEvent = event{
+		Delegate: "System.Windows.Forms.KeyEventHandler",
+		Args:     "KeyEventArgs",
+		In:       []string{"(int)e.KeyCode", "(int)e.Modifiers >> 16"},
+	}
+)
+
+func (e event) named(name string) event {
+	e.Name = name
+	return e
+}
+
+// commonEvents maps the VB6 events shared by most controls.
+var commonEvents = map[string]event{
+	"click":     clickEvent,
+	"dblclick":  clickEvent.named("DoubleClick"),
+	"change":    clickEvent.named("TextChanged"),
+	"gotfocus":  clickEvent.named("Enter"),
+	"lostfocus": clickEvent.named("Leave"),
+	"resize":    clickEvent.named("Resize"),
+	"mousedown": mouseEvent.named("MouseDown"),
+	"mouseup":   mouseEvent.named("MouseUp"),
+	"mousemove": mouseEvent.named("MouseMove"),
+	"keydown": {
+		Name:     "KeyDown",
+		Delegate: keyEvent.Delegate,
+		Args:     keyEvent.Args,
+		In:       keyEvent.In,
+		Out:      []string{"if (%[1]s == 0) e.SuppressKeyPress = true;"},
+	},
+	"keyup": keyEvent.named("KeyUp"),
+	"keypress": {
+		Name:     "KeyPress",
+		Delegate: "System.Windows.Forms.KeyPressEventHandler",
+		Args:     "KeyPressEventArgs",
+		In:       []string{"e.KeyChar"},
+		Out:      []string{"if (%[1]s == 0) e.Handled = true; else e.KeyChar = (char)%[1]s;"},
+	},
+	"validate": {
+		Name:     "Validating",
+		Delegate: "System.ComponentModel.CancelEventHandler",
+		Args:     "CancelEventArgs",
+		In:       []string{"false"},
+		Out:      []string{"e.Cancel = %[1]s;"},
+	},
+	"paint": {
+		Name:     "Paint",
+		Delegate: "System.Windows.Forms.PaintEventHandler",
+		Args:     "PaintEventArgs",
+	},
+}
+
+// formEvents maps the VB6 events of forms.
+var formEvents = map[string]event{
+	"load":           clickEvent.named("Load"),
+	"activate":       clickEvent.named("Activated"),
+	"deactivate":     clickEvent.named("Deactivate"),
+	"terminate":      clickEvent.named("Disposed"),
+	"initialize":     {Args: "EventArgs"},
+	"initproperties": {Args: "EventArgs"},
+	"unload": {
+		Name:     "FormClosing",
+		Delegate: "System.Windows.Forms.FormClosingEventHandler",
+		Args:     "FormClosingEventArgs",
+		In:       []string{"0"},
+		Out:      []string{"e.Cancel = %[1]s != 0;"},
+	},
+	"queryunload": {
+		Name:     "FormClosing",
+		Delegate: "System.Windows.Forms.FormClosingEventHandler",
+		Args:     "FormClosingEventArgs",
+		In:       []string{"0", "e.CloseReason == CloseReason.UserClosing ? 0 : 1"},
+		Out:      []string{"e.Cancel = %[1]s != 0;"},
+	},
+}
+
+// typeEvents overrides commonEvents for specific .NET control types.
+var typeEvents = map[string]map[string]event{
+	"ComboBox": {
+		"click": clickEvent.named("SelectedIndexChanged"),
+	},
+	"ListBox": {
+		"click": clickEvent.named("SelectedIndexChanged"),
+	},
+	"CheckedListBox": {
+		"click": clickEvent.named("SelectedIndexChanged"),
+	},
+	"CheckBox": {
+		"click": clickEvent.named("CheckedChanged"),
+	},
+	"RadioButton": {
+		// CheckedChanged is raised for the option that is cleared too
+		"click": {
+			Name:     "CheckedChanged",
+			Delegate: clickEvent.Delegate,
+			Args:     clickEvent.Args,
+			Guard:    "((RadioButton)sender).Checked",
+		},
+	},
+	"Timer": {
+		"timer": clickEvent.named("Tick"),
+	},
+	"HScrollBar": {
+		"change": clickEvent.named("ValueChanged"),
+		"scroll": {Name: "Scroll", Delegate: "System.Windows.Forms.ScrollEventHandler", Args: "ScrollEventArgs"},
+	},
+	"VScrollBar": {
+		"change": clickEvent.named("ValueChanged"),
+		"scroll": {Name: "Scroll", Delegate: "System.Windows.Forms.ScrollEventHandler", Args: "ScrollEventArgs"},
+	},
+	"ListView": {
+		"itemclick": {
+			Name:     "ItemSelectionChanged",
+			Delegate: "System.Windows.Forms.ListViewItemSelectionChangedEventHandler",
+			Args:     "ListViewItemSelectionChangedEventArgs",
+			In:       []string{"e.Item"},
+		},
+		"itemcheck": {
+			Name:     "ItemChecked",
+			Delegate: "System.Windows.Forms.ItemCheckedEventHandler",
+			Args:     "ItemCheckedEventArgs",
+			In:       []string{"e.Item"},
+		},
+		"columnclick": {
+			Name:     "ColumnClick",
+			Delegate: "System.Windows.Forms.ColumnClickEventHandler",
+			Args:     "ColumnClickEventArgs",
+			In:       []string{"((ListView)sender).Columns[e.Column]"},
+		},
+	},
+	"TreeView": {
+		"nodeclick": {
+			Name:     "NodeMouseClick",
+			Delegate: "System.Windows.Forms.TreeNodeMouseClickEventHandler",
+			Args:     "TreeNodeMouseClickEventArgs",
+			In:       []string{"e.Node"},
+		},
+		"nodecheck": {
+			Name:     "AfterCheck",
+			Delegate: "System.Windows.Forms.TreeViewEventHandler",
+			Args:     "TreeViewEventArgs",
+			In:       []string{"e.Node"},
+		},
+		"expand": {
+			Name:     "AfterExpand",
+			Delegate: "System.Windows.Forms.TreeViewEventHandler",
+			Args:     "TreeViewEventArgs",
+			In:       []string{"e.Node"},
+		},
+		"collapse": {
+			Name:     "AfterCollapse",
+			Delegate: "System.Windows.Forms.TreeViewEventHandler",
+			Args:     "TreeViewEventArgs",
+			In:       []string{"e.Node"},
+		},
+	},
+	"ToolStrip": {
+		"buttonclick": {
+			Name:     "ItemClicked",
+			Delegate: "System.Windows.Forms.ToolStripItemClickedEventHandler",
+			Args:     "ToolStripItemClickedEventArgs",
+			In:       []string{"e.ClickedItem"},
+		},
+	},
+	"StatusStrip": {
+		"panelclick": {
+			Name:     "ItemClicked",
+			Delegate: "System.Windows.Forms.ToolStripItemClickedEventHandler",
+			Args:     "ToolStripItemClickedEventArgs",
+			In:       []string{"e.ClickedItem"},
+		},
+	},
+	"TrackBar": {
+		"change": clickEvent.named("ValueChanged"),
+		"scroll": clickEvent.named("Scroll"),
+	},
+	"TabControl": {
+		"click": clickEvent.named("SelectedIndexChanged"),
+	},
+	"RichTextBox": {
+		"selchange": clickEvent.named("SelectionChanged"),
+	},
+	"DateTimePicker": {
+		"change": clickEvent.named("ValueChanged"),
+	},
+	"MonthCalendar": {
+		"selchange": {
+			Name:     "DateChanged",
+			Delegate: "System.Windows.Forms.DateRangeEventHandler",
+			Args:     "DateRangeEventArgs",
+			In:       []string{"e.Start", "e.End"},
+		},
+		"dateclick": {
+			Name:     "DateSelected",
+			Delegate: "System.Windows.Forms.DateRangeEventHandler",
+			Args:     "DateRangeEventArgs",
+			In:       []string{"e.Start"},
+		},
+	},
+	"NumericUpDown": {
+		"change": clickEvent.named("ValueChanged"),
+	},
+	"WinsockControl": {
+		"connect":      clickEvent.named("Connected"),
+		"close":        clickEvent.named("Closed"),
+		"sendcomplete": clickEvent.named("SendComplete"),
+		"dataarrival": {
+			Name:     "DataArrival",
+			Delegate: "System.EventHandler<WinsockDataArrivalEventArgs>",
+			Args:     "WinsockDataArrivalEventArgs",
+			In:       []string{"e.BytesTotal"},
+		},
+		"connectionrequest": {
+			Name:     "ConnectionRequest",
+			Delegate: "System.EventHandler<WinsockConnectionRequestEventArgs>",
+			Args:     "WinsockConnectionRequestEventArgs",
+			In:       []string{"e.RequestID"},
+		},
+		"error": {
+			Name:     "Error",
+			Delegate: "System.EventHandler<WinsockErrorEventArgs>",
+			Args:     "WinsockErrorEventArgs",
+			In:       []string{"e.Number", "e.Description", "0", `"Winsock"`, `""`, "0", "false"},
+		},
+	},
+}
+
+func lookupEvent(c *Control, root bool, name string) (event, bool) {
+	name = strings.ToLower(name)
+	if root {
+		if e, ok := formEvents[name]; ok {
+			return e, true
+		}
+	}
+	if events, ok := typeEvents[shortType(c.TypeName)]; ok {
+		if e, ok := events[name]; ok {
+			return e, true
+		}
+	}
+	e, ok := commonEvents[name]
+	return e, ok
+}
+
+// handler is a procedure that handles an event of a control.
+type handler struct {
+	event   event
+	control *Control
+}
+
+// findControls finds the child controls with the given name, or the elements
+// of the control array with that name.
+func findControls(c *Control, name string, found []*Control) []*Control {
+	for _, child := range c.Children {
+		if child.ArrayName != "" && strings.EqualFold(child.ArrayName, name) ||
+			child.ArrayName == "" && strings.EqualFold(child.Name, name) {
+			found = append(found, child)
+		}
+		found = findControls(child, name, found)
+	}
+	return found
+}
+
+// bindEvents finds the event handlers in the code of a form, named
+// <control>_<event>, and adds the event wiring to the controls.
+func bindEvents(p *ProjectInfo, root *Control, file *ast.File) map[*ast.ProcDecl]*handler {
+	handlers := make(map[*ast.ProcDecl]*handler)
+	for _, proc := range file.Procedures() {
+		if proc.Kind != ast.SubProc {
+			continue
+		}
+		sep := strings.LastIndex(proc.Name, "_")
+		if sep == -1 {
+			continue
+		}
+		name, eventName := proc.Name[:sep], proc.Name[sep+1:]
+
+		isRoot := strings.EqualFold(name, "Form") || strings.EqualFold(name, "MDIForm") || strings.EqualFold(name, "UserControl")
+		controls := []*Control{root}
+		if !isRoot {
+			controls = findControls(root, name, nil)
+			if len(controls) == 0 {
+				continue
+			}
+		}
+
+		e, ok := lookupEvent(controls[0], isRoot, eventName)
+		if !ok {
+			if e, ok = p.userControlEvent(controls[0], eventName); !ok {
+				continue
+			}
+		}
+
+		handlers[proc] = &handler{
+			event:   e,
+			control: controls[0],
+		}
+		// Handlers of control arrays of user controls are left as a TODO
+		if e.Name == "" || e.Custom && controls[0].ArrayName != "" {
+			continue
+		}
+		for _, control := range controls {
+			control.Events = append(control.Events, EventHandler{
+				Event:    e.Name,
+				Delegate: e.Delegate,
+				Method:   csName(proc.Name),
+			})
+		}
+	}
+	return handlers
+}
+
+// writeWithEvents writes a WithEvents variable. The variable becomes a
+// property that subscribes the handlers of the events of its class, named
+// <variable>_<event>, when an object is assigned to it.
+func (t *translator) writeWithEvents(d *ast.VarDecl, spec *ast.VarSpec) {
+	typ := t.specType(spec)
+	name := csName(spec.Name)
+	field := "_" + name
+	mod := t.modifiers(d.Keyword, "private")
+
+	events, ok := t.classes[key(typ)]
+	if !ok {
+		reason := fmt.Sprintf("the events of %s are not handled, WithEvents is only supported for the classes of the project", name)
+		t.w.Writef("// TODO: %s", reason)
+		t.w.Writef("%s %s %s;", mod, typ, name)
+		t.todos = append(t.todos, CodeIssue{Line: spec.Pos().Line, Reason: reason})
+		return
+	}
+
+	// Lines subscribing the handlers, written with -= to unsubscribe them
+	handlers := make([]string, 0)
+	for _, proc := range t.file.Procedures() {
+		sep := strings.LastIndex(proc.Name, "_")
+		if proc.Kind != ast.SubProc || sep == -1 || !strings.EqualFold(proc.Name[:sep], spec.Name) {
+			continue
+		}
+		if e, ok := events[key(proc.Name[sep+1:])]; ok {
+			handlers = append(handlers, fmt.Sprintf("%s.%s %%s %s;", field, csName(e.Name), csName(proc.Name)))
+		}
+	}
+	if len(handlers) == 0 {
+		t.w.Writef("%s %s %s;", mod, typ, name)
+		return
+	}
+
+	t.w.Writef("private %s %s;", typ, field)
+	t.w.Writeln()
+	t.w.Writef("%s %s %s", mod, typ, name)
+	t.w.Write("{")
+	t.w.WriteIndent(func() {
+		t.w.Writef("get { return %s; }", field)
+		t.w.Write("set")
+		t.w.Write("{")
+		t.w.WriteIndent(func() {
+			t.w.Writef("if (%s != null)", field)
+			t.w.Write("{")
+			t.w.WriteIndent(func() {
+				for _, h := range handlers {
+					t.w.Writef(h, "-=")
+				}
+			})
+			t.w.Write("}")
+			t.w.Writef("%s = value;", field)
+			t.w.Writef("if (%s != null)", field)
+			t.w.Write("{")
+			t.w.WriteIndent(func() {
+				for _, h := range handlers {
+					t.w.Writef(h, "+=")
+				}
+			})
+			t.w.Write("}")
+		})
+		t.w.Write("}")
+	})
+	t.w.Write("}")
+	t.block = true
+}
@@ -1,31 +1,169 @@
 package export
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/guthius/vb6conv/resx"
 	"github.com/guthius/vb6conv/vb6"
+	"github.com/guthius/vb6conv/vb6/ast"
 )
 
+// ErrUnsupportedFramework is returned by CheckTargetFramework for target
+// frameworks that can not run Windows Forms.
+var ErrUnsupportedFramework = errors.New("unsupported target framework")
+
+// ErrUnsupportedPlatform is returned by CheckPlatform for unknown platform
+// targets.
+var ErrUnsupportedPlatform = errors.New("unsupported platform target")
+
+// DefaultTargetFramework is the target framework of projects that do not
+// specify one.
+const DefaultTargetFramework = "net48"
+
+// DefaultPlatform is the platform target of projects that do not specify one.
+// VB6 applications, and the ActiveX controls and DLLs they use, are 32 bit.
+const DefaultPlatform = "x86"
+
 type ProjectInfo struct {
-	Name      string
-	Namespace string
-	Output    string
+	Name            string
+	Namespace       string
+	Output          string
+	TargetFramework string               // Target framework moniker, such as net48 or net8.0-windows
+	Platform        string               // Platform target, such as x86 or AnyCPU
+	Startup         string               // Startup object: a form, Sub Main or (None)
+	HelpFile        string               // Help file of the application, used by the HelpProviders
+	MenuStrip       bool                 // Convert menus to a MenuStrip instead of a MainMenu
+	Anchor          bool                 // Anchor controls to the edges of their container they are close to
+	symbols         map[string]*symbol   // public members of the modules
+	types           map[string]*userType // public types and enums of the modules
+	userControls    map[string]*userControl
+	classes         map[string]map[string]*ast.EventDecl // public events of the classes, by key
+	forms           []string                             // names of the exported forms
+	mdiForm         string                               // name of the MDI form, the parent of the MDI child forms
+	reports         []*FileReport
+}
+
+// CheckTargetFramework checks that a target framework moniker is a .NET
+// Framework 4.6.2 or later, or a .NET 5 or later Windows target.
+func CheckTargetFramework(tfm string) error {
+	if !strings.Contains(tfm, ".") {
+		switch tfm {
+		case "net462", "net47", "net471", "net472", "net48", "net481":
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrUnsupportedFramework, tfm)
+	}
+
+	var major, minor int
+	var platform string
+	if _, err := fmt.Sscanf(tfm, "net%d.%d-%s", &major, &minor, &platform); err != nil || major < 5 || !strings.HasPrefix(platform, "windows") {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFramework, tfm)
+	}
+	return nil
+}
+
+// CheckPlatform checks that a platform target is one MSBuild knows.
+func CheckPlatform(platform string) error {
+	switch platform {
+	case "AnyCPU", "x86", "x64", "ARM64":
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
+}
+
+// isNetFramework reports whether the project targets the .NET Framework
+// instead of .NET 5 or later.
+func (p *ProjectInfo) isNetFramework() bool {
+	return p.TargetFramework == "" || !strings.Contains(p.TargetFramework, ".")
+}
+
+// RegisterForm makes a form of the project available to the code of all
+// files, by its default instance as in VB6. Forms must be registered before
+// any file is exported, after the user controls.
+func (p *ProjectInfo) RegisterForm(f *vb6.Form) {
+	if p.symbols == nil {
+		p.symbols = make(map[string]*symbol)
+	}
+	// The controls are built to translate their properties in other files, the
+	// resources are reported when the form itself is exported
+	form := buildControl(&BuildContext{project: p}, f.Root)
+	if form == nil || form.Unsupported {
+		return
+	}
+	buildMenu(p, form)
+	if f.Root.TypeName == "VB.MDIForm" {
+		p.mdiForm = form.Name
+	}
+	p.symbols[key(form.Name)] = &symbol{Kind: controlSymbol, Code: form.Name + ".Default", Type: form.Name, Control: form}
+}
+
+// useMenuStrip reports whether menus are converted to a MenuStrip. .NET 5 and
+// later no longer have a MainMenu.
+func (p *ProjectInfo) useMenuStrip() bool {
+	return p.MenuStrip || !p.isNetFramework()
 }
 
 func Export(p *ProjectInfo, f *vb6.Form) {
-	control := buildControl(f.Root)
-	buildMenu(control)
+	ctx := &BuildContext{project: p}
+	control := buildControl(ctx, f.Root)
+	if control == nil || control.Unsupported {
+		fmt.Fprintf(os.Stderr, "%s: unsupported root control %s\n", f.Filename, f.Root.TypeName)
+		return
+	}
+	for _, r := range ctx.failedResources {
+		fmt.Fprintf(os.Stderr, "%s: unable to load resource: %s (%s)\n", f.Filename, r.Resource, r.Error)
+	}
+	buildMenu(p, control)
+	if p.Anchor {
+		applyAnchors(control)
+	}
+	buildToolTip(control)
+	buildHelpProvider(p, control)
+	code, err := ast.Parse(f.Script)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", f.Filename, err)
+	}
+	handlers := bindEvents(p, control, code)
 	resx := resx.NewResx()
 	resName := filepath.Join(p.Output, control.Name+".resx")
 	exportResources(resx, control)
 	hasResources := resx.Count() > 0
 	resx.Save(resName)
-	exportForm(p, control)
+	todos, err := exportForm(p, control, code, handlers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", f.Filename, err)
+	}
 	exportFormDesigner(p, control, hasResources)
+	writeSupportClasses(p, control)
+
+	kind := "form"
+	if f.Root.TypeName == "VB.UserControl" {
+		kind = "usercontrol"
+	} else {
+		p.forms = append(p.forms, control.Name)
+	}
+	report := newFileReport(control.Name, kind, f.Filename)
+	report.addControls(control)
+	report.FailedResources = ctx.failedResources
+	report.ContextMenus = contextMenus(control)
+	report.Untranslated = todos
+	p.addReport(report)
+	for _, c := range report.unsupported() {
+		fmt.Fprintf(os.Stderr, "%s: unsupported control %s (%s)\n", f.Filename, c.Name, c.TypeName)
+	}
+	if err := writeFormReport(p, report); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", f.Filename, err)
+	}
+}
+
+// WriteProject writes the project file and the Program class that starts the
+// application, after all the files of the project are exported.
+func WriteProject(p *ProjectInfo) {
 	writeProgramFile(p)
 	writeProjectFile(p)
 }
@@ -37,22 +175,131 @@ func writeProjectFile(p *ProjectInfo) {
 		log.Fatal(err)
 	}
 	defer file.Close()
-	file.WriteString(fmt.Sprintf(`<Project Sdk="Microsoft.NET.Sdk.WindowsDesktop">
+
+	targetFramework := p.TargetFramework
+	if targetFramework == "" {
+		targetFramework = DefaultTargetFramework
+	}
+	platform := p.Platform
+	if platform == "" {
+		platform = DefaultPlatform
+	}
+
+	// On .NET Microsoft.VisualBasic is part of the framework
+	properties := ""
+	references := `      <Reference Include="Microsoft.VisualBasic" />
+`
+	if !p.isNetFramework() {
+		properties = fmt.Sprintf(`        <Nullable>disable</Nullable>
+        <ImplicitUsings>disable</ImplicitUsings>
+        <ApplicationHighDpiMode>%s</ApplicationHighDpiMode>
+`, highDpiMode())
+		references = ""
+	}
+
+	file.WriteString(fmt.Sprintf(`<Project Sdk="Microsoft.NET.Sdk">
     <PropertyGroup>
         <GenerateAssemblyInfo>False</GenerateAssemblyInfo>
         <OutputType>WinExe</OutputType>
         <UseWindowsForms>True</UseWindowsForms>
-        <PlatformTarget>x86</PlatformTarget>
+        <PlatformTarget>%s</PlatformTarget>
         <GenerateResourceUsePreserializedResources>True</GenerateResourceUsePreserializedResources>
-        <TargetFramework>net48</TargetFramework>
+        <TargetFramework>%s</TargetFramework>
         <LangVersion>default</LangVersion>
-		 <RootNamespace>%s</RootNamespace>
-    </PropertyGroup>    
+%s        <RootNamespace>%s</RootNamespace>
+        <StartupObject>%s.Program</StartupObject>
+    </PropertyGroup>
 
-	<ItemGroup>
-      <PackageReference Include="System.Resources.Extensions" Version="9.0.0" />
+    <ItemGroup>
+%s      <PackageReference Include="System.Resources.Extensions" Version="9.0.0" />
     </ItemGroup>
-</Project>`, p.Namespace))
+</Project>`, platform, targetFramework, properties, p.Namespace, p.Namespace, references))
+}
+
+// highDpiMode returns the HighDpiMode of .NET applications. Forms are laid out
+// in pixels for a single resolution, they are only scaled when --dpi converts
+// them for a resolution other than 96.
+func highDpiMode() string {
+	if vb6.DPI != 96 {
+		return "SystemAware"
+	}
+	return "DpiUnaware"
+}
+
+// startupCode returns the statements of Program.Main that start the startup
+// object of the project, and whether they use the FormsContext class.
+func startupCode(p *ProjectInfo) ([]string, bool) {
+	switch {
+	case strings.EqualFold(p.Startup, "Sub Main"):
+		if s, ok := p.symbols["main"]; ok && s.Kind == procSymbol {
+			return []string{
+				s.Code + "();",
+				"Application.Run(new FormsContext());",
+			}, true
+		}
+		fmt.Fprintf(os.Stderr, "startup object Sub Main not found in the modules\n")
+	case p.Startup == "" || p.Startup == "(None)":
+	default:
+		// The startup form is the default instance of the form
+		for _, name := range p.forms {
+			if strings.EqualFold(name, p.Startup) {
+				return []string{fmt.Sprintf("Application.Run(%s.Default);", name)}, false
+			}
+		}
+		fmt.Fprintf(os.Stderr, "startup form %s not found\n", p.Startup)
+	}
+	return []string{"// TODO: start the application, the project has no startup object"}, false
+}
+
+// writeFormsContext writes the FormsContext class, which keeps the application
+// running until the last form is closed, as VB6 does after Sub Main returns.
+func writeFormsContext(w *ExportWriter) {
+	w.Writeln()
+	w.Write("private sealed class FormsContext : ApplicationContext")
+	w.Write("{")
+	w.WriteIndent(func() {
+		w.Write("public FormsContext()")
+		w.Write("{")
+		w.WriteIndent(func() {
+			w.Write("Application.Idle += OnIdle;")
+		})
+		w.Write("}")
+		w.Writeln()
+		w.Write("private void OnIdle(object sender, EventArgs e)")
+		w.Write("{")
+		w.WriteIndent(func() {
+			w.Write("if (Application.OpenForms.Count == 0)")
+			w.Write("{")
+			w.WriteIndent(func() {
+				w.Write("Application.Idle -= OnIdle;")
+				w.Write("ExitThread();")
+			})
+			w.Write("}")
+		})
+		w.Write("}")
+	})
+	w.Write("}")
+}
+
+// visualStylesCode returns the statements of Program.Main that configure the
+// application before the first form is created.
+func visualStylesCode(p *ProjectInfo) []string {
+	if p.isNetFramework() {
+		return []string{
+			"Application.EnableVisualStyles();",
+			"Application.SetCompatibleTextRenderingDefault(false);",
+		}
+	}
+
+	// ApplicationConfiguration is generated from the project file since .NET 6
+	if strings.HasPrefix(p.TargetFramework, "net5.") {
+		return []string{
+			fmt.Sprintf("Application.SetHighDpiMode(HighDpiMode.%s);", highDpiMode()),
+			"Application.EnableVisualStyles();",
+			"Application.SetCompatibleTextRenderingDefault(false);",
+		}
+	}
+	return []string{"ApplicationConfiguration.Initialize();"}
 }
 
 func writeProgramFile(p *ProjectInfo) {
@@ -62,19 +309,37 @@ func writeProgramFile(p *ProjectInfo) {
 		log.Fatal(err)
 	}
 	defer file.Close()
-	file.WriteString(fmt.Sprintf(`using System;
-using System.Windows.Forms;
-
-namespace %s;
-
-internal static class Program
-{
-	[STAThread]
-    public static void Main()
-    {
-        Application.Run(new frmMainMenu());
-    }
-}`, p.Namespace))
+
+	startup, formsContext := startupCode(p)
+
+	writer := NewExportWriter(file)
+	writer.Write("using System;")
+	writer.Write("using System.Windows.Forms;")
+	writer.Writeln()
+	writer.Writef("namespace %s;", p.Namespace)
+	writer.Writeln()
+	writer.Write("internal static class Program")
+	writer.Write("{")
+	writer.WriteIndent(func() {
+		writer.Write("[STAThread]")
+		writer.Write("public static void Main()")
+		writer.Write("{")
+		writer.WriteIndent(func() {
+			for _, line := range visualStylesCode(p) {
+				writer.Write(line)
+			}
+			writer.Writeln()
+			for _, line := range startup {
+				writer.Write(line)
+			}
+		})
+		writer.Write("}")
+
+		if formsContext {
+			writeFormsContext(writer)
+		}
+	})
+	writer.Write("}")
 }
 
 func exportResources(res resx.Resx, f *Control) {
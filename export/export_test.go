@@ -0,0 +1,76 @@
+package export
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+func TestCheckTargetFramework(t *testing.T) {
+	tests := []struct {
+		tfm string
+		ok  bool
+	}{
+		{"net462", true},
+		{"net48", true},
+		{"net481", true},
+		{"net5.0-windows", true},
+		{"net8.0-windows", true},
+		{"net8.0-windows10.0.19041.0", true},
+		{"net461", false},
+		{"net8.0", false},
+		{"net8.0-android", false},
+		{"netcoreapp3.1", false},
+		{"net4.8", false},
+		{"netstandard2.0", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := CheckTargetFramework(test.tfm)
+		if test.ok && err != nil {
+			t.Errorf("%q: unexpected error %v", test.tfm, err)
+		}
+		if !test.ok && !errors.Is(err, ErrUnsupportedFramework) {
+			t.Errorf("%q: got error %v, want %v", test.tfm, err, ErrUnsupportedFramework)
+		}
+	}
+}
+
+func TestCheckPlatform(t *testing.T) {
+	for _, platform := range []string{"AnyCPU", "x86", "x64", "ARM64"} {
+		if err := CheckPlatform(platform); err != nil {
+			t.Errorf("%q: unexpected error %v", platform, err)
+		}
+	}
+	for _, platform := range []string{"", "arm", "Win32"} {
+		if err := CheckPlatform(platform); !errors.Is(err, ErrUnsupportedPlatform) {
+			t.Errorf("%q: got error %v, want %v", platform, err, ErrUnsupportedPlatform)
+		}
+	}
+}
+
+func TestVisualStylesCode(t *testing.T) {
+	t.Cleanup(func() { vb6.DPI = 96 })
+
+	tests := []struct {
+		tfm  string
+		dpi  int
+		want string
+	}{
+		{"net48", 96, "Application.EnableVisualStyles();"},
+		{"net5.0-windows", 96, "Application.SetHighDpiMode(HighDpiMode.DpiUnaware);"},
+		{"net5.0-windows", 144, "Application.SetHighDpiMode(HighDpiMode.SystemAware);"},
+		{"net8.0-windows", 144, "ApplicationConfiguration.Initialize();"},
+	}
+
+	for _, test := range tests {
+		vb6.DPI = test.dpi
+		code := visualStylesCode(&ProjectInfo{TargetFramework: test.tfm})
+		if !slices.Contains(code, test.want) {
+			t.Errorf("%s at %d DPI: got %q, want %q", test.tfm, test.dpi, code, test.want)
+		}
+	}
+}
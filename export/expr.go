@@ -0,0 +1,931 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6/ast"
+)
+
+// C# operator precedence, from loosest to tightest
+const (
+	precTernary = iota + 1
+	precOr
+	precXor
+	precAnd
+	precEquality
+	precRelational
+	precAdditive
+	precMul
+	precUnary
+	precPrimary
+)
+
+// value is a translated expression.
+type value struct {
+	code   string
+	typ    string // C# type, empty when unknown
+	prec   int
+	konst  bool     // compile time constant
+	lvalue bool     // variable, field or element that can be assigned
+	ctl    *Control // set when the expression is a control or the form itself
+}
+
+func primary(code string, typ string) value {
+	return value{code: code, typ: typ, prec: precPrimary}
+}
+
+// wrap returns the code of v, parenthesized if it binds weaker than prec.
+func wrap(v value, prec int) string {
+	if v.prec < prec {
+		return "(" + v.code + ")"
+	}
+	return v.code
+}
+
+func binary(x value, op string, y value, prec int, typ string) value {
+	return value{
+		code:  wrap(x, prec) + " " + op + " " + wrap(y, prec+1),
+		typ:   typ,
+		prec:  prec,
+		konst: x.konst && y.konst,
+	}
+}
+
+func cast(typ string, v value) value {
+	// Casting an enum constant back to its own type, e.g. (int)MsgBoxStyle.OkOnly
+	if rest, ok := strings.CutPrefix(v.code, "(int)"+typ+"."); ok && !strings.ContainsAny(rest, " ()") {
+		return primary(typ+"."+rest, typ)
+	}
+	return value{
+		code:  "(" + typ + ")" + wrap(v, precUnary),
+		typ:   typ,
+		prec:  precUnary,
+		konst: v.konst,
+	}
+}
+
+func convert(fn string, v value, typ string) value {
+	return primary(fmt.Sprintf("%s(%s)", fn, v.code), typ)
+}
+
+func isIntegral(typ string) bool {
+	switch typ {
+	case "byte", "short", "int", "long":
+		return true
+	}
+	return false
+}
+
+func isNumeric(typ string) bool {
+	switch typ {
+	case "float", "double", "decimal":
+		return true
+	}
+	return isIntegral(typ)
+}
+
+// numericRank orders the numeric types by how wide they are.
+var numericRank = map[string]int{
+	"byte": 1, "short": 2, "int": 3, "long": 4, "decimal": 5, "float": 6, "double": 7,
+}
+
+func widest(x string, y string) string {
+	if !isNumeric(x) || !isNumeric(y) {
+		return ""
+	}
+	if numericRank[x] >= numericRank[y] {
+		return x
+	}
+	return y
+}
+
+var convertFuncs = map[string]string{
+	"byte":     "Convert.ToByte",
+	"short":    "Convert.ToInt16",
+	"int":      "Convert.ToInt32",
+	"long":     "Convert.ToInt64",
+	"float":    "Convert.ToSingle",
+	"double":   "Convert.ToDouble",
+	"decimal":  "Convert.ToDecimal",
+	"bool":     "Convert.ToBoolean",
+	"string":   "Convert.ToString",
+	"DateTime": "Convert.ToDateTime",
+}
+
+// coerce applies the implicit conversions VB6 performs when a value of one
+// type is used where another type is expected.
+func coerce(v value, to string) value {
+	from := v.typ
+	if to == "" || from == to || from == "" || from == "dynamic" || to == "dynamic" || to == "object" {
+		return v
+	}
+	if isNumeric(from) && isNumeric(to) {
+		if v.konst && isIntegral(from) && isIntegral(to) {
+			return v
+		}
+		if numericRank[from] < numericRank[to] && !(to == "decimal" && !isIntegral(from)) {
+			return v
+		}
+	}
+	switch {
+	case to == "bool" && isNumeric(from):
+		return value{code: wrap(v, precEquality+1) + " != 0", typ: "bool", prec: precEquality}
+	case from == "bool" && isNumeric(to):
+		return value{code: wrap(v, precTernary+1) + " ? -1 : 0", typ: to, prec: precTernary}
+	}
+	if fn, ok := convertFuncs[to]; ok {
+		return convert(fn, v, to)
+	}
+	return v
+}
+
+// untranslatable is raised when a statement cannot be translated. The
+// statement is then written as a commented out TODO.
+type untranslatable struct {
+	reason string
+}
+
+func (t *translator) fail(format string, args ...any) {
+	panic(untranslatable{fmt.Sprintf(format, args...)})
+}
+
+// expr translates an expression. Controls are returned as they are, use
+// value to get the default property of a control instead.
+func (t *translator) expr(x ast.Expr) value {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return t.literal(x)
+	case *ast.Ident:
+		return t.ident(x.Name)
+	case *ast.MeExpr:
+		return t.me()
+	case *ast.MemberExpr:
+		return t.member(x, nil, false)
+	case *ast.CallExpr:
+		return t.call(x.Fn, x.Args, false)
+	case *ast.ParenExpr:
+		v := t.value(x.X)
+		v.code = "(" + v.code + ")"
+		v.prec = precPrimary
+		v.lvalue = false
+		return v
+	case *ast.UnaryExpr:
+		return t.unary(x)
+	case *ast.BinaryExpr:
+		return t.binary(x)
+	case *ast.NewExpr:
+		typ := csTypeName(x.Type)
+		return primary(fmt.Sprintf("new %s()", typ), typ)
+	case *ast.TypeOfExpr:
+		v := t.expr(x.X)
+		return value{
+			code: wrap(v, precRelational) + " is " + csTypeName(x.Type),
+			typ:  "bool",
+			prec: precRelational,
+		}
+	case *ast.AddressOfExpr:
+		return primary(csName(x.Name), "")
+	case *ast.FileNumber:
+		return coerce(t.value(x.X), "int")
+	}
+	t.fail("unsupported expression")
+	return value{}
+}
+
+// value translates an expression, replacing controls by their default
+// property (Text1 = "" assigns the Text property).
+func (t *translator) value(x ast.Expr) value {
+	v := t.expr(x)
+	if v.ctl != nil {
+		return t.defaultMember(v)
+	}
+	return v
+}
+
+// cond translates an expression used as a condition.
+func (t *translator) cond(x ast.Expr) string {
+	return coerce(t.value(x), "bool").code
+}
+
+func (t *translator) literal(x *ast.BasicLit) value {
+	switch x.Kind {
+	case ast.IntLit:
+		return intLiteral(x.Value)
+	case ast.FloatLit:
+		return floatLiteral(x.Value)
+	case ast.StringLit:
+		return value{code: csString(x.Value), typ: "string", prec: precPrimary, konst: true}
+	case ast.DateLit:
+		return primary(fmt.Sprintf("DateTime.Parse(%s, System.Globalization.CultureInfo.InvariantCulture)", csString(x.Value)), "DateTime")
+	case ast.BoolLit:
+		return value{code: lower(x.Value), typ: "bool", prec: precPrimary, konst: true}
+	case ast.NothingLit, ast.EmptyLit:
+		return primary("null", "")
+	case ast.NullLit:
+		return primary("DBNull.Value", "object")
+	}
+	t.fail("unsupported literal")
+	return value{}
+}
+
+func intLiteral(text string) value {
+	long := strings.HasSuffix(text, "&")
+	text = strings.TrimRight(text, "%&")
+
+	var n int64
+	switch {
+	case len(text) > 2 && (text[1] == 'H' || text[1] == 'h'):
+		u, _ := strconv.ParseUint(text[2:], 16, 64)
+		n = int64(u)
+		if !long && len(text)-2 <= 4 && n >= 0x8000 {
+			n = int64(int16(n))
+		} else if n >= 0x80000000 && n <= 0xFFFFFFFF {
+			n = int64(int32(n))
+		}
+		if n >= 0 {
+			return value{code: fmt.Sprintf("0x%X", n), typ: "int", prec: precPrimary, konst: true}
+		}
+	case len(text) > 2 && (text[1] == 'O' || text[1] == 'o'):
+		u, _ := strconv.ParseUint(text[2:], 8, 64)
+		n = int64(u)
+	default:
+		var err error
+		n, err = strconv.ParseInt(text, 10, 64)
+		if err != nil || n > 0x7FFFFFFF {
+			return value{code: text + "D", typ: "double", prec: precPrimary, konst: true}
+		}
+	}
+
+	if n < 0 {
+		return value{code: strconv.FormatInt(n, 10), typ: "int", prec: precUnary, konst: true}
+	}
+	return value{code: strconv.FormatInt(n, 10), typ: "int", prec: precPrimary, konst: true}
+}
+
+func floatLiteral(text string) value {
+	typ, suffix := "double", ""
+	switch text[len(text)-1] {
+	case '!':
+		typ, suffix = "float", "F"
+		text = text[:len(text)-1]
+	case '@':
+		typ, suffix = "decimal", "M"
+		text = text[:len(text)-1]
+	case '#':
+		text = text[:len(text)-1]
+	}
+	text = strings.NewReplacer("D", "E", "d", "E").Replace(text)
+	if strings.HasPrefix(text, ".") {
+		text = "0" + text
+	}
+	if typ == "double" && !strings.ContainsAny(text, ".E") {
+		text += ".0"
+	}
+	return value{code: text + suffix, typ: typ, prec: precPrimary, konst: true}
+}
+
+func (t *translator) me() value {
+	if t.static {
+		t.fail("Me is not available in modules")
+	}
+	if t.form == nil {
+		return primary("this", t.name)
+	}
+	return value{code: "this", typ: t.form.TypeName, prec: precPrimary, ctl: t.form}
+}
+
+func (t *translator) ident(name string) value {
+	if t.isResult(name) {
+		return t.result
+	}
+
+	if s := t.lookup(name); s != nil {
+		switch s.Kind {
+		case procSymbol:
+			return t.callProc(s, nil)
+		case controlSymbol:
+			if s.Indexed {
+				return primary(s.Code, s.Type+"[]")
+			}
+			return value{code: s.Code, typ: s.Type, prec: precPrimary, ctl: s.Control}
+		case propSymbol:
+			if s.Indexed {
+				return t.callProc(s, nil)
+			}
+		}
+		return value{
+			code:   s.Code,
+			typ:    s.Type,
+			prec:   precPrimary,
+			konst:  s.Kind == constSymbol,
+			lvalue: s.Kind == varSymbol || s.Kind == propSymbol,
+		}
+	}
+
+	if m, ok := t.formMember(name); ok {
+		return t.member(m, nil, false)
+	}
+
+	if v, ok := lookupConstant(name); ok {
+		return v
+	}
+
+	if b, ok := builtins[key(name)]; ok {
+		return t.callBuiltin(b, nil, false)
+	}
+
+	switch key(name) {
+	case "err":
+		return primary("Information.Err()", "ErrObject")
+	case "extender":
+		// The extender properties, such as Visible and Left, are properties of
+		// the user control itself
+		if t.isUserControl() {
+			return t.me()
+		}
+	case "app", "screen", "clipboard", "printer", "debug", "forms":
+		t.fail("the %s object is not supported", name)
+	}
+
+	v := primary(csName(name), "")
+	v.lvalue = true
+	return v
+}
+
+func (t *translator) unary(x *ast.UnaryExpr) value {
+	v := t.value(x.X)
+	switch x.Op {
+	case "+":
+		return v
+	case "-":
+		code := "-" + wrap(v, precUnary)
+		if strings.HasPrefix(code, "--") {
+			code = "-(" + v.code + ")"
+		}
+		return value{code: code, typ: v.typ, prec: precUnary, konst: v.konst}
+	}
+	if isNumeric(v.typ) {
+		return value{code: "~" + wrap(v, precUnary), typ: v.typ, prec: precUnary, konst: v.konst}
+	}
+	v = coerce(v, "bool")
+	return value{code: "!" + wrap(v, precUnary), typ: "bool", prec: precUnary, konst: v.konst}
+}
+
+func (t *translator) binary(x *ast.BinaryExpr) value {
+	l := t.value(x.X)
+	r := t.value(x.Y)
+
+	switch x.Op {
+	case "&":
+		if l.typ != "string" && r.typ != "string" {
+			l = convert("Convert.ToString", l, "string")
+		}
+		return binary(l, "+", r, precAdditive, "string")
+	case "+":
+		if l.typ == "string" && r.typ == "string" {
+			return binary(l, "+", r, precAdditive, "string")
+		}
+		return binary(l, "+", r, precAdditive, widest(l.typ, r.typ))
+	case "-":
+		return binary(l, "-", r, precAdditive, widest(l.typ, r.typ))
+	case "*":
+		return binary(l, "*", r, precMul, widest(l.typ, r.typ))
+	case "/":
+		if isIntegral(l.typ) && isIntegral(r.typ) {
+			l = cast("double", l)
+		}
+		typ := widest(l.typ, r.typ)
+		if typ != "decimal" && typ != "" {
+			typ = "double"
+		}
+		return binary(l, "/", r, precMul, typ)
+	case "\\", "Mod":
+		op := "/"
+		if x.Op == "Mod" {
+			op = "%"
+		}
+		if !isIntegral(l.typ) {
+			l = coerce(l, "int")
+		}
+		if !isIntegral(r.typ) {
+			r = coerce(r, "int")
+		}
+		return binary(l, op, r, precMul, "int")
+	case "^":
+		return primary(fmt.Sprintf("Math.Pow(%s, %s)", l.code, r.code), "double")
+	case "=", "<>", "<", ">", "<=", ">=", "Is":
+		return t.compare(x.Op, l, r)
+	case "Like":
+		return primary(fmt.Sprintf("LikeOperator.LikeString(%s, %s, CompareMethod.Binary)", coerce(l, "string").code, coerce(r, "string").code), "bool")
+	case "And", "Or", "Xor":
+		ops := map[string]string{"And": "&", "Or": "|", "Xor": "^"}
+		precs := map[string]int{"And": precAnd, "Or": precOr, "Xor": precXor}
+		if l.typ == "bool" || r.typ == "bool" {
+			return binary(coerce(l, "bool"), ops[x.Op], coerce(r, "bool"), precs[x.Op], "bool")
+		}
+		typ := ""
+		if isIntegral(l.typ) && isIntegral(r.typ) {
+			typ = "int"
+		}
+		return binary(l, ops[x.Op], r, precs[x.Op], typ)
+	case "Eqv":
+		return binary(coerce(l, "bool"), "==", coerce(r, "bool"), precEquality, "bool")
+	case "Imp":
+		l = coerce(l, "bool")
+		not := value{code: "!" + wrap(l, precUnary), typ: "bool", prec: precUnary}
+		return binary(not, "|", coerce(r, "bool"), precOr, "bool")
+	}
+
+	t.fail("unsupported operator %s", x.Op)
+	return value{}
+}
+
+// compare translates a comparison. Strings are compared ordinally and
+// strings compared with numbers are converted to numbers first.
+func (t *translator) compare(op string, l value, r value) value {
+	switch op {
+	case "=", "Is":
+		op = "=="
+	case "<>":
+		op = "!="
+	}
+
+	switch {
+	case l.typ == "string" && isNumeric(r.typ):
+		l = convert("Conversion.Val", l, "double")
+	case r.typ == "string" && isNumeric(l.typ):
+		r = convert("Conversion.Val", r, "double")
+	case l.typ == "bool" && isNumeric(r.typ):
+		r = coerce(r, "bool")
+	case r.typ == "bool" && isNumeric(l.typ):
+		l = coerce(l, "bool")
+	}
+
+	if op == "==" || op == "!=" {
+		return binary(l, op, r, precEquality, "bool")
+	}
+
+	if l.typ == "string" || r.typ == "string" {
+		cmp := primary(fmt.Sprintf("string.CompareOrdinal(%s, %s)", l.code, r.code), "int")
+		return binary(cmp, op, intConst("0"), precRelational, "bool")
+	}
+	return binary(l, op, r, precRelational, "bool")
+}
+
+// call translates a call or an array index, Fn(Args). When stmt is set the
+// call is used as a statement and its result is discarded.
+func (t *translator) call(fn ast.Expr, args []*ast.Arg, stmt bool) value {
+	switch f := fn.(type) {
+	case *ast.Ident:
+		if t.isResult(f.Name) && len(args) == 0 {
+			return t.result
+		}
+		if s := t.lookup(f.Name); s != nil {
+			switch s.Kind {
+			case procSymbol, propSymbol:
+				return t.callProc(s, args)
+			case controlSymbol:
+				if !s.Indexed || len(args) != 1 || args[0].Value == nil {
+					t.fail("%s is not a control array", f.Name)
+				}
+				index := coerce(t.value(args[0].Value), "int")
+				return value{code: fmt.Sprintf("%s[%s]", s.Code, index.code), typ: s.Type, prec: precPrimary, ctl: s.Control}
+			}
+			return t.index(t.ident(f.Name), args)
+		}
+		switch key(f.Name) {
+		case "unload":
+			if len(args) == 1 && args[0].Value != nil {
+				v := t.expr(args[0].Value)
+				return primary(wrap(v, precPrimary)+".Close()", "void")
+			}
+		case "load":
+			t.fail("Load is not supported")
+		}
+		if b, ok := builtins[key(f.Name)]; ok {
+			return t.callBuiltin(b, args, stmt)
+		}
+		if m, ok := t.formMember(f.Name); ok {
+			return t.member(m, args, true)
+		}
+		if _, ok := lookupConstant(f.Name); !ok {
+			return primary(fmt.Sprintf("%s(%s)", csName(f.Name), t.args(args, nil)), "")
+		}
+	case *ast.MemberExpr:
+		return t.member(f, args, true)
+	}
+	return t.index(t.value(fn), args)
+}
+
+// index translates an array element access.
+func (t *translator) index(v value, args []*ast.Arg) value {
+	if len(args) == 0 {
+		return v
+	}
+	indices := make([]string, 0, len(args))
+	for _, a := range args {
+		if a.Value == nil {
+			t.fail("missing array index")
+		}
+		indices = append(indices, coerce(t.value(a.Value), "int").code)
+	}
+	typ := v.typ
+	if i := strings.Index(typ, "["); i != -1 {
+		typ = typ[:i]
+	} else if typ != "" && typ != "string" {
+		typ = "dynamic"
+	}
+	return value{
+		code:   wrap(v, precPrimary) + "[" + strings.Join(indices, ", ") + "]",
+		typ:    typ,
+		prec:   precPrimary,
+		lvalue: true,
+	}
+}
+
+// args translates an argument list, converting the arguments to the given
+// parameter types.
+func (t *translator) args(args []*ast.Arg, params []string) string {
+	parts := make([]string, 0, len(args))
+	for i, a := range args {
+		if a.Value == nil {
+			parts = append(parts, "default")
+			continue
+		}
+		v := t.value(a.Value)
+		if i < len(params) {
+			v = coerce(v, params[i])
+		}
+		code := v.code
+		if a.Name != "" {
+			code = a.Name + ": " + code
+		}
+		parts = append(parts, code)
+	}
+	for len(parts) > 0 && parts[len(parts)-1] == "default" {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (t *translator) callBuiltin(b builtin, args []*ast.Arg, stmt bool) value {
+	parts := make([]string, 0, len(args))
+	for i, a := range args {
+		if a.Value == nil {
+			parts = append(parts, "default")
+			continue
+		}
+		v := t.value(a.Value)
+		if i < len(b.Params) {
+			v = coerce(v, b.Params[i])
+		}
+		if i < len(b.Casts) && b.Casts[i] != "" {
+			v = cast(b.Casts[i], v)
+		}
+		code := v.code
+		if a.Name != "" {
+			code = strings.ToUpper(a.Name[:1]) + a.Name[1:] + ": " + code
+		}
+		parts = append(parts, code)
+	}
+	for len(parts) > 0 && parts[len(parts)-1] == "default" {
+		parts = parts[:len(parts)-1]
+	}
+
+	joined := strings.Join(parts, ", ")
+	v := primary(b.Name+"("+joined+")", b.Type)
+	switch {
+	case strings.Contains(b.Name, "%s"):
+		v = value{code: fmt.Sprintf(b.Name, joined), typ: b.Type, prec: precUnary}
+	case b.Prop && len(args) == 0:
+		v = primary(b.Name, b.Type)
+	}
+	if b.Cast != "" && !stmt {
+		v = cast(b.Cast, v)
+	}
+	return v
+}
+
+// callProc translates a call to a procedure declared in the file.
+func (t *translator) callProc(s *symbol, args []*ast.Arg) value {
+	if s.Unsupported != "" {
+		t.fail("%s", s.Unsupported)
+	}
+	if s.Kind == propSymbol && !s.Indexed {
+		if len(args) > 0 {
+			return t.index(primary(s.Code, s.Type), args)
+		}
+		v := primary(s.Code, s.Type)
+		v.lvalue = true
+		return v
+	}
+
+	if h, ok := t.handlers[s.Proc]; ok {
+		if len(args) > 0 || h.event.Args != "EventArgs" {
+			t.fail("calling event handlers with arguments is not supported")
+		}
+		return primary(s.Code+"(this, EventArgs.Empty)", "void")
+	}
+
+	code := s.Code
+	if s.Kind == propSymbol {
+		code = accessor("Get", s.Code)
+	}
+
+	params := make([]*ast.Param, 0)
+	if s.Proc != nil {
+		params = s.Proc.Params
+	}
+
+	parts := make([]string, 0, len(args))
+	for i, a := range args {
+		var p *ast.Param
+		switch {
+		case a.Name != "":
+			for _, param := range params {
+				if strings.EqualFold(param.Name, a.Name) {
+					p = param
+				}
+			}
+		case i < len(params):
+			p = params[i]
+		case len(params) > 0 && params[len(params)-1].ParamArray:
+			p = params[len(params)-1]
+		}
+
+		if a.Value == nil {
+			if p != nil && p.Optional {
+				parts = append(parts, t.paramDefault(p))
+				continue
+			}
+			t.fail("missing argument")
+		}
+
+		var v value
+		if p == nil || p.ParamArray {
+			v = t.value(a.Value)
+		} else {
+			typ := t.paramType(p)
+			if isPrimitive(typ) {
+				v = coerce(t.value(a.Value), typ)
+			} else {
+				v = t.expr(a.Value)
+			}
+		}
+
+		code := v.code
+		if p != nil && p.ByRef && !p.Optional && !p.IsArray && v.lvalue {
+			code = "ref " + code
+		}
+		if a.Name != "" && p != nil {
+			code = csName(p.Name) + ": " + code
+		}
+		parts = append(parts, code)
+	}
+
+	return primary(fmt.Sprintf("%s(%s)", code, strings.Join(parts, ", ")), s.Type)
+}
+
+// formMember returns Me.name when name is an unqualified member of the form,
+// such as Caption or Hide.
+func (t *translator) formMember(name string) (*ast.MemberExpr, bool) {
+	if t.form == nil {
+		return nil, false
+	}
+	if _, ok := controlMember(t.form, key(name)); !ok {
+		return nil, false
+	}
+	return &ast.MemberExpr{X: &ast.MeExpr{}, Name: name}, true
+}
+
+// member translates X.Name. When call is set the member is called with args.
+func (t *translator) member(m *ast.MemberExpr, args []*ast.Arg, call bool) value {
+	var recv value
+	if m.X == nil {
+		if len(t.with) == 0 {
+			t.fail("member access outside of a With block")
+		}
+		recv = t.with[len(t.with)-1]
+	} else {
+		if id, ok := m.X.(*ast.Ident); ok && t.lookup(id.Name) == nil {
+			if v, ok := t.enumMember(id.Name, m.Name); ok {
+				return v
+			}
+			if v, ok := t.globalObject(id.Name, m.Name, args); ok {
+				return v
+			}
+			if v, ok := t.ambient(id.Name, m.Name); ok {
+				return v
+			}
+		}
+		recv = t.expr(m.X)
+	}
+
+	if m.Bang {
+		v := value{
+			code:   wrap(recv, precPrimary) + "[" + csString(m.Name) + "]",
+			typ:    "dynamic",
+			prec:   precPrimary,
+			lvalue: true,
+		}
+		return t.index(v, args)
+	}
+
+	name := key(m.Name)
+
+	if recv.ctl != nil {
+		// The controls of a form, as in frmMain.cmdOk
+		if c := findControl(recv.ctl, name); c != nil {
+			return t.formControl(recv, c, args)
+		}
+		if mem, ok := controlMember(recv.ctl, name); ok {
+			return t.controlMember(recv, name, mem, args, call)
+		}
+	} else if members, ok := typeMembers[shortType(recv.typ)]; ok {
+		// Objects of the control libraries, such as list items and nodes
+		if mem, ok := members[name]; ok {
+			return t.controlMember(recv, name, mem, args, call)
+		}
+	} else if ut := t.structType(recv.typ); ut != nil {
+		if v, ok := t.field(recv, ut, name, args); ok {
+			return v
+		}
+		t.fail("%s is not a field of %s", m.Name, ut.Code)
+	}
+
+	code := wrap(recv, precPrimary) + "." + csName(m.Name)
+	if name == "show" && len(args) > 0 {
+		if v := t.value(args[0].Value); v.code == "1" {
+			return primary(wrap(recv, precPrimary)+".ShowDialog()", "")
+		}
+		args = nil
+	}
+	if call {
+		return primary(fmt.Sprintf("%s(%s)", code, t.args(args, nil)), "")
+	}
+	v := primary(code, "")
+	v.lvalue = true
+	return v
+}
+
+// formControl translates a control of the form recv, an element when it is
+// a control array.
+func (t *translator) formControl(recv value, c *Control, args []*ast.Arg) value {
+	if c.ArrayName == "" {
+		return t.index(value{code: wrap(recv, precPrimary) + "." + c.Name, typ: c.TypeName, prec: precPrimary, ctl: c}, args)
+	}
+	code := wrap(recv, precPrimary) + "." + c.ArrayName
+	if len(args) == 0 {
+		return primary(code, c.TypeName+"[]")
+	}
+	if len(args) != 1 || args[0].Value == nil {
+		t.fail("%s is not a control array", c.ArrayName)
+	}
+	index := coerce(t.value(args[0].Value), "int")
+	return value{code: fmt.Sprintf("%s[%s]", code, index.code), typ: c.TypeName, prec: precPrimary, ctl: c}
+}
+
+func (t *translator) controlMember(recv value, name string, m member, args []*ast.Arg, call bool) value {
+	target := wrap(recv, precPrimary) + "." + m.Name
+
+	switch name {
+	case "additem":
+		if len(args) == 2 && args[1].Value != nil {
+			index := coerce(t.value(args[1].Value), "int")
+			return primary(fmt.Sprintf("%s.Items.Insert(%s, %s)", wrap(recv, precPrimary), index.code, t.value(args[0].Value).code), "void")
+		}
+	case "getdata", "peekdata":
+		// The data is assigned to the variable passed to GetData
+		if len(args) == 0 || args[0].Value == nil {
+			t.fail("%s without a variable", name)
+		}
+		v := t.expr(args[0].Value)
+		if !v.lvalue {
+			t.fail("%s into an expression", name)
+		}
+		fn := m.Name
+		if v.typ == "byte[]" {
+			fn = strings.Replace(fn, "String", "Bytes", 1)
+		}
+		return primary(fmt.Sprintf("%s = %s.%s()", v.code, wrap(recv, precPrimary), fn), "void")
+	case "zorder":
+		if len(args) > 0 && t.value(args[0].Value).code == "1" {
+			return primary(wrap(recv, precPrimary)+".SendToBack()", "void")
+		}
+		args = nil
+	case "popupmenu":
+		// Invisible menus are converted to a ContextMenuStrip
+		if len(args) == 0 || args[0].Value == nil {
+			t.fail("PopupMenu without a menu")
+		}
+		if len(args) > 2 {
+			t.fail("PopupMenu at a position is not supported")
+		}
+		menu := t.expr(args[0].Value)
+		return primary(wrap(menu, precPrimary)+".Show(Cursor.Position)", "void")
+	case "show":
+		// Show vbModal shows the form as a dialog
+		if len(args) > 0 && args[0].Value != nil && t.value(args[0].Value).code == "1" {
+			return primary(wrap(recv, precPrimary)+".ShowDialog()", "void")
+		}
+		args = nil
+	case "move":
+		bounds := []string{"Left", "Top", "Width", "Height"}
+		parts := make([]string, 4)
+		for i := range bounds {
+			if i < len(args) && args[i].Value != nil {
+				parts[i] = coerce(t.value(args[i].Value), "int").code
+			} else {
+				parts[i] = wrap(recv, precPrimary) + "." + bounds[i]
+			}
+		}
+		return primary(fmt.Sprintf("%s(%s)", target, strings.Join(parts, ", ")), "void")
+	}
+
+	if m.Method {
+		return primary(fmt.Sprintf("%s(%s)", target, t.args(args, m.Params)), m.Type)
+	}
+
+	if m.Elem != "" && len(args) == 1 && args[0].Value != nil {
+		return t.item(target, m.Elem, t.value(args[0].Value))
+	}
+
+	v := getMember(target, m)
+	return t.index(v, args)
+}
+
+// item translates an element of a 1-based collection, indexed by position or
+// by key.
+func (t *translator) item(target string, typ string, index value) value {
+	code := index.code
+	if index.typ != "string" {
+		index = coerce(index, "int")
+		if n, err := strconv.Atoi(index.code); err == nil {
+			code = strconv.Itoa(n - 1)
+		} else {
+			code = wrap(index, precAdditive) + " - 1"
+		}
+	}
+	return value{code: target + "[" + code + "]", typ: typ, prec: precPrimary, lvalue: true}
+}
+
+func getMember(target string, m member) value {
+	if m.Get != "" {
+		return value{code: fmt.Sprintf(m.Get, target), typ: m.Type, prec: precUnary}
+	}
+	v := primary(target, m.Type)
+	v.lvalue = true
+	return v
+}
+
+func (t *translator) defaultMember(v value) value {
+	name, ok := defaultMembers[shortType(v.ctl.TypeName)]
+	if !ok {
+		return v
+	}
+	m, _ := controlMember(v.ctl, name)
+	return getMember(wrap(v, precPrimary)+"."+m.Name, m)
+}
+
+// globalObject translates members of the VB6 global objects such as App and
+// Screen.
+func (t *translator) globalObject(obj string, name string, args []*ast.Arg) (value, bool) {
+	switch key(obj) + "." + key(name) {
+	case "app.path":
+		return primary("Application.StartupPath", "string"), true
+	case "app.title", "app.productname":
+		return primary("Application.ProductName", "string"), true
+	case "app.companyname":
+		return primary("Application.CompanyName", "string"), true
+	case "app.exename":
+		return primary("System.IO.Path.GetFileNameWithoutExtension(Application.ExecutablePath)", "string"), true
+	case "app.major":
+		return primary("new Version(Application.ProductVersion).Major", "int"), true
+	case "app.minor":
+		return primary("new Version(Application.ProductVersion).Minor", "int"), true
+	case "app.revision":
+		return primary("new Version(Application.ProductVersion).Build", "int"), true
+	case "app.previnstance":
+		return primary("(System.Diagnostics.Process.GetProcessesByName(System.Diagnostics.Process.GetCurrentProcess().ProcessName).Length > 1)", "bool"), true
+	case "screen.width":
+		return primary("Screen.PrimaryScreen.Bounds.Width", "int"), true
+	case "screen.height":
+		return primary("Screen.PrimaryScreen.Bounds.Height", "int"), true
+	case "clipboard.settext":
+		return primary(fmt.Sprintf("Clipboard.SetText(%s)", t.args(args, []string{"string"})), "void"), true
+	case "clipboard.gettext":
+		return primary("Clipboard.GetText()", "string"), true
+	case "clipboard.clear":
+		return primary("Clipboard.Clear()", "void"), true
+	case "debug.assert":
+		return primary(fmt.Sprintf("System.Diagnostics.Debug.Assert(%s)", t.args(args, []string{"bool"})), "void"), true
+	}
+
+	switch key(obj) {
+	case "app", "screen", "clipboard", "printer", "debug", "forms":
+		t.fail("%s.%s is not supported", obj, name)
+	}
+	return value{}, false
+}
@@ -0,0 +1,222 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+// flexColumn is a column described by the FormatString of a flex grid.
+type flexColumn struct {
+	Header    string
+	Alignment string
+}
+
+// parseFormatString reads the column and row headers of a flex grid. Columns
+// are separated by | and the text after ; holds the row headers. A column
+// header may start with <, ^ or > to align the column left, center or right.
+func parseFormatString(formatString string) ([]flexColumn, []string) {
+	var rowHeaders []string
+	if semi := strings.Index(formatString, ";"); semi != -1 {
+		rowHeaders = strings.Split(formatString[semi+1:], "|")[1:]
+		formatString = formatString[:semi]
+	}
+	columns := make([]flexColumn, 0)
+	for _, header := range strings.Split(formatString, "|") {
+		column := flexColumn{Header: header}
+		if len(header) > 0 {
+			switch header[0] {
+			case '<':
+				column.Alignment = "System.Windows.Forms.DataGridViewContentAlignment.MiddleLeft"
+			case '^':
+				column.Alignment = "System.Windows.Forms.DataGridViewContentAlignment.MiddleCenter"
+			case '>':
+				column.Alignment = "System.Windows.Forms.DataGridViewContentAlignment.MiddleRight"
+			}
+			if column.Alignment != "" {
+				column.Header = header[1:]
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns, rowHeaders
+}
+
+// FlexGridBuilder builds a read-only DataGridView in place of the MSFlexGrid
+// and MSHFlexGrid. The first fixed column becomes the row headers and the
+// fixed rows become the column headers.
+func FlexGridBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+	propCalls := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "BackColor")
+
+	rows, ok := vb6.GetInt("Rows", c.Properties)
+	if !ok {
+		rows = 2
+	}
+	cols, ok := vb6.GetInt("Cols", c.Properties)
+	if !ok {
+		cols = 2
+	}
+	fixedRows, ok := vb6.GetInt("FixedRows", c.Properties)
+	if !ok {
+		fixedRows = 1
+	}
+	fixedCols, ok := vb6.GetInt("FixedCols", c.Properties)
+	if !ok {
+		fixedCols = 1
+	}
+
+	props["ReadOnly"] = toBool(true)
+	props["AllowUserToAddRows"] = toBool(false)
+	props["AllowUserToDeleteRows"] = toBool(false)
+	props["ColumnHeadersVisible"] = toBool(fixedRows > 0)
+	props["RowHeadersVisible"] = toBool(fixedCols > 0)
+	props["ColumnHeadersHeightSizeMode"] = "System.Windows.Forms.DataGridViewColumnHeadersHeightSizeMode.AutoSize"
+
+	if backColor, ok := vb6.GetColor("BackColor", c.Properties); ok {
+		props["DefaultCellStyle.BackColor"] = toColor(backColor)
+	}
+
+	if foreColor, ok := vb6.GetColor("ForeColor", c.Properties); ok {
+		props["DefaultCellStyle.ForeColor"] = toColor(foreColor)
+	}
+
+	if backColorBkg, ok := vb6.GetColor("BackColorBkg", c.Properties); ok {
+		props["BackgroundColor"] = toColor(backColorBkg)
+	}
+
+	if backColorFixed, ok := vb6.GetColor("BackColorFixed", c.Properties); ok {
+		props["EnableHeadersVisualStyles"] = toBool(false)
+		props["ColumnHeadersDefaultCellStyle.BackColor"] = toColor(backColorFixed)
+		props["RowHeadersDefaultCellStyle.BackColor"] = toColor(backColorFixed)
+	}
+
+	if gridColor, ok := vb6.GetColor("GridColor", c.Properties); ok {
+		props["GridColor"] = toColor(gridColor)
+	}
+
+	gridLines, ok := vb6.GetInt("GridLines", c.Properties)
+	if !ok {
+		gridLines = 1
+	}
+	switch gridLines {
+	case 0:
+		props["CellBorderStyle"] = "System.Windows.Forms.DataGridViewCellBorderStyle.None"
+	case 2:
+		props["CellBorderStyle"] = "System.Windows.Forms.DataGridViewCellBorderStyle.Sunken"
+	case 3:
+		props["CellBorderStyle"] = "System.Windows.Forms.DataGridViewCellBorderStyle.Raised"
+	default:
+		props["CellBorderStyle"] = "System.Windows.Forms.DataGridViewCellBorderStyle.Single"
+	}
+
+	switch selectionMode, _ := vb6.GetInt("SelectionMode", c.Properties); selectionMode {
+	case 1:
+		props["SelectionMode"] = "System.Windows.Forms.DataGridViewSelectionMode.FullRowSelect"
+	case 2:
+		props["SelectionMode"] = "System.Windows.Forms.DataGridViewSelectionMode.FullColumnSelect"
+	default:
+		props["SelectionMode"] = "System.Windows.Forms.DataGridViewSelectionMode.CellSelect"
+	}
+
+	allowUserResizing, _ := vb6.GetInt("AllowUserResizing", c.Properties)
+	props["AllowUserToResizeColumns"] = toBool(allowUserResizing == 1 || allowUserResizing == 3)
+	props["AllowUserToResizeRows"] = toBool(allowUserResizing == 2 || allowUserResizing == 3)
+
+	if scrollBars, ok := vb6.GetInt("ScrollBars", c.Properties); ok {
+		switch scrollBars {
+		case 0:
+			props["ScrollBars"] = "System.Windows.Forms.ScrollBars.None"
+		case 1:
+			props["ScrollBars"] = "System.Windows.Forms.ScrollBars.Horizontal"
+		case 2:
+			props["ScrollBars"] = "System.Windows.Forms.ScrollBars.Vertical"
+		case 3:
+			props["ScrollBars"] = "System.Windows.Forms.ScrollBars.Both"
+		}
+	}
+
+	var format []flexColumn
+	var rowHeaders []string
+	if formatString, ok := vb6.GetStr("FormatString", c.Properties); ok {
+		format, rowHeaders = parseFormatString(formatString)
+		if len(format) > cols {
+			cols = len(format)
+		}
+	}
+
+	// The first fixed column is shown as the row headers, other fixed columns
+	// are frozen
+	children := make([]*Control, 0, cols)
+	childNames := make([]string, 0, cols)
+	for i := 0; i < cols; i++ {
+		columnProps := make(map[string]string)
+		if i < len(format) {
+			columnProps["HeaderText"] = toStr(format[i].Header)
+			if format[i].Alignment != "" {
+				columnProps["DefaultCellStyle.Alignment"] = format[i].Alignment
+				columnProps["HeaderCell.Style.Alignment"] = format[i].Alignment
+			}
+		}
+		// Columns in the FormatString are sized to fit their header text
+		width, ok := vb6.GetTwips(fmt.Sprintf("ColWidth(%d)", i), c.Properties)
+		if !ok && i < len(format) && format[i].Header != "" {
+			width, ok = len(format[i].Header)*7+10, true
+		}
+		if ok {
+			if i == 0 && fixedCols > 0 {
+				props["RowHeadersWidth"] = toInt(width)
+			} else {
+				columnProps["Width"] = toInt(width)
+			}
+		}
+		if i == 0 && fixedCols > 0 {
+			continue
+		}
+		columnProps["SortMode"] = "System.Windows.Forms.DataGridViewColumnSortMode.NotSortable"
+		if i < fixedCols {
+			columnProps["Frozen"] = toBool(true)
+		}
+
+		column := &Control{
+			Name:      fmt.Sprintf("%s_Col%d", controlName(c), i),
+			TypeName:  "System.Windows.Forms.DataGridViewTextBoxColumn",
+			Resources: make(map[string]any),
+			Props:     columnProps,
+			Children:  make([]*Control, 0),
+			MustInit:  false,
+			SkipAdd:   true,
+		}
+		children = append(children, column)
+		childNames = append(childNames, fmt.Sprintf("this.%s", column.Name))
+	}
+	if len(children) > 0 {
+		propCalls["Columns"] = fmt.Sprintf("AddRange(%s)", toArrayOfType(childNames, "System.Windows.Forms.DataGridViewColumn"))
+	}
+
+	// The rows can only be added once the grid has columns
+	calls := make([]string, 0)
+	if len(children) > 0 && rows > fixedRows {
+		calls = append(calls, fmt.Sprintf("Rows.Add(%s)", toInt(rows-fixedRows)))
+	}
+	for i, header := range rowHeaders {
+		if i < rows-fixedRows && fixedCols > 0 {
+			calls = append(calls, fmt.Sprintf("Rows[%d].HeaderCell.Value = %s", i, toStr(header)))
+		}
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.DataGridView",
+		Resources: make(map[string]any),
+		Props:     props,
+		PropCalls: propCalls,
+		Calls:     calls,
+		Children:  children,
+		MustInit:  true,
+	}
+}
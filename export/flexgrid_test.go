@@ -0,0 +1,123 @@
+package export
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseFormatString(t *testing.T) {
+	columns, rowHeaders := parseFormatString("|<Name|^Qty|>Price;|One|Two")
+	want := []flexColumn{
+		{Header: ""},
+		{Header: "Name", Alignment: "System.Windows.Forms.DataGridViewContentAlignment.MiddleLeft"},
+		{Header: "Qty", Alignment: "System.Windows.Forms.DataGridViewContentAlignment.MiddleCenter"},
+		{Header: "Price", Alignment: "System.Windows.Forms.DataGridViewContentAlignment.MiddleRight"},
+	}
+	if !slices.Equal(columns, want) {
+		t.Errorf("got columns %v, want %v", columns, want)
+	}
+	if want := []string{"One", "Two"}; !slices.Equal(rowHeaders, want) {
+		t.Errorf("got row headers %v, want %v", rowHeaders, want)
+	}
+
+	columns, rowHeaders = parseFormatString("Name|Qty")
+	if len(columns) != 2 || columns[0].Header != "Name" || columns[0].Alignment != "" || rowHeaders != nil {
+		t.Errorf("got columns %v and row headers %v", columns, rowHeaders)
+	}
+}
+
+func TestFlexGridBuilder(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Object = "{5E9E78A0-531B-11CF-91F6-C2863C385E30}#1.0#0"; "MSFLXGRD.OCX"
+Object = "{0ECD9B60-23AA-11D0-B351-00A0C9055D8E}#6.0#0"; "MSHFLXGD.OCX"
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin MSFlexGridLib.MSFlexGrid grdItems
+      Height          =   2000
+      Left            =   120
+      TabIndex        =   0
+      Top             =   120
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   3528
+      _Version        =   393216
+      Rows            =   4
+      Cols            =   4
+      BackColorFixed  =   12632256
+      GridLines       =   0
+      SelectionMode   =   1
+      AllowUserResizing=   1
+      FormatString    =   "|<Name|^Qty|>Price;|One|Two"
+   End
+   Begin MSHierarchicalFlexGridLib.MSHFlexGrid grdTotals
+      Height          =   800
+      Left            =   120
+      TabIndex        =   1
+      Top             =   2200
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   1411
+      _Version        =   393216
+      Cols            =   3
+      FixedCols       =   2
+      FixedRows       =   0
+   End
+End
+`)
+
+	grid := testControl(t, form, "grdItems")
+	if grid.TypeName != "System.Windows.Forms.DataGridView" {
+		t.Errorf("grdItems is a %s, want System.Windows.Forms.DataGridView", grid.TypeName)
+	}
+	checkProps(t, grid, map[string]string{
+		"ReadOnly":                                "true",
+		"ColumnHeadersVisible":                    "true",
+		"RowHeadersVisible":                       "true",
+		"CellBorderStyle":                         "System.Windows.Forms.DataGridViewCellBorderStyle.None",
+		"SelectionMode":                           "System.Windows.Forms.DataGridViewSelectionMode.FullRowSelect",
+		"AllowUserToResizeColumns":                "true",
+		"AllowUserToResizeRows":                   "false",
+		"EnableHeadersVisualStyles":               "false",
+		"ColumnHeadersDefaultCellStyle.BackColor": "System.Drawing.Color.FromArgb(192, 192, 192)",
+		"BackColor":                               "",
+	})
+
+	// The fixed column is shown as the row headers, the other columns come
+	// from the FormatString
+	names := make([]string, 0, len(grid.Children))
+	for _, c := range grid.Children {
+		names = append(names, c.Name)
+	}
+	if want := []string{"grdItems_Col1", "grdItems_Col2", "grdItems_Col3"}; !slices.Equal(names, want) {
+		t.Errorf("got columns %v, want %v", names, want)
+	}
+	checkProps(t, testControl(t, form, "grdItems_Col2"), map[string]string{
+		"HeaderText":                 `"Qty"`,
+		"DefaultCellStyle.Alignment": "System.Windows.Forms.DataGridViewContentAlignment.MiddleCenter",
+		"SortMode":                   "System.Windows.Forms.DataGridViewColumnSortMode.NotSortable",
+		"Frozen":                     "",
+	})
+
+	// The rows are added after the columns, without the fixed rows
+	want := []string{`Rows.Add(3)`, `Rows[0].HeaderCell.Value = "One"`, `Rows[1].HeaderCell.Value = "Two"`}
+	if !slices.Equal(grid.Calls, want) {
+		t.Errorf("got calls %v, want %v", grid.Calls, want)
+	}
+
+	// Fixed columns after the first are frozen
+	totals := testControl(t, form, "grdTotals")
+	checkProps(t, totals, map[string]string{
+		"ColumnHeadersVisible": "false",
+		"CellBorderStyle":      "System.Windows.Forms.DataGridViewCellBorderStyle.Single",
+	})
+	if len(totals.Children) != 2 {
+		t.Fatalf("grdTotals has %d columns, want 2", len(totals.Children))
+	}
+	checkProps(t, totals.Children[0], map[string]string{"Frozen": "true"})
+	checkProps(t, totals.Children[1], map[string]string{"Frozen": ""})
+	if want := []string{"Rows.Add(2)"}; !slices.Equal(totals.Calls, want) {
+		t.Errorf("got calls %v, want %v", totals.Calls, want)
+	}
+}
@@ -3,16 +3,19 @@ package export
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/guthius/vb6conv/vb6/ast"
 )
 
-func exportForm(p *ProjectInfo, f *Control) error {
+func exportForm(p *ProjectInfo, f *Control, code *ast.File, handlers map[*ast.ProcDecl]*handler) ([]CodeIssue, error) {
 	filename := filepath.Join(p.Output, f.Name+".cs")
 	file, err := os.Create(filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
 
+	var todos []CodeIssue
 	writer := NewExportWriter(file)
 	writer.Write("using System;")
 	writer.Write("using System.Collections.Generic;")
@@ -23,20 +26,104 @@ func exportForm(p *ProjectInfo, f *Control) error {
 	writer.Write("using System.Text;")
 	writer.Write("using System.Threading.Tasks;")
 	writer.Write("using System.Windows.Forms;")
+	writer.Write("using Microsoft.VisualBasic;")
+	writer.Write("using Microsoft.VisualBasic.CompilerServices;")
 	writer.Writeln()
 	writer.Writef("namespace %s;", p.Namespace)
 	writer.Writeln()
-	writer.Writef("public partial class %s : Form", f.Name)
+	writer.Writef("public partial class %s : %s", f.Name, shortType(f.TypeName))
 	writer.Write("{")
 	writer.WriteIndent(func() {
+		t := newTranslator(code, writer, f.Name)
+		t.handlers = handlers
+		t.shared = p.symbols
+		t.sharedTypes = p.types
+		t.classes = p.classes
+		t.addControls(f, true)
+		t.declareTypes()
+		t.declareFile()
+
+		decls, procs := t.splitDecls()
+		t.writeDecls(decls)
+		if len(decls) > 0 {
+			t.separate()
+		}
+
 		writer.Writef("public %s()", f.Name)
 		writer.Write("{")
 		writer.WriteIndent(func() {
 			writer.Write("InitializeComponent();")
+			if f.MdiChild && p.mdiForm != "" {
+				// As in VB6, showing an MDI child form loads the MDI form
+				writer.Writef("MdiParent = %s.Default;", p.mdiForm)
+			}
+			for _, proc := range code.Procedures() {
+				if h, ok := handlers[proc]; ok && h.control == f && h.event.Name == "" {
+					writer.Writef("%s(this, EventArgs.Empty);", csName(proc.Name))
+				}
+			}
 		})
 		writer.Write("}")
+
+		if shortType(f.TypeName) == "Form" {
+			writeDefaultInstance(writer, f.Name)
+		}
+
+		if f.Fixed {
+			writeFixedWndProc(writer)
+		}
+
+		t.writeDecls(procs)
+		todos = t.todos
 	})
 	writer.Write("}")
 
-	return nil
+	return todos, nil
+}
+
+// writeDefaultInstance writes the Default property, which stands in for the
+// instance VB6 creates when the form is used by its name. A closed form is
+// created again the next time it is used.
+func writeDefaultInstance(w *ExportWriter, name string) {
+	w.Writeln()
+	w.Writef("private static %s defaultInstance;", name)
+	w.Writeln()
+	w.Writef("public static %s Default", name)
+	w.Write("{")
+	w.WriteIndent(func() {
+		w.Write("get")
+		w.Write("{")
+		w.WriteIndent(func() {
+			w.Write("if (defaultInstance == null || defaultInstance.IsDisposed)")
+			w.Write("{")
+			w.WriteIndent(func() {
+				w.Writef("defaultInstance = new %s();", name)
+			})
+			w.Write("}")
+			w.Write("return defaultInstance;")
+		})
+		w.Write("}")
+	})
+	w.Write("}")
+}
+
+// writeFixedWndProc keeps the user from moving the form (Moveable = False) by
+// ignoring the move system command, which is also sent when the caption is
+// dragged.
+func writeFixedWndProc(w *ExportWriter) {
+	w.Writeln()
+	w.Write("protected override void WndProc(ref Message m)")
+	w.Write("{")
+	w.WriteIndent(func() {
+		w.Write("const int WM_SYSCOMMAND = 0x0112;")
+		w.Write("const int SC_MOVE = 0xF010;")
+		w.Write("if (m.Msg == WM_SYSCOMMAND && ((int)m.WParam & 0xFFF0) == SC_MOVE)")
+		w.Write("{")
+		w.WriteIndent(func() {
+			w.Write("return;")
+		})
+		w.Write("}")
+		w.Write("base.WndProc(ref m);")
+	})
+	w.Write("}")
 }
@@ -0,0 +1,124 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guthius/vb6conv/resx"
+	"github.com/guthius/vb6conv/vb6"
+)
+
+func TestFormProperties(t *testing.T) {
+	tests := []struct {
+		name  string
+		props string
+		want  map[string]string
+		fixed bool
+	}{
+		{"sizable", ``, map[string]string{
+			"FormBorderStyle": "",
+			"MinimizeBox":     "true",
+			"MaximizeBox":     "true",
+			"ShowInTaskbar":   "",
+			"WindowState":     "",
+		}, false},
+		{"dialog", `   BorderStyle     =   3  'Fixed Dialog
+`, map[string]string{
+			"FormBorderStyle": "System.Windows.Forms.FormBorderStyle.FixedDialog",
+			"MinimizeBox":     "false",
+			"MaximizeBox":     "false",
+			"ShowInTaskbar":   "false",
+		}, false},
+		{"dialog in taskbar", `   BorderStyle     =   3  'Fixed Dialog
+   MinButton       =   -1  'True
+   ShowInTaskbar   =   -1  'True
+`, map[string]string{
+			"MinimizeBox":   "true",
+			"MaximizeBox":   "false",
+			"ShowInTaskbar": "",
+		}, false},
+		{"borderless", `   BorderStyle     =   0  'None
+`, map[string]string{
+			"FormBorderStyle": "System.Windows.Forms.FormBorderStyle.None",
+		}, false},
+		{"tool window", `   BorderStyle     =   5  'Sizable ToolWindow
+   ControlBox      =   0   'False
+`, map[string]string{
+			"FormBorderStyle": "System.Windows.Forms.FormBorderStyle.SizableToolWindow",
+			"ControlBox":      "false",
+			"ShowInTaskbar":   "false",
+		}, false},
+		{"maximized", `   WindowState     =   2  'Maximized
+   KeyPreview      =   -1  'True
+   Moveable        =   0   'False
+`, map[string]string{
+			"WindowState": "System.Windows.Forms.FormWindowState.Maximized",
+			"KeyPreview":  "true",
+		}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			form, _ := buildTestForm(t, `VERSION 5.00
+Begin VB.Form frmTest
+`+test.props+`   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+End
+`)
+			checkProps(t, form, test.want)
+			if form.Fixed != test.fixed {
+				t.Errorf("got Fixed %v, want %v", form.Fixed, test.fixed)
+			}
+		})
+	}
+}
+
+func TestFormIcon(t *testing.T) {
+	dir := t.TempDir()
+	icon := []byte{0, 0, 1, 0, 1, 0}
+	// The size of binary resources follows two unknown dwords
+	frx := append([]byte{0, 0, 0, 0, 0, 0, 0, 0, byte(len(icon)), 0, 0, 0}, icon...)
+	if err := os.WriteFile(filepath.Join(dir, "frmTest.frx"), frx, 0644); err != nil {
+		t.Fatal(err)
+	}
+	frm := `VERSION 5.00
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Icon            =   "frmTest.frx":0000
+End
+`
+	if err := os.WriteFile(filepath.Join(dir, "frmTest.frm"), []byte(frm), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := vb6.Load(filepath.Join(dir, "frmTest.frm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &BuildContext{project: &ProjectInfo{}}
+	form := buildControl(ctx, f.Root)
+	checkProps(t, form, map[string]string{
+		"Icon": `((System.Drawing.Icon)(resources.GetObject("$this.Icon")))`,
+	})
+	if got, ok := form.Resources["$this.Icon"].(resx.Icon); !ok || string(got) != string(icon) {
+		t.Errorf("got icon resource %v, want %v", form.Resources["$this.Icon"], icon)
+	}
+	if len(ctx.failedResources) != 0 {
+		t.Errorf("got failed resources %v", ctx.failedResources)
+	}
+
+	// A missing icon is reported
+	if err := os.Remove(filepath.Join(dir, "frmTest.frx")); err != nil {
+		t.Fatal(err)
+	}
+	ctx = &BuildContext{project: &ProjectInfo{}}
+	form = buildControl(ctx, f.Root)
+	checkProps(t, form, map[string]string{"Icon": ""})
+	if len(ctx.failedResources) != 1 || ctx.failedResources[0].Property != "Icon" {
+		t.Errorf("got failed resources %v, want the icon", ctx.failedResources)
+	}
+}
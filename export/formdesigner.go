@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6"
 )
 
 func exportFormDesigner(p *ProjectInfo, f *Control, hasRes bool) error {
@@ -49,6 +52,9 @@ func exportFormDesigner(p *ProjectInfo, f *Control, hasRes bool) error {
 		for _, c := range f.Children {
 			writeControlDefinitions(p, c, writer)
 		}
+		for _, a := range getControlArrays(f, nil) {
+			writer.Writef("internal %s[] %s;", a.TypeName, a.Name)
+		}
 	})
 
 	writer.Write("}")
@@ -56,11 +62,13 @@ func exportFormDesigner(p *ProjectInfo, f *Control, hasRes bool) error {
 	return nil
 }
 
+// writeControlDefinitions declares the controls as internal fields, VB6 code
+// can use the controls of other forms.
 func writeControlDefinitions(p *ProjectInfo, f *Control, w *ExportWriter) {
 	for _, c := range f.Children {
 		writeControlDefinitions(p, c, w)
 	}
-	w.Writef("private %s %s;", f.TypeName, f.Name)
+	w.Writef("internal %s %s;", f.TypeName, f.Name)
 }
 
 func writeControlInitializers(p *ProjectInfo, f *Control, w *ExportWriter) {
@@ -85,6 +93,9 @@ func writeControlProperties(p *ProjectInfo, f *Control, w *ExportWriter, root bo
 	w.Write("//")
 	w.Writef("// %s", f.Name)
 	w.Write("//")
+	if f.Unsupported {
+		writeControlSource(f.Original, w)
+	}
 	if !f.SkipName {
 		w.Writef("%s.Name = \"%s\";", name, f.Name)
 	}
@@ -94,6 +105,12 @@ func writeControlProperties(p *ProjectInfo, f *Control, w *ExportWriter, root bo
 	for k, v := range f.PropCalls {
 		w.Writef("%s.%s.%s;", name, k, v)
 	}
+	for _, v := range f.Calls {
+		w.Writef("%s.%s;", name, v)
+	}
+	for _, e := range f.Events {
+		w.Writef("%s.%s += new %s(this.%s);", name, e.Event, e.Delegate, e.Method)
+	}
 	for _, c := range f.Children {
 		if !c.SkipAdd {
 			w.Writef("%s.Controls.Add(this.%s);", name, c.Name)
@@ -101,6 +118,42 @@ func writeControlProperties(p *ProjectInfo, f *Control, w *ExportWriter, root bo
 	}
 }
 
+// writeControlSource writes the original definition of a control that could
+// not be converted as a comment.
+func writeControlSource(c *vb6.Control, w *ExportWriter) {
+	w.Writef("// TODO: %s could not be converted, the original definition was:", c.TypeName)
+	indent := -1
+	for _, line := range c.Source {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" {
+			if n := len(line) - len(trimmed); indent == -1 || n < indent {
+				indent = n
+			}
+		}
+	}
+	indent = max(indent, 0)
+	for _, line := range c.Source {
+		line = strings.TrimRight(line, " \t\r")
+		if len(line) >= indent && strings.TrimSpace(line[:indent]) == "" {
+			line = line[indent:]
+		}
+		w.Writef("//   %s", line)
+	}
+}
+
+func writeControlArrays(f *Control, w *ExportWriter) {
+	for _, a := range getControlArrays(f, nil) {
+		elements := make([]string, 0, len(a.Elements))
+		for _, c := range a.Elements {
+			if c == nil {
+				elements = append(elements, "null")
+			} else {
+				elements = append(elements, fmt.Sprintf("this.%s", c.Name))
+			}
+		}
+		w.Writef("this.%s = %s;", a.Name, toArrayOfType(elements, a.TypeName))
+	}
+}
+
 func getControlsToInit(f *Control, current []*Control) []*Control {
 	if f.MustInit {
 		current = append(current, f)
@@ -151,6 +204,7 @@ func writeInitializeComponent(p *ProjectInfo, f *Control, w *ExportWriter, hasRe
 		for _, c := range f.Children {
 			writeControlInitializers(p, c, w)
 		}
+		writeControlArrays(f, w)
 		writeBeginInit(w, init)
 		writeControlProperties(p, f, w, true)
 		writeEndInit(w, init)
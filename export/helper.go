@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/guthius/vb6conv/vb6"
 )
@@ -25,7 +26,49 @@ func toStr(s string) string {
 	return strconv.Quote(s)
 }
 
+// systemColors holds the SystemColors for the indices of VB6 system colors
+// (&H80000000 + index), as used by GetSysColor.
+var systemColors = []string{
+	"ScrollBar",
+	"Desktop",
+	"ActiveCaption",
+	"InactiveCaption",
+	"Menu",
+	"Window",
+	"WindowFrame",
+	"MenuText",
+	"WindowText",
+	"ActiveCaptionText",
+	"ActiveBorder",
+	"InactiveBorder",
+	"AppWorkspace",
+	"Highlight",
+	"HighlightText",
+	"Control",
+	"ControlDark",
+	"GrayText",
+	"ControlText",
+	"InactiveCaptionText",
+	"ControlLightLight",
+	"ControlDarkDark",
+	"ControlLight",
+	"InfoText",
+	"Info",
+	"",
+	"HotTrack",
+	"GradientActiveCaption",
+	"GradientInactiveCaption",
+	"MenuHighlight",
+	"MenuBar",
+}
+
 func toColor(c uint32) string {
+	if c&0x80000000 != 0 {
+		if index := c & 0xFF; int(index) < len(systemColors) && systemColors[index] != "" {
+			return fmt.Sprintf("System.Drawing.SystemColors.%s", systemColors[index])
+		}
+	}
+
 	r := (c & 0x0000FF)
 	g := (c & 0x00FF00) >> 8
 	b := (c & 0xFF0000) >> 16
@@ -45,6 +88,42 @@ func toSize(w int, h int) string {
 	return fmt.Sprintf("new System.Drawing.Size(%v, %v)", w, h)
 }
 
+// parsePoint reads a point written by toPoint.
+func parsePoint(s string) (int, int, bool) {
+	var x, y int
+	_, err := fmt.Sscanf(s, "new System.Drawing.Point(%d, %d)", &x, &y)
+	return x, y, err == nil
+}
+
+// parseSize reads a size written by toSize.
+func parseSize(s string) (int, int, bool) {
+	var w, h int
+	_, err := fmt.Sscanf(s, "new System.Drawing.Size(%d, %d)", &w, &h)
+	return w, h, err == nil
+}
+
+// toDate converts an OLE automation date, the number of days since 30 December
+// 1899, into a DateTime.
+func toDate(serial float64) string {
+	d := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).Add(time.Duration(serial * float64(24*time.Hour)))
+	return fmt.Sprintf("new System.DateTime(%d, %d, %d, %d, %d, %d, 0)", d.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second())
+}
+
+// toDock converts the VB6 Align property into a DockStyle.
+func toDock(align int) (string, bool) {
+	switch align {
+	case 1:
+		return "System.Windows.Forms.DockStyle.Top", true
+	case 2:
+		return "System.Windows.Forms.DockStyle.Bottom", true
+	case 3:
+		return "System.Windows.Forms.DockStyle.Left", true
+	case 4:
+		return "System.Windows.Forms.DockStyle.Right", true
+	}
+	return "", false
+}
+
 func toFont(f *vb6.Font) string {
 	var fontStyle string
 	switch f.Weight {
@@ -0,0 +1,46 @@
+package export
+
+import "testing"
+
+func TestToColor(t *testing.T) {
+	tests := []struct {
+		color uint32
+		want  string
+	}{
+		{0x000080FF, "System.Drawing.Color.FromArgb(255, 128, 0)"},
+		{0x00000000, "System.Drawing.Color.FromArgb(0, 0, 0)"},
+		{0x80000005, "System.Drawing.SystemColors.Window"},
+		{0x8000000F, "System.Drawing.SystemColors.Control"},
+		{0x80000012, "System.Drawing.SystemColors.ControlText"},
+		{0x8000001E, "System.Drawing.SystemColors.MenuBar"},
+		// Indices without a SystemColor fall back to the RGB value
+		{0x80000019, "System.Drawing.Color.FromArgb(25, 0, 0)"},
+		{0x800000FF, "System.Drawing.Color.FromArgb(255, 0, 0)"},
+	}
+
+	for _, test := range tests {
+		if got := toColor(test.color); got != test.want {
+			t.Errorf("toColor(%#x) = %s, want %s", test.color, got, test.want)
+		}
+	}
+}
+
+func TestToDock(t *testing.T) {
+	tests := []struct {
+		align int
+		want  string
+	}{
+		{0, ""},
+		{1, "System.Windows.Forms.DockStyle.Top"},
+		{2, "System.Windows.Forms.DockStyle.Bottom"},
+		{3, "System.Windows.Forms.DockStyle.Left"},
+		{4, "System.Windows.Forms.DockStyle.Right"},
+		{5, ""},
+	}
+
+	for _, test := range tests {
+		if got, ok := toDock(test.align); got != test.want || ok != (test.want != "") {
+			t.Errorf("toDock(%d) = %s, %v, want %s", test.align, got, ok, test.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"math"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+// anchorMargin is the distance in twips from the right or bottom edge of its
+// container within which a control is anchored to that edge.
+const anchorMargin = 240
+
+// applyAnchors anchors the controls of a form to the edges of their container
+// they are close to, so the form can be resized. Controls close to the right
+// or bottom edge move with it, controls that also fill most of the container
+// stretch with it.
+func applyAnchors(f *Control) {
+	w, h, ok := parseSize(f.Props["ClientSize"])
+	if !ok {
+		return
+	}
+	anchorChildren(f, w, h)
+}
+
+func anchorChildren(container *Control, w int, h int) {
+	margin := vb6.TwipsToPixels(anchorMargin)
+	for _, c := range container.Children {
+		if c.IsComponent {
+			continue
+		}
+
+		cw, ch, hasSize := parseSize(c.Props["Size"])
+		switch {
+		case c.TypeName == "System.Windows.Forms.TabPage":
+			cw, ch = tabPageSize(container, w, h)
+		case !hasSize:
+			// Containers without a size of their own fill their parent
+			cw, ch = w, h
+		}
+
+		_, docked := c.Props["Dock"]
+		_, anchored := c.Props["Anchor"]
+		x, y, hasLocation := parsePoint(c.Props["Location"])
+		if !docked && !anchored && hasLocation && hasSize {
+			if anchor := getAnchor(x, y, cw, ch, w, h, margin); anchor != "" {
+				c.Props["Anchor"] = anchor
+			}
+		}
+
+		anchorChildren(c, cw, ch)
+	}
+}
+
+// tabPageSize returns the size of the pages of a tab control of size w, h,
+// which is the size of the tab control without its tabs.
+func tabPageSize(tabs *Control, w int, h int) (int, int) {
+	tabHeight, orientation := 300, 0
+	if c := tabs.Original; c != nil {
+		if n, ok := vb6.GetInt("TabHeight", c.Properties); ok {
+			tabHeight = n
+		}
+		orientation, _ = vb6.GetInt("TabOrientation", c.Properties)
+		tabHeight = int(float64(tabHeight) * math.Abs(vb6.GetScale(c).Y))
+	}
+	header := vb6.TwipsToPixels(tabHeight)
+
+	// Tabs on the left or right take up width instead of height
+	if orientation == 2 || orientation == 3 {
+		return max(w-header, 0), h
+	}
+	return w, max(h-header, 0)
+}
+
+// getAnchor returns the AnchorStyles of a control at x, y of size cw, ch in a
+// container of size w, h. It is empty for controls anchored top left.
+func getAnchor(x int, y int, cw int, ch int, w int, h int, margin int) string {
+	left, top, right, bottom := true, true, false, false
+
+	if w-(x+cw) <= margin {
+		right = true
+		left = cw >= w/2
+	}
+	if h-(y+ch) <= margin {
+		bottom = true
+		top = ch >= h/2
+	}
+
+	if left && top && !right && !bottom {
+		return ""
+	}
+
+	styles := make([]string, 0, 4)
+	for _, s := range []struct {
+		name string
+		set  bool
+	}{{"Top", top}, {"Bottom", bottom}, {"Left", left}, {"Right", right}} {
+		if s.set {
+			styles = append(styles, "System.Windows.Forms.AnchorStyles."+s.name)
+		}
+	}
+	return "((System.Windows.Forms.AnchorStyles)(" + strings.Join(styles, " | ") + "))"
+}
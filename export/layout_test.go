@@ -0,0 +1,183 @@
+package export
+
+import "testing"
+
+func TestGetAnchor(t *testing.T) {
+	const (
+		top    = "System.Windows.Forms.AnchorStyles.Top"
+		bottom = "System.Windows.Forms.AnchorStyles.Bottom"
+		left   = "System.Windows.Forms.AnchorStyles.Left"
+		right  = "System.Windows.Forms.AnchorStyles.Right"
+	)
+	anchor := func(styles string) string {
+		return "((System.Windows.Forms.AnchorStyles)(" + styles + "))"
+	}
+
+	tests := []struct {
+		name         string
+		x, y, cw, ch int
+		want         string
+	}{
+		{"top left", 8, 8, 100, 20, ""},
+		{"right", 290, 8, 100, 20, anchor(top + " | " + right)},
+		{"bottom right", 290, 270, 100, 20, anchor(bottom + " | " + right)},
+		{"stretched", 8, 8, 384, 20, anchor(top + " | " + left + " | " + right)},
+		{"filled", 8, 8, 384, 284, anchor(top + " | " + bottom + " | " + left + " | " + right)},
+		{"bottom", 8, 270, 100, 20, anchor(bottom + " | " + left)},
+	}
+
+	for _, test := range tests {
+		if got := getAnchor(test.x, test.y, test.cw, test.ch, 400, 300, 16); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestTabPageSize(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Object = "{BDC217C8-ED16-11CD-956C-0000C04E4C0A}#1.1#0"; "TABCTL32.OCX"
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin TabDlg.SSTab tabTop
+      Height          =   1500
+      TabHeight       =   420
+      TabIndex        =   0
+      Tabs            =   1
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   2646
+      _Version        =   393216
+   End
+   Begin TabDlg.SSTab tabLeft
+      Height          =   1500
+      TabIndex        =   1
+      TabOrientation  =   2
+      Tabs            =   1
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   2646
+      _Version        =   393216
+   End
+End
+`)
+
+	tests := []struct {
+		name string
+		w, h int
+	}{
+		{"tabTop", 200, 72},
+		{"tabLeft", 180, 100},
+	}
+	for _, test := range tests {
+		w, h := tabPageSize(testControl(t, form, test.name), 200, 100)
+		if w != test.w || h != test.h {
+			t.Errorf("%s: got page size %d, %d, want %d, %d", test.name, w, h, test.w, test.h)
+		}
+	}
+}
+
+func TestApplyAnchors(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Object = "{BDC217C8-ED16-11CD-956C-0000C04E4C0A}#1.1#0"; "TABCTL32.OCX"
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4500
+   Begin VB.PictureBox picStatus
+      Align           =   2  'Align Bottom
+      Height          =   300
+      Left            =   0
+      ScaleHeight     =   240
+      ScaleWidth      =   4440
+      TabIndex        =   0
+      Top             =   2700
+      Width           =   4500
+   End
+   Begin VB.TextBox txtNotes
+      Height          =   1000
+      Left            =   120
+      TabIndex        =   1
+      Top             =   120
+      Width           =   4260
+   End
+   Begin VB.CommandButton cmdOk
+      Caption         =   "OK"
+      Height          =   375
+      Left            =   3360
+      TabIndex        =   2
+      Top             =   2400
+      Width           =   1020
+   End
+   Begin VB.Label lblName
+      Caption         =   "Name"
+      Height          =   255
+      Left            =   120
+      TabIndex        =   3
+      Top             =   1200
+      Width           =   1000
+   End
+   Begin TabDlg.SSTab tabMain
+      Height          =   1000
+      Left            =   120
+      TabIndex        =   4
+      Top             =   1200
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   1764
+      _Version        =   393216
+      Tabs            =   1
+      TabHeight       =   420
+      TabCaption(0)   =   "General"
+      Tab(0).ControlEnabled=   -1  'True
+      Tab(0).Control(0)=   "chkDebug"
+      Tab(0).ControlCount=   1
+      Begin VB.CheckBox chkDebug
+         Caption         =   "Debug"
+         Height          =   255
+         Left            =   1800
+         TabIndex        =   5
+         Top             =   660
+         Width           =   1080
+      End
+   End
+   Begin VB.Timer tmrPoll
+      Interval        =   1000
+      Left            =   4000
+      Top             =   2500
+   End
+End
+`)
+	applyAnchors(form)
+
+	tests := []struct {
+		name  string
+		props map[string]string
+	}{
+		{"picStatus", map[string]string{
+			"Dock":     "System.Windows.Forms.DockStyle.Bottom",
+			"Location": "",
+			"Anchor":   "",
+		}},
+		{"txtNotes", map[string]string{
+			"Anchor": "((System.Windows.Forms.AnchorStyles)(System.Windows.Forms.AnchorStyles.Top | System.Windows.Forms.AnchorStyles.Left | System.Windows.Forms.AnchorStyles.Right))",
+		}},
+		{"cmdOk", map[string]string{
+			"Anchor": "((System.Windows.Forms.AnchorStyles)(System.Windows.Forms.AnchorStyles.Bottom | System.Windows.Forms.AnchorStyles.Right))",
+		}},
+		{"lblName", map[string]string{
+			"Anchor": "",
+		}},
+		// Within the page, which is the tab control without its tabs
+		{"chkDebug", map[string]string{
+			"Anchor": "((System.Windows.Forms.AnchorStyles)(System.Windows.Forms.AnchorStyles.Bottom | System.Windows.Forms.AnchorStyles.Right))",
+		}},
+		{"tmrPoll", map[string]string{
+			"Anchor": "",
+		}},
+	}
+	for _, test := range tests {
+		checkProps(t, testControl(t, form, test.name), test.props)
+	}
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+// Errors returned by LoadMapping
+var (
+	ErrMappingNoType     = errors.New("no .NET type given")
+	ErrMappingNoName     = errors.New("no .NET property name given")
+	ErrUnknownConverter  = errors.New("unknown converter")
+	ErrBadEnumValue      = errors.New("enum value is not an integer")
+	ErrEnumWithoutValues = errors.New("enum converter without values")
+)
+
+// Mapping is a declarative description of how ActiveX controls are converted,
+// by ProgID (e.g. Threed.SSPanel).
+type Mapping struct {
+	Controls map[string]*ControlMapping `json:"controls"`
+}
+
+// ControlMapping maps a control to a .NET type and its properties to the
+// properties of that type. The location, size, tab index, visibility, back
+// color and font of controls are always mapped.
+type ControlMapping struct {
+	Type       string                      `json:"type"`
+	Component  bool                        `json:"component"` // The .NET type is a component, such as a Timer
+	Properties map[string]*PropertyMapping `json:"properties"`
+}
+
+// PropertyMapping maps a property to a .NET property. Convert is one of
+// string, bool, int, twips, color or enum. Enums map the integer values in
+// Values to .NET expressions.
+type PropertyMapping struct {
+	Name    string            `json:"name"`
+	Convert string            `json:"convert"`
+	Values  map[string]string `json:"values"`
+
+	enum map[int]string
+}
+
+// LoadMapping reads a JSON mapping file and registers a builder for each of
+// the controls in it.
+func LoadMapping(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var mapping Mapping
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&mapping); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	for progId, m := range mapping.Controls {
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("%s: %s: %w", filename, progId, err)
+		}
+	}
+	for progId, m := range mapping.Controls {
+		Register(progId, m.build)
+	}
+	return nil
+}
+
+func (m *ControlMapping) validate() error {
+	if m.Type == "" {
+		return ErrMappingNoType
+	}
+	for name, p := range m.Properties {
+		if p.Name == "" {
+			return fmt.Errorf("%s: %w", name, ErrMappingNoName)
+		}
+		switch p.Convert {
+		case "string", "bool", "int", "twips", "color":
+		case "enum":
+			if len(p.Values) == 0 {
+				return fmt.Errorf("%s: %w", name, ErrEnumWithoutValues)
+			}
+			p.enum = make(map[int]string, len(p.Values))
+			for k, v := range p.Values {
+				i, err := strconv.Atoi(k)
+				if err != nil {
+					return fmt.Errorf("%s: %w: %s", name, ErrBadEnumValue, k)
+				}
+				p.enum[i] = v
+			}
+		default:
+			return fmt.Errorf("%s: %w: %s", name, ErrUnknownConverter, p.Convert)
+		}
+	}
+	return nil
+}
+
+// convert returns the .NET expression for the value of a property.
+func (p *PropertyMapping) convert(key string, props vb6.PropertyMap) (string, bool) {
+	switch p.Convert {
+	case "string":
+		if v, ok := vb6.GetStr(key, props); ok {
+			return toStr(v), true
+		}
+	case "bool":
+		if v, ok := vb6.GetBool(key, props); ok {
+			return toBool(v), true
+		}
+	case "int":
+		if v, ok := vb6.GetInt(key, props); ok {
+			return toInt(v), true
+		}
+	case "twips":
+		if v, ok := vb6.GetTwips(key, props); ok {
+			return toInt(v), true
+		}
+	case "color":
+		if v, ok := vb6.GetColor(key, props); ok {
+			return toColor(v), true
+		}
+	case "enum":
+		if v, ok := vb6.GetInt(key, props); ok {
+			if value, ok := p.enum[v]; ok {
+				return value, true
+			}
+			// Values missing from the mapping are reported as ignored
+			props[key].Used = false
+		}
+	}
+	return "", false
+}
+
+func (m *ControlMapping) build(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	if !m.Component {
+		applyDefaultPropsForControl(c, props)
+	}
+
+	for key, p := range m.Properties {
+		if v, ok := p.convert(key, c.Properties); ok {
+			props[p.Name] = v
+		}
+	}
+
+	return &Control{
+		Name:        c.Name,
+		TypeName:    m.Type,
+		Resources:   make(map[string]any),
+		Props:       props,
+		Children:    buildControlSlice(ctx, c.Children),
+		MustInit:    false,
+		SkipAdd:     m.Component,
+		SkipName:    m.Component,
+		IsComponent: m.Component,
+	}
+}
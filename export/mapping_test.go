@@ -0,0 +1,99 @@
+package export
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// describeControls lists the types and the sorted properties of the children
+// of a control, one control per paragraph.
+func describeControls(f *Control) string {
+	sb := strings.Builder{}
+	for _, c := range f.Children {
+		fmt.Fprintf(&sb, "%s %s", c.Name, c.TypeName)
+		if c.IsComponent {
+			sb.WriteString(" (component)")
+		}
+		sb.WriteString("\n")
+		keys := make([]string, 0, len(c.Props))
+		for k := range c.Props {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&sb, "\t%s = %s\n", k, c.Props[k])
+		}
+		if ignored := ignoredProperties(c.Original); len(ignored) > 0 {
+			fmt.Fprintf(&sb, "\tignored: %s\n", strings.Join(ignored, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func TestLoadMapping(t *testing.T) {
+	if err := LoadMapping(filepath.Join("testdata", "mapping.json")); err != nil {
+		t.Fatal(err)
+	}
+	form, err := vb6.Load(filepath.Join("testdata", "mapping.frm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &BuildContext{project: &ProjectInfo{}}
+	got := describeControls(buildControl(ctx, form.Root))
+
+	golden := filepath.Join("testdata", "mapping.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("controls differ from %s:\n%s", golden, got)
+	}
+}
+
+func TestLoadMappingErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		err     error
+	}{
+		{"no type", `{"controls": {"A.B": {"properties": {}}}}`, ErrMappingNoType},
+		{"no property name", `{"controls": {"A.B": {"type": "Panel", "properties": {"Caption": {"convert": "string"}}}}}`, ErrMappingNoName},
+		{"unknown converter", `{"controls": {"A.B": {"type": "Panel", "properties": {"Caption": {"name": "Text", "convert": "date"}}}}}`, ErrUnknownConverter},
+		{"enum without values", `{"controls": {"A.B": {"type": "Panel", "properties": {"Style": {"name": "Style", "convert": "enum"}}}}}`, ErrEnumWithoutValues},
+		{"bad enum value", `{"controls": {"A.B": {"type": "Panel", "properties": {"Style": {"name": "Style", "convert": "enum", "values": {"x": "Y"}}}}}}`, ErrBadEnumValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "mapping.json")
+			if err := os.WriteFile(filename, []byte(test.mapping), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := LoadMapping(filename); !errors.Is(err, test.err) {
+				t.Errorf("got error %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	if err := LoadMapping(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
@@ -0,0 +1,252 @@
+package export
+
+import "strings"
+
+// member describes how a VB6 control property or method maps to .NET.
+type member struct {
+	Name   string   // .NET member, may be a path such as Items.Count
+	Type   string   // C# type of the VB6 property
+	Get    string   // format applied when the property is read
+	Set    string   // format applied to values assigned to the property
+	Method bool     // the member is a method
+	Params []string // parameter types of methods
+	Elem   string   // element type of 1-based collections, indexed by position or key
+}
+
+var colorMember = member{
+	Type: "int",
+	Get:  "ColorTranslator.ToOle(%s)",
+	Set:  "ColorTranslator.FromOle(%s)",
+}
+
+// controlMembers maps the VB6 members shared by most controls and forms.
+var controlMembers = map[string]member{
+	"caption":     {Name: "Text", Type: "string"},
+	"text":        {Name: "Text", Type: "string"},
+	"enabled":     {Name: "Enabled", Type: "bool"},
+	"visible":     {Name: "Visible", Type: "bool"},
+	"left":        {Name: "Left", Type: "int"},
+	"top":         {Name: "Top", Type: "int"},
+	"width":       {Name: "Width", Type: "int"},
+	"height":      {Name: "Height", Type: "int"},
+	"tag":         {Name: "Tag", Type: "string", Get: "Convert.ToString(%s)"},
+	"tabindex":    {Name: "TabIndex", Type: "int"},
+	"tabstop":     {Name: "TabStop", Type: "bool"},
+	"hwnd":        {Name: "Handle", Type: "int", Get: "%s.ToInt32()"},
+	"backcolor":   {Name: "BackColor", Type: colorMember.Type, Get: colorMember.Get, Set: colorMember.Set},
+	"forecolor":   {Name: "ForeColor", Type: colorMember.Type, Get: colorMember.Get, Set: colorMember.Set},
+	"listindex":   {Name: "SelectedIndex", Type: "int"},
+	"listcount":   {Name: "Items.Count", Type: "int"},
+	"list":        {Name: "Items", Type: "object[]"},
+	"selstart":    {Name: "SelectionStart", Type: "int"},
+	"sellength":   {Name: "SelectionLength", Type: "int"},
+	"seltext":     {Name: "SelectedText", Type: "string"},
+	"max":         {Name: "Maximum", Type: "int"},
+	"min":         {Name: "Minimum", Type: "int"},
+	"interval":    {Name: "Interval", Type: "int"},
+	"scalewidth":  {Name: "ClientSize.Width", Type: "int"},
+	"scaleheight": {Name: "ClientSize.Height", Type: "int"},
+	"windowstate": {Name: "WindowState", Type: "int", Get: "(int)%s", Set: "(FormWindowState)(%s)"},
+	"additem":     {Name: "Items.Add", Type: "void", Method: true},
+	"removeitem":  {Name: "Items.RemoveAt", Type: "void", Method: true, Params: []string{"int"}},
+	"setfocus":    {Name: "Focus", Type: "void", Method: true},
+	"refresh":     {Name: "Refresh", Type: "void", Method: true},
+	"cls":         {Name: "Invalidate", Type: "void", Method: true},
+	"zorder":      {Name: "BringToFront", Type: "void", Method: true},
+	"move":        {Name: "SetBounds", Type: "void", Method: true},
+	"show":        {Name: "Show", Type: "void", Method: true},
+	"hide":        {Name: "Hide", Type: "void", Method: true},
+	"popupmenu":   {Name: "Show", Type: "void", Method: true},
+}
+
+// typeMembers overrides controlMembers for specific .NET control types.
+var typeMembers = map[string]map[string]member{
+	"CheckBox": {
+		"value": {Name: "CheckState", Type: "int", Get: "(int)%s", Set: "(CheckState)(%s)"},
+	},
+	"RadioButton": {
+		"value": {Name: "Checked", Type: "bool"},
+	},
+	"TextBox": {
+		"locked": {Name: "ReadOnly", Type: "bool"},
+		"clear":  {Name: "Clear", Type: "void", Method: true},
+	},
+	"ComboBox": {
+		"clear":    {Name: "Items.Clear", Type: "void", Method: true},
+		"itemdata": {Name: "Tag", Type: "int[]", Get: "((int[])%s)"},
+	},
+	"ListBox": {
+		"clear":    {Name: "Items.Clear", Type: "void", Method: true},
+		"itemdata": {Name: "Tag", Type: "int[]", Get: "((int[])%s)"},
+		"selected": {Name: "GetSelected", Type: "bool", Method: true, Params: []string{"int"}},
+	},
+	"CheckedListBox": {
+		"clear":    {Name: "Items.Clear", Type: "void", Method: true},
+		"itemdata": {Name: "Tag", Type: "int[]", Get: "((int[])%s)"},
+		"selected": {Name: "GetItemChecked", Type: "bool", Method: true, Params: []string{"int"}},
+	},
+	"ShapeControl": {
+		"fillcolor":   {Name: "FillColor", Type: colorMember.Type, Get: colorMember.Get, Set: colorMember.Set},
+		"bordercolor": {Name: "BorderColor", Type: colorMember.Type, Get: colorMember.Get, Set: colorMember.Set},
+	},
+	"LineControl": {
+		"bordercolor": {Name: "BorderColor", Type: colorMember.Type, Get: colorMember.Get, Set: colorMember.Set},
+	},
+	"HScrollBar": {
+		"value": {Name: "Value", Type: "int"},
+	},
+	"VScrollBar": {
+		"value": {Name: "Value", Type: "int"},
+	},
+	"ListView": {
+		"listitems":     {Name: "Items", Type: "ListView.ListViewItemCollection", Elem: "ListViewItem"},
+		"columnheaders": {Name: "Columns", Type: "ListView.ColumnHeaderCollection", Elem: "ColumnHeader"},
+		"selecteditem":  {Name: "FocusedItem", Type: "ListViewItem"},
+	},
+	"TreeView": {
+		"nodes":        {Name: "Nodes", Type: "TreeNodeCollection", Elem: "TreeNode"},
+		"selecteditem": {Name: "SelectedNode", Type: "TreeNode"},
+	},
+	"ImageList": {
+		"listimages": {Name: "Images", Type: "ImageList.ImageCollection", Elem: "Image"},
+	},
+	"ToolStrip": {
+		"buttons": {Name: "Items", Type: "ToolStripItemCollection", Elem: "ToolStripItem"},
+	},
+	"StatusStrip": {
+		"panels":     {Name: "Items", Type: "ToolStripItemCollection", Elem: "ToolStripItem"},
+		"simpletext": {Name: "Items[0].Text", Type: "string"},
+	},
+	"CommonDialogControl": {
+		"filename":    {Name: "FileName", Type: "string"},
+		"filetitle":   {Name: "FileTitle", Type: "string"},
+		"filter":      {Name: "Filter", Type: "string"},
+		"filterindex": {Name: "FilterIndex", Type: "int"},
+		"defaultext":  {Name: "DefaultExt", Type: "string"},
+		"dialogtitle": {Name: "DialogTitle", Type: "string"},
+		"initdir":     {Name: "InitDir", Type: "string"},
+		"flags":       {Name: "Flags", Type: "int"},
+		"color":       {Name: "Color", Type: "int"},
+		"cancelerror": {Name: "CancelError", Type: "bool"},
+		"showopen":    {Name: "ShowOpen", Type: "void", Method: true},
+		"showsave":    {Name: "ShowSave", Type: "void", Method: true},
+		"showcolor":   {Name: "ShowColor", Type: "void", Method: true},
+		"showfont":    {Name: "ShowFont", Type: "void", Method: true},
+		"showprinter": {Name: "ShowPrinter", Type: "void", Method: true},
+	},
+	"RichTextBox": {
+		"locked":   {Name: "ReadOnly", Type: "bool"},
+		"textrtf":  {Name: "Rtf", Type: "string"},
+		"selrtf":   {Name: "SelectedRtf", Type: "string"},
+		"selcolor": {Name: "SelectionColor", Type: colorMember.Type, Get: colorMember.Get, Set: colorMember.Set},
+		"loadfile": {Name: "LoadFile", Type: "void", Method: true, Params: []string{"string"}},
+		"savefile": {Name: "SaveFile", Type: "void", Method: true, Params: []string{"string"}},
+		"clear":    {Name: "Clear", Type: "void", Method: true},
+	},
+	"DateTimePicker": {
+		"value": {Name: "Value", Type: "DateTime"},
+		"year":  {Name: "Value.Year", Type: "int"},
+		"month": {Name: "Value.Month", Type: "int"},
+		"day":   {Name: "Value.Day", Type: "int"},
+	},
+	"MonthCalendar": {
+		"value": {Name: "SelectionStart", Type: "DateTime"},
+	},
+	"NumericUpDown": {
+		"value":     {Name: "Value", Type: "int", Get: "(int)%s", Set: "(decimal)(%s)"},
+		"increment": {Name: "Increment", Type: "int", Get: "(int)%s", Set: "(decimal)(%s)"},
+		"max":       {Name: "Maximum", Type: "int", Get: "(int)%s", Set: "(decimal)(%s)"},
+		"min":       {Name: "Minimum", Type: "int", Get: "(int)%s", Set: "(decimal)(%s)"},
+	},
+	"WinsockControl": {
+		"state":         {Name: "State", Type: "int"},
+		"bytesreceived": {Name: "BytesReceived", Type: "int"},
+		"remotehost":    {Name: "RemoteHost", Type: "string"},
+		"remotehostip":  {Name: "RemoteHostIP", Type: "string"},
+		"remoteport":    {Name: "RemotePort", Type: "int"},
+		"localport":     {Name: "LocalPort", Type: "int"},
+		"protocol":      {Name: "Protocol", Type: "int"},
+		"connect":       {Name: "Connect", Type: "void", Method: true, Params: []string{"string", "int"}},
+		"listen":        {Name: "Listen", Type: "void", Method: true},
+		"accept":        {Name: "Accept", Type: "void", Method: true, Params: []string{"int"}},
+		"senddata":      {Name: "SendData", Type: "void", Method: true},
+		"getdata":       {Name: "GetString", Type: "void", Method: true},
+		"peekdata":      {Name: "PeekString", Type: "void", Method: true},
+		"close":         {Name: "Close", Type: "void", Method: true},
+	},
+	"ListViewItem": {
+		"key":      {Name: "Name", Type: "string"},
+		"text":     {Name: "Text", Type: "string"},
+		"selected": {Name: "Selected", Type: "bool"},
+		"checked":  {Name: "Checked", Type: "bool"},
+		"index":    {Name: "Index", Type: "int", Get: "(%s + 1)"},
+	},
+	"TreeNode": {
+		"key":      {Name: "Name", Type: "string"},
+		"text":     {Name: "Text", Type: "string"},
+		"expanded": {Name: "IsExpanded", Type: "bool"},
+		"checked":  {Name: "Checked", Type: "bool"},
+		"parent":   {Name: "Parent", Type: "TreeNode"},
+		"index":    {Name: "Index", Type: "int", Get: "(%s + 1)"},
+	},
+	"ToolStripItem": {
+		"key":     {Name: "Name", Type: "string"},
+		"caption": {Name: "Text", Type: "string"},
+		"text":    {Name: "Text", Type: "string"},
+	},
+	"ColumnHeader": {
+		"key":   {Name: "Name", Type: "string"},
+		"text":  {Name: "Text", Type: "string"},
+		"width": {Name: "Width", Type: "int"},
+	},
+	"ProgressBar": {
+		"value": {Name: "Value", Type: "int"},
+	},
+	"TrackBar": {
+		"value": {Name: "Value", Type: "int"},
+	},
+	"TabControl": {
+		"tab":          {Name: "SelectedIndex", Type: "int"},
+		"tabs":         {Name: "TabPages", Type: "TabControl.TabPageCollection", Elem: "TabPage"},
+		"selecteditem": {Name: "SelectedTab", Type: "TabPage"},
+	},
+}
+
+// defaultMembers is the VB6 default property of each .NET control type.
+var defaultMembers = map[string]string{
+	"TextBox":        "text",
+	"Label":          "caption",
+	"ComboBox":       "text",
+	"ListBox":        "text",
+	"CheckedListBox": "text",
+	"CheckBox":       "value",
+	"RadioButton":    "value",
+	"HScrollBar":     "value",
+	"VScrollBar":     "value",
+	"ProgressBar":    "value",
+	"TrackBar":       "value",
+	"RichTextBox":    "text",
+	"DateTimePicker": "value",
+	"MonthCalendar":  "value",
+	"NumericUpDown":  "value",
+	"Timer":          "enabled",
+}
+
+// shortType strips the namespace from a .NET type name.
+func shortType(typeName string) string {
+	if dot := strings.LastIndex(typeName, "."); dot != -1 {
+		return typeName[dot+1:]
+	}
+	return typeName
+}
+
+// controlMember looks up the translation of a member of a control.
+func controlMember(c *Control, name string) (member, bool) {
+	if members, ok := typeMembers[shortType(c.TypeName)]; ok {
+		if m, ok := members[name]; ok {
+			return m, true
+		}
+	}
+	m, ok := controlMembers[name]
+	return m, ok
+}
@@ -0,0 +1,318 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+// menuStripHeight is the height of a MenuStrip in pixels at 96 DPI.
+const menuStripHeight = 24
+
+// shortcutKey is a key that can be used in a menu shortcut, named as in the
+// Keys and Shortcut enums.
+type shortcutKey struct {
+	Keys     string
+	Shortcut string
+}
+
+// shortcutKeys maps the key names between braces in VB6 shortcuts.
+var shortcutKeys = map[string]shortcutKey{
+	"INSERT":    {"Insert", "Ins"},
+	"INS":       {"Insert", "Ins"},
+	"DELETE":    {"Delete", "Del"},
+	"DEL":       {"Delete", "Del"},
+	"BACKSPACE": {"Back", "Bksp"},
+	"BKSP":      {"Back", "Bksp"},
+}
+
+// parseShortcut reads a VB6 menu shortcut, such as ^S, +{F1} or %{BKSP}, and
+// returns it as Keys and as Shortcut expressions.
+func parseShortcut(s string) (string, string, bool) {
+	s = strings.TrimSpace(s)
+
+	var keys []string
+	var shortcut string
+	var ctrl, shift, alt bool
+	for len(s) > 0 && strings.ContainsRune("^+%", rune(s[0])) {
+		switch s[0] {
+		case '^':
+			ctrl = true
+		case '+':
+			shift = true
+		case '%':
+			alt = true
+		}
+		s = s[1:]
+	}
+	if ctrl {
+		keys = append(keys, "Control")
+		shortcut += "Ctrl"
+	}
+	if shift {
+		keys = append(keys, "Shift")
+		shortcut += "Shift"
+	}
+	if alt {
+		keys = append(keys, "Alt")
+		shortcut += "Alt"
+	}
+
+	var key shortcutKey
+	switch {
+	case len(s) == 1 && s[0] >= 'A' && s[0] <= 'Z':
+		key = shortcutKey{s, s}
+	case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+		name := strings.ToUpper(s[1 : len(s)-1])
+		if k, ok := shortcutKeys[name]; ok {
+			key = k
+		} else if len(name) >= 2 && name[0] == 'F' && strings.Trim(name[1:], "0123456789") == "" {
+			key = shortcutKey{name, name}
+		} else {
+			return "", "", false
+		}
+	default:
+		return "", "", false
+	}
+
+	keys = append(keys, key.Keys)
+	for i, k := range keys {
+		keys[i] = "System.Windows.Forms.Keys." + k
+	}
+	return strings.Join(keys, " | "), "System.Windows.Forms.Shortcut." + shortcut + key.Shortcut, true
+}
+
+// applyMenuProps maps the properties shared by the items of a MainMenu and a
+// MenuStrip.
+func applyMenuProps(c *vb6.Control, props map[string]string) {
+	if checked, ok := vb6.GetBool("Checked", c.Properties); ok {
+		props["Checked"] = toBool(checked)
+	}
+
+	if enabled, ok := vb6.GetBool("Enabled", c.Properties); ok {
+		props["Enabled"] = toBool(enabled)
+	}
+
+	if visible, ok := vb6.GetBool("Visible", c.Properties); ok {
+		props["Visible"] = toBool(visible)
+	}
+
+	if tag, ok := vb6.GetStr("Tag", c.Properties); ok {
+		props["Tag"] = toStr(tag)
+	}
+}
+
+// ToolStripMenuItemBuilder builds an item of a MenuStrip, a caption of - is a
+// separator.
+func ToolStripMenuItemBuilder(c *vb6.Control) *Control {
+	props := make(map[string]string)
+	propCalls := make(map[string]string)
+
+	caption, _ := vb6.GetStr("Caption", c.Properties)
+	if caption == "-" {
+		if visible, ok := vb6.GetBool("Visible", c.Properties); ok {
+			props["Visible"] = toBool(visible)
+		}
+
+		return &Control{
+			Name:      c.Name,
+			TypeName:  "System.Windows.Forms.ToolStripSeparator",
+			Resources: make(map[string]any),
+			Props:     props,
+			Children:  make([]*Control, 0),
+			MustInit:  false,
+			SkipAdd:   true,
+		}
+	}
+
+	props["Text"] = toStr(caption)
+
+	applyMenuProps(c, props)
+
+	if shortcut, ok := vb6.GetProp("Shortcut", c.Properties); ok {
+		if keys, _, ok := parseShortcut(shortcut); ok {
+			props["ShortcutKeys"] = keys
+		}
+	}
+
+	// Menus negotiated to the right, like Help, are aligned to the right
+	if negotiatePosition, _ := vb6.GetInt("NegotiatePosition", c.Properties); negotiatePosition == 3 {
+		props["Alignment"] = "System.Windows.Forms.ToolStripItemAlignment.Right"
+	}
+
+	children := buildToolStripMenuItemSlice(c.Children)
+	if len(children) > 0 {
+		childNames := make([]string, 0, len(children))
+		for _, child := range children {
+			childNames = append(childNames, fmt.Sprintf("this.%s", child.Name))
+		}
+		propCalls["DropDownItems"] = fmt.Sprintf("AddRange(%s)", toArrayOfType(childNames, "System.Windows.Forms.ToolStripItem"))
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.ToolStripMenuItem",
+		Resources: make(map[string]any),
+		Props:     props,
+		PropCalls: propCalls,
+		Children:  children,
+		MustInit:  false,
+		SkipAdd:   true,
+	}
+}
+
+func buildToolStripMenuItemSlice(controls []*vb6.Control) []*Control {
+	result := make([]*Control, 0, len(controls))
+	for _, c := range controls {
+		if c.TypeName != "VB.Menu" {
+			continue
+		}
+		control := ToolStripMenuItemBuilder(c)
+		control.Original = c
+		applyControlArray(c, control)
+		result = append(result, control)
+	}
+	return result
+}
+
+// getWindowList returns the menu item that lists the MDI child forms.
+func getWindowList(items []*Control) *Control {
+	for _, item := range items {
+		if windowList, _ := vb6.GetBool("WindowList", item.Original.Properties); windowList {
+			return item
+		}
+		if item := getWindowList(item.Children); item != nil {
+			return item
+		}
+	}
+	return nil
+}
+
+// buildContextMenus replaces the invisible top level menus of a form by a
+// ContextMenuStrip, these menus are only shown with PopupMenu.
+func buildContextMenus(f *Control) {
+	for i, c := range f.Children {
+		if c.TypeName != "System.Windows.Forms.MenuItem" {
+			continue
+		}
+		if visible, ok := vb6.GetBool("Visible", c.Original.Properties); !ok || visible {
+			continue
+		}
+
+		items := buildToolStripMenuItemSlice(c.Original.Children)
+		itemNames := make([]string, 0, len(items))
+		for _, item := range items {
+			itemNames = append(itemNames, fmt.Sprintf("this.%s", item.Name))
+		}
+
+		propCalls := make(map[string]string)
+		if len(items) > 0 {
+			propCalls["Items"] = fmt.Sprintf("AddRange(%s)", toArrayOfType(itemNames, "System.Windows.Forms.ToolStripItem"))
+		}
+
+		f.Children[i] = &Control{
+			Name:        c.Name,
+			TypeName:    "System.Windows.Forms.ContextMenuStrip",
+			Resources:   make(map[string]any),
+			Props:       make(map[string]string),
+			PropCalls:   propCalls,
+			Children:    items,
+			MustInit:    false,
+			SkipAdd:     true,
+			IsComponent: true,
+			Original:    c.Original,
+			ArrayName:   c.ArrayName,
+			ArrayIndex:  c.ArrayIndex,
+		}
+	}
+}
+
+// contextMenus returns the names of the context menus built from invisible
+// menus.
+func contextMenus(f *Control) []string {
+	names := make([]string, 0)
+	for _, c := range f.Children {
+		if c.TypeName == "System.Windows.Forms.ContextMenuStrip" && c.Original != nil && c.Original.TypeName == "VB.Menu" {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// buildMenuStrip replaces the menu items on the form by a MenuStrip. The
+// menu items are built again from the VB6 menus.
+func buildMenuStrip(f *Control) {
+	menus := make([]*vb6.Control, 0)
+	newChildren := make([]*Control, 0, len(f.Children))
+	for _, c := range f.Children {
+		if c.TypeName == "System.Windows.Forms.MenuItem" {
+			menus = append(menus, c.Original)
+		} else {
+			newChildren = append(newChildren, c)
+		}
+	}
+
+	if len(menus) == 0 {
+		return
+	}
+
+	items := buildToolStripMenuItemSlice(menus)
+	itemNames := make([]string, 0, len(items))
+	for _, item := range items {
+		itemNames = append(itemNames, fmt.Sprintf("this.%s", item.Name))
+	}
+
+	props := make(map[string]string)
+	propCalls := make(map[string]string)
+	propCalls["Items"] = fmt.Sprintf("AddRange(%s)", toArrayOfType(itemNames, "System.Windows.Forms.ToolStripItem"))
+
+	if windowList := getWindowList(items); windowList != nil {
+		props["MdiWindowListItem"] = fmt.Sprintf("this.%s", windowList.Name)
+	}
+
+	// The client area of VB6 forms starts below the menu bar, the MenuStrip
+	// is part of the client area
+	height := vb6.TwipsToPixels(menuStripHeight * 15)
+	for _, c := range newChildren {
+		offsetControl(c, height)
+	}
+	if w, h, ok := parseSize(f.Props["ClientSize"]); ok {
+		f.Props["ClientSize"] = toSize(w, h+height)
+	}
+
+	menu := &Control{
+		Name:      "menuStrip1",
+		TypeName:  "System.Windows.Forms.MenuStrip",
+		Resources: make(map[string]any),
+		Props:     props,
+		PropCalls: propCalls,
+		Children:  items,
+		MustInit:  false,
+	}
+
+	f.Props["MainMenuStrip"] = "this.menuStrip1"
+
+	// Added last so it is docked before the other controls
+	f.Children = append(newChildren, menu)
+}
+
+// offsetControl moves a control placed on the form down by dy pixels. Docked
+// controls and components are left alone.
+func offsetControl(c *Control, dy int) {
+	if c.SkipAdd {
+		return
+	}
+	if _, ok := c.Props["Dock"]; ok {
+		return
+	}
+	if x, y, ok := parsePoint(c.Props["Location"]); ok {
+		c.Props["Location"] = toPoint(x, y+dy)
+	}
+	for _, key := range []string{"Y1", "Y2"} {
+		var y int
+		if _, err := fmt.Sscanf(c.Props[key], "%d", &y); err == nil {
+			c.Props[key] = toInt(y + dy)
+		}
+	}
+}
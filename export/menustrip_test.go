@@ -0,0 +1,218 @@
+package export
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseShortcut(t *testing.T) {
+	tests := []struct {
+		shortcut string
+		keys     string
+		want     string
+	}{
+		{"^S", "System.Windows.Forms.Keys.Control | System.Windows.Forms.Keys.S", "System.Windows.Forms.Shortcut.CtrlS"},
+		{"+{F1}", "System.Windows.Forms.Keys.Shift | System.Windows.Forms.Keys.F1", "System.Windows.Forms.Shortcut.ShiftF1"},
+		{"^+%A", "System.Windows.Forms.Keys.Control | System.Windows.Forms.Keys.Shift | System.Windows.Forms.Keys.Alt | System.Windows.Forms.Keys.A", "System.Windows.Forms.Shortcut.CtrlShiftAltA"},
+		{"%{BKSP}", "System.Windows.Forms.Keys.Alt | System.Windows.Forms.Keys.Back", "System.Windows.Forms.Shortcut.AltBksp"},
+		{"{DEL}", "System.Windows.Forms.Keys.Delete", "System.Windows.Forms.Shortcut.Del"},
+		{"{F12}", "System.Windows.Forms.Keys.F12", "System.Windows.Forms.Shortcut.F12"},
+		{"^{HOME}", "", ""},
+		{"^1", "", ""},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		keys, shortcut, ok := parseShortcut(test.shortcut)
+		if ok != (test.keys != "") || keys != test.keys || shortcut != test.want {
+			t.Errorf("parseShortcut(%q) = %q, %q, %v, want %q, %q", test.shortcut, keys, shortcut, ok, test.keys, test.want)
+		}
+	}
+}
+
+// testMenuForm has a menu with a shortcut, a separator, a control array, a
+// window list and a menu on the right.
+const testMenuForm = `VERSION 5.00
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin VB.TextBox txtName
+      Height          =   285
+      Left            =   120
+      TabIndex        =   0
+      Top             =   120
+      Width           =   1000
+   End
+   Begin VB.Menu mnuFile
+      Caption         =   "&File"
+      Begin VB.Menu mnuFileSave
+         Caption         =   "&Save"
+         Shortcut        =   ^S
+      End
+      Begin VB.Menu mnuFileSep
+         Caption         =   "-"
+      End
+      Begin VB.Menu mnuFileRecent
+         Caption         =   "Recent"
+         Checked         =   -1  'True
+         Enabled         =   0   'False
+         Index           =   0
+      End
+   End
+   Begin VB.Menu mnuWindow
+      Caption         =   "&Window"
+      WindowList      =   -1  'True
+   End
+   Begin VB.Menu mnuHelp
+      Caption         =   "&Help"
+      NegotiatePosition=   3  'Right
+      Begin VB.Menu mnuHelpAbout
+         Caption         =   "&About"
+         Shortcut        =   {F1}
+      End
+   End
+End
+`
+
+func TestMenuStrip(t *testing.T) {
+	form, _ := buildTestForm(t, testMenuForm)
+	buildMenu(&ProjectInfo{MenuStrip: true}, form)
+
+	// The MenuStrip is added last so it is docked before the other controls
+	names := make([]string, 0, len(form.Children))
+	for _, c := range form.Children {
+		names = append(names, c.Name)
+	}
+	if want := []string{"txtName", "menuStrip1"}; !slices.Equal(names, want) {
+		t.Fatalf("got children %v, want %v", names, want)
+	}
+	checkProps(t, form, map[string]string{
+		"MainMenuStrip": "this.menuStrip1",
+		"Menu":          "",
+		"ClientSize":    "new System.Drawing.Size(266, 224)",
+	})
+
+	// The controls are moved below the menu
+	checkProps(t, testControl(t, form, "txtName"), map[string]string{
+		"Location": "new System.Drawing.Point(8, 32)",
+	})
+
+	menu := testControl(t, form, "menuStrip1")
+	checkProps(t, menu, map[string]string{"MdiWindowListItem": "this.mnuWindow"})
+
+	tests := []struct {
+		name     string
+		typeName string
+		props    map[string]string
+	}{
+		{"mnuFileSave", "System.Windows.Forms.ToolStripMenuItem", map[string]string{
+			"Text":         `"&Save"`,
+			"ShortcutKeys": "System.Windows.Forms.Keys.Control | System.Windows.Forms.Keys.S",
+		}},
+		{"mnuFileSep", "System.Windows.Forms.ToolStripSeparator", map[string]string{
+			"Text": "",
+		}},
+		{"mnuFileRecent_0", "System.Windows.Forms.ToolStripMenuItem", map[string]string{
+			"Checked": "true",
+			"Enabled": "false",
+		}},
+		{"mnuHelp", "System.Windows.Forms.ToolStripMenuItem", map[string]string{
+			"Alignment": "System.Windows.Forms.ToolStripItemAlignment.Right",
+		}},
+		{"mnuHelpAbout", "System.Windows.Forms.ToolStripMenuItem", map[string]string{
+			"ShortcutKeys": "System.Windows.Forms.Keys.F1",
+		}},
+	}
+	for _, test := range tests {
+		c := testControl(t, menu, test.name)
+		if c.TypeName != test.typeName {
+			t.Errorf("%s is a %s, want %s", test.name, c.TypeName, test.typeName)
+		}
+		checkProps(t, c, test.props)
+	}
+	if recent := testControl(t, menu, "mnuFileRecent_0"); recent.ArrayName != "mnuFileRecent" {
+		t.Errorf("mnuFileRecent_0 is in array %q, want mnuFileRecent", recent.ArrayName)
+	}
+}
+
+func TestMainMenu(t *testing.T) {
+	form, _ := buildTestForm(t, testMenuForm)
+	buildMenu(&ProjectInfo{}, form)
+
+	menu := testControl(t, form, "mainMenu1")
+	if !menu.IsComponent {
+		t.Error("mainMenu1 is not a component")
+	}
+	checkProps(t, form, map[string]string{
+		"Menu":          "this.mainMenu1",
+		"MainMenuStrip": "",
+		"ClientSize":    "new System.Drawing.Size(266, 200)",
+	})
+	checkProps(t, testControl(t, form, "txtName"), map[string]string{
+		"Location": "new System.Drawing.Point(8, 8)",
+	})
+	checkProps(t, testControl(t, menu, "mnuFileSave"), map[string]string{
+		"Shortcut": "System.Windows.Forms.Shortcut.CtrlS",
+		"Index":    "0",
+	})
+	checkProps(t, testControl(t, menu, "mnuFileSep"), map[string]string{
+		"Text":  `"-"`,
+		"Index": "1",
+	})
+	checkProps(t, testControl(t, menu, "mnuWindow"), map[string]string{
+		"MdiList": "true",
+	})
+}
+
+func TestContextMenus(t *testing.T) {
+	for _, menuStrip := range []bool{false, true} {
+		form, _ := buildTestForm(t, `VERSION 5.00
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin VB.Menu mnuFile
+      Caption         =   "&File"
+   End
+   Begin VB.Menu mnuPopup
+      Caption         =   "Popup"
+      Visible         =   0   'False
+      Begin VB.Menu mnuPopupCopy
+         Caption         =   "&Copy"
+         Shortcut        =   ^C
+      End
+      Begin VB.Menu mnuPopupSep
+         Caption         =   "-"
+      End
+   End
+End
+`)
+		buildMenu(&ProjectInfo{MenuStrip: menuStrip}, form)
+
+		// Invisible menus are left out of the menu bar
+		popup := testControl(t, form, "mnuPopup")
+		if popup.TypeName != "System.Windows.Forms.ContextMenuStrip" || !popup.IsComponent {
+			t.Errorf("mnuPopup is a %s, want a ContextMenuStrip component", popup.TypeName)
+		}
+		if !slices.Contains(form.Children, popup) {
+			t.Error("mnuPopup is not a child of the form")
+		}
+		bar := form.Children[len(form.Children)-1]
+		if len(bar.Children) != 1 || bar.Children[0].Name != "mnuFile" {
+			t.Errorf("%s has %d items, want only mnuFile", bar.Name, len(bar.Children))
+		}
+
+		checkProps(t, testControl(t, popup, "mnuPopupCopy"), map[string]string{
+			"Text":         `"&Copy"`,
+			"ShortcutKeys": "System.Windows.Forms.Keys.Control | System.Windows.Forms.Keys.C",
+		})
+		if sep := testControl(t, popup, "mnuPopupSep"); sep.TypeName != "System.Windows.Forms.ToolStripSeparator" {
+			t.Errorf("mnuPopupSep is a %s, want a ToolStripSeparator", sep.TypeName)
+		}
+
+		if names := contextMenus(form); !slices.Equal(names, []string{"mnuPopup"}) {
+			t.Errorf("got context menus %v, want mnuPopup", names)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/guthius/vb6conv/vb6"
+	"github.com/guthius/vb6conv/vb6/ast"
+)
+
+// ExportModules converts standard modules into static classes. The public
+// members of the modules are made available to the forms exported afterwards.
+func ExportModules(p *ProjectInfo, modules []*vb6.Module) {
+	if p.symbols == nil {
+		p.symbols = make(map[string]*symbol)
+	}
+
+	translators := make([]*translator, 0, len(modules))
+	reports := make([]*FileReport, 0, len(modules))
+	for _, m := range modules {
+		code, err := ast.Parse(m.Script)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", m.Filename, err)
+		}
+
+		t := newTranslator(code, nil, m.Name)
+		t.static = true
+		t.shared = p.symbols
+		t.declareTypes()
+		t.shareTypes(p)
+		translators = append(translators, t)
+		reports = append(reports, newFileReport(m.Name, "module", m.Filename))
+	}
+
+	// The types of all modules are known before the variables and procedures
+	// using them are declared
+	for _, t := range translators {
+		t.sharedTypes = p.types
+		t.declareFile()
+		t.shareSymbols(p.symbols)
+	}
+
+	for i, t := range translators {
+		if err := exportModule(p, t); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", t.name, err)
+		}
+		reports[i].Untranslated = t.todos
+		p.addReport(reports[i])
+	}
+}
+
+func exportModule(p *ProjectInfo, t *translator) error {
+	filename := filepath.Join(p.Output, t.name+".cs")
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := NewExportWriter(file)
+	writer.Write("using System;")
+	writer.Write("using System.Collections.Generic;")
+	writer.Write("using System.Drawing;")
+	writer.Write("using System.Linq;")
+	writer.Write("using System.Text;")
+	writer.Write("using System.Windows.Forms;")
+	writer.Write("using Microsoft.VisualBasic;")
+	writer.Write("using Microsoft.VisualBasic.CompilerServices;")
+	writer.Writeln()
+	writer.Writef("namespace %s;", p.Namespace)
+	writer.Writeln()
+	t.w = writer
+	t.writeTypes()
+	writer.Writef("internal static class %s", t.name)
+	writer.Write("{")
+	writer.WriteIndent(func() {
+		t.comment = true
+		t.writeDecls(t.file.Decls)
+	})
+	writer.Write("}")
+
+	return nil
+}
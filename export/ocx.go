@@ -0,0 +1,436 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6"
+	"github.com/guthius/vb6conv/vb6/frx"
+)
+
+func RichTextBoxBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	// The RTF text takes precedence over the plain text
+	if locator, ok := vb6.GetProp("TextRTF", c.Properties); ok && strings.HasPrefix(locator, "$") {
+		rtf, err := frx.LoadBlob(c.Form.Folder, locator)
+		if err != nil {
+			ctx.resourceFailed(c, "TextRTF", locator, err)
+		} else {
+			props["Rtf"] = toStr(strings.TrimRight(string(rtf), "\x00"))
+		}
+	} else if rtf, ok := vb6.GetStr("TextRTF", c.Properties); ok {
+		props["Rtf"] = toStr(rtf)
+	}
+	if _, ok := props["Rtf"]; !ok {
+		if text, ok := vb6.GetStr("Text", c.Properties); ok {
+			props["Text"] = toStr(text)
+		}
+	}
+
+	if multiLine, ok := vb6.GetBool("MultiLine", c.Properties); ok {
+		props["Multiline"] = toBool(multiLine)
+	}
+
+	scrollBars, _ := vb6.GetInt("ScrollBars", c.Properties)
+	switch scrollBars {
+	case 0:
+		props["ScrollBars"] = "System.Windows.Forms.RichTextBoxScrollBars.None"
+	case 1:
+		props["ScrollBars"] = "System.Windows.Forms.RichTextBoxScrollBars.Horizontal"
+	case 2:
+		props["ScrollBars"] = "System.Windows.Forms.RichTextBoxScrollBars.Vertical"
+	case 3:
+		props["ScrollBars"] = "System.Windows.Forms.RichTextBoxScrollBars.Both"
+	}
+
+	if locked, ok := vb6.GetBool("Locked", c.Properties); ok {
+		props["ReadOnly"] = toBool(locked)
+	}
+
+	if maxLength, ok := vb6.GetInt("MaxLength", c.Properties); ok && maxLength > 0 {
+		props["MaxLength"] = toInt(maxLength)
+	}
+
+	if hideSelection, ok := vb6.GetBool("HideSelection", c.Properties); ok {
+		props["HideSelection"] = toBool(hideSelection)
+	}
+
+	if borderStyle, ok := vb6.GetInt("BorderStyle", c.Properties); ok && borderStyle == 0 {
+		props["BorderStyle"] = "System.Windows.Forms.BorderStyle.None"
+	}
+
+	if rightMargin, ok := vb6.GetTwips("RightMargin", c.Properties); ok && rightMargin > 0 {
+		props["RightMargin"] = toInt(rightMargin)
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.RichTextBox",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// sstabOffset is the distance controls on the hidden tabs of an SSTab are
+// moved to the left, to keep them out of sight.
+const sstabOffset = 75000
+
+// moveControl returns a copy of c with its position moved by dx and dy twips,
+// converted to the units of its container.
+func moveControl(c *vb6.Control, dx int, dy int) *vb6.Control {
+	props := make(vb6.PropertyMap, len(c.Properties))
+	for k, v := range c.Properties {
+		props[k] = v
+	}
+	scale := vb6.GetScale(c.Parent)
+	move := func(key string, d float64) {
+		if v, ok := vb6.GetFloat64(key, props); ok {
+			props[key] = &vb6.Property{Name: key, Value: strconv.FormatFloat(v+d, 'f', -1, 64), Properties: make(vb6.PropertyMap)}
+		}
+	}
+	if c.TypeName == "VB.Line" {
+		move("X1", float64(dx)/scale.X)
+		move("X2", float64(dx)/scale.X)
+		move("Y1", float64(dy)/scale.Y)
+		move("Y2", float64(dy)/scale.Y)
+	} else {
+		move("Left", float64(dx)/scale.X)
+		move("Top", float64(dy)/scale.Y)
+	}
+	moved := *c
+	moved.Properties = props
+	return &moved
+}
+
+// SSTabBuilder builds a TabControl. The SSTab keeps the controls of all tabs
+// as its own children, moving the controls of the hidden tabs off-screen to
+// the left; they are moved back and placed on the TabPage they belong to.
+func SSTabBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+	propCalls := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "BackColor")
+
+	tabs, ok := vb6.GetInt("Tabs", c.Properties)
+	if !ok {
+		tabs = 3
+	}
+	current, _ := vb6.GetInt("Tab", c.Properties)
+	if current >= 0 && current < tabs {
+		props["SelectedIndex"] = toInt(current)
+	}
+
+	if tabsPerRow, ok := vb6.GetInt("TabsPerRow", c.Properties); ok && tabs > tabsPerRow {
+		props["Multiline"] = toBool(true)
+	}
+
+	tabHeight, ok := vb6.GetInt("TabHeight", c.Properties)
+	if !ok {
+		tabHeight = 300
+	}
+
+	// The page starts after the tabs, the controls are relative to the SSTab
+	var dx, dy int
+	orientation, _ := vb6.GetInt("TabOrientation", c.Properties)
+	switch orientation {
+	case 0:
+		dy = -tabHeight
+	case 1:
+		props["Alignment"] = "System.Windows.Forms.TabAlignment.Bottom"
+	case 2:
+		props["Alignment"] = "System.Windows.Forms.TabAlignment.Left"
+		dx = -tabHeight
+	case 3:
+		props["Alignment"] = "System.Windows.Forms.TabAlignment.Right"
+	}
+
+	// Tab(n).Control(i) names the controls on each tab, control array
+	// elements are named Name(Index)
+	owner := make(map[string]int)
+	for n := 0; n < tabs; n++ {
+		count, _ := vb6.GetInt(fmt.Sprintf("Tab(%d).ControlCount", n), c.Properties)
+		for i := 0; i < count; i++ {
+			if name, ok := vb6.GetStr(fmt.Sprintf("Tab(%d).Control(%d)", n, i), c.Properties); ok {
+				owner[strings.ToLower(name)] = n
+			}
+		}
+	}
+
+	pages := make([][]*vb6.Control, tabs)
+	for _, child := range c.Children {
+		name := child.Name
+		if index, ok := vb6.GetInt("Index", child.Properties); ok {
+			name = fmt.Sprintf("%s(%d)", child.Name, index)
+		}
+		n, ok := owner[strings.ToLower(name)]
+		if !ok || n >= tabs {
+			n = current
+		}
+		if n < 0 || n >= tabs {
+			continue
+		}
+
+		offset := 0
+		left, _ := vb6.GetFloat64("Left", child.Properties)
+		if child.TypeName == "VB.Line" {
+			left, _ = vb6.GetFloat64("X1", child.Properties)
+		}
+		if left*vb6.GetScale(c).X < -sstabOffset/2 {
+			offset = sstabOffset
+		}
+		pages[n] = append(pages[n], moveControl(child, dx+offset, dy))
+	}
+
+	children := make([]*Control, 0, tabs)
+	childNames := make([]string, 0, tabs)
+	for n := 0; n < tabs; n++ {
+		pageProps := make(map[string]string)
+		if caption, ok := vb6.GetStr(fmt.Sprintf("TabCaption(%d)", n), c.Properties); ok {
+			pageProps["Text"] = toStr(caption)
+		}
+		if backColor, ok := vb6.GetColor("BackColor", c.Properties); ok {
+			pageProps["BackColor"] = toColor(backColor)
+		}
+		if enabled, ok := vb6.GetBool(fmt.Sprintf("TabEnabled(%d)", n), c.Properties); ok && !enabled {
+			pageProps["Enabled"] = toBool(false)
+		}
+		page := &Control{
+			Name:      fmt.Sprintf("%s_Tab%d", controlName(c), n),
+			TypeName:  "System.Windows.Forms.TabPage",
+			Resources: make(map[string]any),
+			Props:     pageProps,
+			Children:  buildControlSlice(ctx, pages[n]),
+			MustInit:  false,
+			SkipAdd:   true,
+		}
+		children = append(children, page)
+		childNames = append(childNames, fmt.Sprintf("this.%s", page.Name))
+	}
+	if len(children) > 0 {
+		propCalls["TabPages"] = fmt.Sprintf("AddRange(%s)", toArrayOfType(childNames, "System.Windows.Forms.TabPage"))
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.TabControl",
+		Resources: make(map[string]any),
+		Props:     props,
+		PropCalls: propCalls,
+		Children:  children,
+		MustInit:  false,
+	}
+}
+
+func DTPickerBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	// The format is stored in the low byte, together with other flags
+	if format, ok := vb6.GetInt("Format", c.Properties); ok {
+		switch format & 0xFF {
+		case 0:
+			props["Format"] = "System.Windows.Forms.DateTimePickerFormat.Long"
+		case 1:
+			props["Format"] = "System.Windows.Forms.DateTimePickerFormat.Short"
+		case 2:
+			props["Format"] = "System.Windows.Forms.DateTimePickerFormat.Time"
+		case 3:
+			props["Format"] = "System.Windows.Forms.DateTimePickerFormat.Custom"
+		}
+	}
+
+	if customFormat, ok := vb6.GetStr("CustomFormat", c.Properties); ok {
+		props["CustomFormat"] = toStr(customFormat)
+	}
+
+	if checkBox, ok := vb6.GetBool("CheckBox", c.Properties); ok {
+		props["ShowCheckBox"] = toBool(checkBox)
+	}
+
+	if upDown, ok := vb6.GetBool("UpDown", c.Properties); ok {
+		props["ShowUpDown"] = toBool(upDown)
+	}
+
+	applyDateRange(c, props)
+
+	if currentDate, ok := vb6.GetFloat64("CurrentDate", c.Properties); ok {
+		props["Value"] = toDate(currentDate)
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.DateTimePicker",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// applyDateRange maps the MinDate and MaxDate properties.
+func applyDateRange(c *vb6.Control, props map[string]string) {
+	if minDate, ok := vb6.GetFloat64("MinDate", c.Properties); ok {
+		props["MinDate"] = toDate(minDate)
+	}
+
+	if maxDate, ok := vb6.GetFloat64("MaxDate", c.Properties); ok {
+		props["MaxDate"] = toDate(maxDate)
+	}
+}
+
+func MonthViewBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "Size")
+
+	if showToday, ok := vb6.GetBool("ShowToday", c.Properties); ok {
+		props["ShowToday"] = toBool(showToday)
+	}
+
+	if showWeekNumbers, ok := vb6.GetBool("ShowWeekNumbers", c.Properties); ok {
+		props["ShowWeekNumbers"] = toBool(showWeekNumbers)
+	}
+
+	if multiSelect, _ := vb6.GetBool("MultiSelect", c.Properties); multiSelect {
+		if maxSelCount, ok := vb6.GetInt("MaxSelCount", c.Properties); ok {
+			props["MaxSelectionCount"] = toInt(maxSelCount)
+		} else {
+			props["MaxSelectionCount"] = toInt(7)
+		}
+	} else {
+		props["MaxSelectionCount"] = toInt(1)
+	}
+
+	// StartOfWeek is 1 for Sunday to 7 for Saturday, stored in the low byte
+	if startOfWeek, ok := vb6.GetInt("StartOfWeek", c.Properties); ok {
+		days := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+		if day := startOfWeek & 0xFF; day >= 1 && day <= 7 {
+			props["FirstDayOfWeek"] = fmt.Sprintf("System.Windows.Forms.Day.%s", days[day-1])
+		}
+	}
+
+	columns, ok := vb6.GetInt("MonthColumns", c.Properties)
+	if !ok {
+		columns = 1
+	}
+	rows, ok := vb6.GetInt("MonthRows", c.Properties)
+	if !ok {
+		rows = 1
+	}
+	if columns != 1 || rows != 1 {
+		props["CalendarDimensions"] = toSize(columns, rows)
+	}
+
+	applyDateRange(c, props)
+
+	if currentDate, ok := vb6.GetFloat64("CurrentDate", c.Properties); ok {
+		props["SelectionStart"] = toDate(currentDate)
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.MonthCalendar",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  false,
+	}
+}
+
+// UpDownBuilder builds a NumericUpDown in place of the UpDown control. The
+// NumericUpDown shows its own value instead of updating a buddy control.
+func UpDownBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+
+	if increment, ok := vb6.GetInt("Increment", c.Properties); ok {
+		props["Increment"] = toInt(increment)
+	}
+
+	// The range is set before the value, the value must be within the range
+	calls := make([]string, 0, 3)
+	if min, ok := vb6.GetInt("Min", c.Properties); ok {
+		calls = append(calls, "Minimum = "+toInt(min))
+	}
+
+	max, ok := vb6.GetInt("Max", c.Properties)
+	if !ok {
+		max = 10
+	}
+	calls = append(calls, "Maximum = "+toInt(max))
+
+	if value, ok := vb6.GetInt("Value", c.Properties); ok {
+		calls = append(calls, "Value = "+toInt(value))
+	}
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.NumericUpDown",
+		Resources: make(map[string]any),
+		Props:     props,
+		Calls:     calls,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  true,
+	}
+}
+
+// AnimationBuilder builds an empty PictureBox in place of the Animation
+// control, AVI clips are not converted.
+func AnimationBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	applyDefaultPropsForControl(c, props)
+	delete(props, "TabIndex")
+
+	if backStyle, ok := vb6.GetInt("BackStyle", c.Properties); ok && backStyle == 0 {
+		props["BackColor"] = "System.Drawing.Color.Transparent"
+	}
+
+	props["SizeMode"] = "System.Windows.Forms.PictureBoxSizeMode.CenterImage"
+	props["TabStop"] = "false"
+
+	return &Control{
+		Name:      c.Name,
+		TypeName:  "System.Windows.Forms.PictureBox",
+		Resources: make(map[string]any),
+		Props:     props,
+		Children:  buildControlSlice(ctx, c.Children),
+		MustInit:  true,
+	}
+}
+
+func WinsockBuilder(ctx *BuildContext, c *vb6.Control) *Control {
+	props := make(map[string]string)
+
+	if remoteHost, ok := vb6.GetStr("RemoteHost", c.Properties); ok {
+		props["RemoteHost"] = toStr(remoteHost)
+	}
+
+	for _, name := range []string{"RemotePort", "LocalPort", "Protocol"} {
+		if v, ok := vb6.GetInt(name, c.Properties); ok {
+			props[name] = toInt(v)
+		}
+	}
+
+	return &Control{
+		Name:        c.Name,
+		TypeName:    "WinsockControl",
+		Resources:   make(map[string]any),
+		Props:       props,
+		Children:    make([]*Control, 0),
+		MustInit:    false,
+		SkipAdd:     true,
+		SkipName:    true,
+		IsComponent: true,
+	}
+}
@@ -0,0 +1,212 @@
+package export
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOCXControls(t *testing.T) {
+	form, _ := buildTestForm(t, `VERSION 5.00
+Object = "{3B7C8863-D78F-101B-B9B5-04021C009402}#1.2#0"; "RICHTX32.OCX"
+Object = "{BDC217C8-ED16-11CD-956C-0000C04E4C0A}#1.1#0"; "TABCTL32.OCX"
+Object = "{86CF1D34-0C5F-11D2-A9FC-0000F8754DA1}#2.0#0"; "MSCOMCT2.OCX"
+Object = "{248DD890-BB45-11CF-9ABC-0080C7E7B78D}#1.0#0"; "MSWINSCK.OCX"
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   6000
+   ClientWidth     =   6000
+   Begin TabDlg.SSTab tabMain
+      Height          =   3000
+      Left            =   120
+      TabIndex        =   0
+      Top             =   120
+      Width           =   4000
+      _ExtentX        =   7056
+      _ExtentY        =   5292
+      _Version        =   393216
+      Tabs            =   2
+      TabHeight       =   420
+      TabCaption(0)   =   "General"
+      TabPicture(0)   =   "frmTest.frx":0000
+      Tab(0).ControlEnabled=   -1  'True
+      Tab(0).Control(0)=   "txtName"
+      Tab(0).ControlCount=   1
+      TabCaption(1)   =   "Advanced"
+      TabPicture(1)   =   "frmTest.frx":001C
+      Tab(1).ControlEnabled=   0   'False
+      Tab(1).Control(0)=   "chkDebug"
+      Tab(1).ControlCount=   1
+      TabEnabled(1)   =   0   'False
+      Begin VB.CheckBox chkDebug
+         Caption         =   "Debug"
+         Height          =   255
+         Left            =   -74760
+         TabIndex        =   2
+         Top             =   720
+         Width           =   1500
+      End
+      Begin VB.TextBox txtName
+         Height          =   285
+         Left            =   240
+         TabIndex        =   1
+         Top             =   720
+         Width           =   1500
+      End
+   End
+   Begin RichTextLib.RichTextBox rtfNotes
+      Height          =   1000
+      Left            =   120
+      TabIndex        =   3
+      Top             =   3240
+      Width           =   3000
+      _ExtentX        =   5292
+      _ExtentY        =   1764
+      _Version        =   393217
+      ScrollBars      =   2
+      Locked          =   -1  'True
+      TextRTF         =   "{\rtf1 Hello}"
+   End
+   Begin MSComCtl2.DTPicker dtpDate
+      Height          =   315
+      Left            =   120
+      TabIndex        =   4
+      Top             =   4320
+      Width           =   2000
+      _ExtentX        =   3528
+      _ExtentY        =   556
+      _Version        =   393216
+      CustomFormat    =   "yyyy-MM-dd"
+      Format          =   61734915
+      CurrentDate     =   36526
+      CheckBox        =   -1  'True
+   End
+   Begin MSComCtl2.UpDown updCount
+      Height          =   285
+      Left            =   2200
+      TabIndex        =   5
+      Top             =   4320
+      Width           =   255
+      _ExtentX        =   450
+      _ExtentY        =   503
+      _Version        =   393216
+      Value           =   5
+      Max             =   20
+      Min             =   1
+   End
+   Begin MSComCtl2.MonthView mvwCal
+      Height          =   2370
+      Left            =   4200
+      TabIndex        =   6
+      Top             =   120
+      Width           =   2700
+      _ExtentX        =   4763
+      _ExtentY        =   4180
+      _Version        =   393216
+      MultiSelect     =   -1  'True
+      StartOfWeek     =   61734914
+      MonthColumns    =   2
+   End
+   Begin MSComCtl2.Animation aniBusy
+      Height          =   500
+      Left            =   120
+      TabIndex        =   7
+      Top             =   4800
+      Width           =   500
+      _ExtentX        =   882
+      _ExtentY        =   882
+      _Version        =   393216
+      BackStyle       =   0
+   End
+   Begin MSWinsockLib.Winsock sckClient
+      Left            =   720
+      Top             =   4800
+      _ExtentX        =   741
+      _ExtentY        =   741
+      _Version        =   393216
+      RemoteHost      =   "localhost"
+      RemotePort      =   8080
+   End
+End
+`)
+
+	tests := []struct {
+		name     string
+		typeName string
+		props    map[string]string
+	}{
+		{"tabMain", "System.Windows.Forms.TabControl", map[string]string{
+			"SelectedIndex": "0",
+			"Alignment":     "",
+		}},
+		{"tabMain_Tab0", "System.Windows.Forms.TabPage", map[string]string{
+			"Text":    `"General"`,
+			"Enabled": "",
+		}},
+		{"tabMain_Tab1", "System.Windows.Forms.TabPage", map[string]string{
+			"Text":    `"Advanced"`,
+			"Enabled": "false",
+		}},
+		{"rtfNotes", "System.Windows.Forms.RichTextBox", map[string]string{
+			"Rtf":        `"{\\rtf1 Hello}"`,
+			"ScrollBars": "System.Windows.Forms.RichTextBoxScrollBars.Vertical",
+			"ReadOnly":   "true",
+			"Text":       "",
+		}},
+		{"dtpDate", "System.Windows.Forms.DateTimePicker", map[string]string{
+			"Format":       "System.Windows.Forms.DateTimePickerFormat.Custom",
+			"CustomFormat": `"yyyy-MM-dd"`,
+			"ShowCheckBox": "true",
+			"Value":        "new System.DateTime(2000, 1, 1, 0, 0, 0, 0)",
+		}},
+		{"updCount", "System.Windows.Forms.NumericUpDown", nil},
+		{"mvwCal", "System.Windows.Forms.MonthCalendar", map[string]string{
+			"MaxSelectionCount":  "7",
+			"FirstDayOfWeek":     "System.Windows.Forms.Day.Monday",
+			"CalendarDimensions": "new System.Drawing.Size(2, 1)",
+			"Size":               "",
+		}},
+		{"aniBusy", "System.Windows.Forms.PictureBox", map[string]string{
+			"BackColor": "System.Drawing.Color.Transparent",
+			"TabStop":   "false",
+			"TabIndex":  "",
+		}},
+		{"sckClient", "WinsockControl", map[string]string{
+			"RemoteHost": `"localhost"`,
+			"RemotePort": "8080",
+			"Location":   "",
+		}},
+	}
+	for _, test := range tests {
+		c := testControl(t, form, test.name)
+		if c.TypeName != test.typeName {
+			t.Errorf("%s is a %s, want %s", test.name, c.TypeName, test.typeName)
+		}
+		checkProps(t, c, test.props)
+	}
+
+	// The controls are placed on the page of their tab, below the tabs. The
+	// controls of hidden tabs are moved back from the left.
+	for _, test := range []struct{ page, name string }{
+		{"tabMain_Tab0", "txtName"},
+		{"tabMain_Tab1", "chkDebug"},
+	} {
+		page := testControl(t, form, test.page)
+		if len(page.Children) != 1 || page.Children[0].Name != test.name {
+			t.Errorf("%s is not the only control on %s", test.name, test.page)
+			continue
+		}
+		checkProps(t, page.Children[0], map[string]string{
+			"Location": "new System.Drawing.Point(16, 20)",
+		})
+	}
+
+	calls := testControl(t, form, "updCount").Calls
+	want := []string{"Minimum = 1", "Maximum = 20", "Value = 5"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	if sck := testControl(t, form, "sckClient"); !sck.IsComponent {
+		t.Error("sckClient is not a component")
+	}
+}
@@ -0,0 +1,276 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6"
+)
+
+// FileReport lists what was converted from a form, module or class and the
+// parts that need manual follow-up.
+type FileReport struct {
+	Name            string          `json:"name"`
+	Kind            string          `json:"kind"`
+	Filename        string          `json:"file"`
+	Controls        []ControlReport `json:"controls,omitempty"`
+	FailedResources []ResourceIssue `json:"failedResources,omitempty"`
+	ContextMenus    []string        `json:"contextMenus,omitempty"` // Invisible menus converted to a ContextMenuStrip
+	Untranslated    []CodeIssue     `json:"untranslated,omitempty"`
+	Totals          ReportTotals    `json:"totals"`
+}
+
+// ControlReport describes how a VB6 control was converted.
+type ControlReport struct {
+	Name              string   `json:"name"`
+	TypeName          string   `json:"type"`
+	MappedType        string   `json:"mappedType,omitempty"` // Empty when the control is unsupported
+	Supported         bool     `json:"supported"`
+	IgnoredProperties []string `json:"ignoredProperties,omitempty"`
+}
+
+// ResourceIssue is a resource of a control that could not be loaded.
+type ResourceIssue struct {
+	Control  string `json:"control"`
+	Property string `json:"property"`
+	Resource string `json:"resource"`
+	Error    string `json:"error"`
+}
+
+// CodeIssue is a statement or declaration that could not be translated.
+type CodeIssue struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+type ReportTotals struct {
+	Files             int `json:"files,omitempty"`
+	Controls          int `json:"controls"`
+	Unsupported       int `json:"unsupported"`
+	IgnoredProperties int `json:"ignoredProperties"`
+	FailedResources   int `json:"failedResources"`
+	Untranslated      int `json:"untranslated"`
+}
+
+// ProjectReport is the report of all the files of a project.
+type ProjectReport struct {
+	Project string        `json:"project"`
+	Files   []*FileReport `json:"files"`
+	Totals  ReportTotals  `json:"totals"`
+}
+
+// resourceFailed reports a resource of a control that could not be loaded.
+func (ctx *BuildContext) resourceFailed(c *vb6.Control, property string, locator string, err error) {
+	ctx.failedResources = append(ctx.failedResources, ResourceIssue{
+		Control:  controlName(c),
+		Property: property,
+		Resource: locator,
+		Error:    err.Error(),
+	})
+}
+
+func newFileReport(name string, kind string, filename string) *FileReport {
+	return &FileReport{
+		Name:     name,
+		Kind:     kind,
+		Filename: filepath.Base(filename),
+	}
+}
+
+// addControls adds the controls that were built from VB6 controls, the
+// controls generated by the builders are left out.
+func (r *FileReport) addControls(f *Control) {
+	if f.Original != nil {
+		control := ControlReport{
+			Name:      controlName(f.Original),
+			TypeName:  f.Original.TypeName,
+			Supported: !f.Unsupported,
+		}
+		if !f.Unsupported {
+			control.MappedType = f.TypeName
+			control.IgnoredProperties = ignoredProperties(f.Original)
+		}
+		r.Controls = append(r.Controls, control)
+	}
+	for _, c := range f.Children {
+		r.addControls(c)
+	}
+}
+
+// ignoredProperties returns the properties of a control that were never read.
+// Properties starting with an underscore hold the internal state of ActiveX
+// controls and are left out.
+func ignoredProperties(c *vb6.Control) []string {
+	ignored := make([]string, 0)
+	for _, name := range vb6.Unused(c.Properties) {
+		if !strings.HasPrefix(name, "_") {
+			ignored = append(ignored, name)
+		}
+	}
+	return ignored
+}
+
+func (r *FileReport) unsupported() []ControlReport {
+	controls := make([]ControlReport, 0)
+	for _, c := range r.Controls {
+		if !c.Supported {
+			controls = append(controls, c)
+		}
+	}
+	return controls
+}
+
+func (r *FileReport) updateTotals() {
+	r.Totals = ReportTotals{
+		Controls:        len(r.Controls),
+		Unsupported:     len(r.unsupported()),
+		FailedResources: len(r.FailedResources),
+		Untranslated:    len(r.Untranslated),
+	}
+	for _, c := range r.Controls {
+		r.Totals.IgnoredProperties += len(c.IgnoredProperties)
+	}
+}
+
+func (r *FileReport) hasIssues() bool {
+	t := r.Totals
+	return t.Unsupported+t.IgnoredProperties+t.FailedResources+t.Untranslated > 0
+}
+
+func (t *ReportTotals) add(o ReportTotals) {
+	t.Files++
+	t.Controls += o.Controls
+	t.Unsupported += o.Unsupported
+	t.IgnoredProperties += o.IgnoredProperties
+	t.FailedResources += o.FailedResources
+	t.Untranslated += o.Untranslated
+}
+
+// addReport adds the report of a file to the project report.
+func (p *ProjectInfo) addReport(r *FileReport) {
+	r.updateTotals()
+	p.reports = append(p.reports, r)
+}
+
+// escapeCell escapes text for use in a Markdown table.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func writeTotalsMarkdown(sb *strings.Builder, t ReportTotals) {
+	sb.WriteString("| Controls | Unsupported | Ignored properties | Failed resources | Untranslated code |\n")
+	sb.WriteString("| ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(sb, "| %d | %d | %d | %d | %d |\n\n", t.Controls, t.Unsupported, t.IgnoredProperties, t.FailedResources, t.Untranslated)
+}
+
+// writeFileMarkdown writes the report of a file, heading is the prefix of the
+// top level heading.
+func writeFileMarkdown(sb *strings.Builder, r *FileReport, heading string) {
+	fmt.Fprintf(sb, "%s %s\n\n", heading, r.Name)
+	fmt.Fprintf(sb, "Converted from `%s` (%s).\n\n", r.Filename, r.Kind)
+	writeTotalsMarkdown(sb, r.Totals)
+
+	if unsupported := r.unsupported(); len(unsupported) > 0 {
+		fmt.Fprintf(sb, "%s# Unsupported controls\n\n", heading)
+		sb.WriteString("These controls were replaced by a placeholder panel, their original definition is kept as a comment in the designer file.\n\n")
+		sb.WriteString("| Control | Type |\n")
+		sb.WriteString("| --- | --- |\n")
+		for _, c := range unsupported {
+			fmt.Fprintf(sb, "| %s | %s |\n", c.Name, c.TypeName)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Controls) > 0 {
+		fmt.Fprintf(sb, "%s# Controls\n\n", heading)
+		sb.WriteString("| Control | Type | .NET type | Ignored properties |\n")
+		sb.WriteString("| --- | --- | --- | --- |\n")
+		for _, c := range r.Controls {
+			mappedType := c.MappedType
+			if !c.Supported {
+				mappedType = "*unsupported*"
+			}
+			fmt.Fprintf(sb, "| %s | %s | %s | %s |\n", c.Name, c.TypeName, mappedType, strings.Join(c.IgnoredProperties, ", "))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.ContextMenus) > 0 {
+		fmt.Fprintf(sb, "%s# Context menus\n\n", heading)
+		sb.WriteString("These invisible menus were converted to a ContextMenuStrip, calls to PopupMenu show them at the cursor position.\n\n")
+		for _, name := range r.ContextMenus {
+			fmt.Fprintf(sb, "- %s\n", name)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.FailedResources) > 0 {
+		fmt.Fprintf(sb, "%s# Failed resources\n\n", heading)
+		sb.WriteString("| Control | Property | Resource | Error |\n")
+		sb.WriteString("| --- | --- | --- | --- |\n")
+		for _, res := range r.FailedResources {
+			fmt.Fprintf(sb, "| %s | %s | %s | %s |\n", res.Control, res.Property, escapeCell(res.Resource), escapeCell(res.Error))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Untranslated) > 0 {
+		fmt.Fprintf(sb, "%s# Untranslated code\n\n", heading)
+		sb.WriteString("| Line | Reason |\n")
+		sb.WriteString("| ---: | --- |\n")
+		for _, issue := range r.Untranslated {
+			fmt.Fprintf(sb, "| %d | %s |\n", issue.Line, escapeCell(issue.Reason))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// writeFormReport writes the report of a form next to its code, forms without
+// issues do not get a report.
+func writeFormReport(p *ProjectInfo, r *FileReport) error {
+	if !r.hasIssues() {
+		return nil
+	}
+
+	var sb strings.Builder
+	writeFileMarkdown(&sb, r, "#")
+
+	filename := filepath.Join(p.Output, r.Name+".report.md")
+	return os.WriteFile(filename, []byte(sb.String()), 0644)
+}
+
+// WriteReport writes the report of all the exported files to the output
+// directory, as JSON and as Markdown.
+func WriteReport(p *ProjectInfo) error {
+	report := &ProjectReport{
+		Project: p.Name,
+		Files:   p.reports,
+	}
+	if report.Files == nil {
+		report.Files = make([]*FileReport, 0)
+	}
+	for _, r := range report.Files {
+		report.Totals.add(r.Totals)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(p.Output, p.Name+".report.json"), data, 0644); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s conversion report\n\n", p.Name)
+	fmt.Fprintf(&sb, "%d files converted.\n\n", report.Totals.Files)
+	writeTotalsMarkdown(&sb, report.Totals)
+	for _, r := range report.Files {
+		writeFileMarkdown(&sb, r, "##")
+	}
+
+	return os.WriteFile(filepath.Join(p.Output, p.Name+".report.md"), []byte(sb.String()), 0644)
+}
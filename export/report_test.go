@@ -0,0 +1,120 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	form, ctx := buildTestForm(t, `VERSION 5.00
+Object = "{12345678-1234-1234-1234-123456789012}#1.0#0"; "ACME.OCX"
+Begin VB.Form frmTest
+   Caption         =   "Test"
+   ClientHeight    =   3000
+   ClientWidth     =   4000
+   Begin AcmeLib.Gauge ggeSpeed
+      Height          =   1500
+      Left            =   120
+      TabIndex        =   0
+      Top             =   120
+      Width           =   1500
+   End
+   Begin VB.Image imgLogo
+      Height          =   500
+      Left            =   1800
+      Picture         =   "frmTest.frx":0000
+      Top             =   120
+      Width           =   500
+   End
+   Begin VB.Label lblName
+      Caption         =   "Name"
+      DataField       =   "Name"
+      Height          =   255
+      Left            =   120
+      TabIndex        =   1
+      Top             =   1800
+      Width           =   1000
+   End
+End
+`)
+
+	r := newFileReport(form.Name, "form", filepath.Join("forms", "frmTest.frm"))
+	r.addControls(form)
+	r.FailedResources = ctx.failedResources
+	r.Untranslated = []CodeIssue{{Line: 12, Reason: "a | b is not supported"}}
+	p := &ProjectInfo{Name: "Test", Output: t.TempDir()}
+	p.addReport(r)
+
+	want := ReportTotals{Controls: 4, Unsupported: 1, IgnoredProperties: 1, FailedResources: 1, Untranslated: 1}
+	if r.Totals != want {
+		t.Errorf("got totals %+v, want %+v", r.Totals, want)
+	}
+	if r.Filename != "frmTest.frm" {
+		t.Errorf("got file %s, want frmTest.frm", r.Filename)
+	}
+	for _, c := range r.Controls {
+		switch c.Name {
+		case "ggeSpeed":
+			if c.Supported || c.MappedType != "" {
+				t.Errorf("ggeSpeed is reported as %s", c.MappedType)
+			}
+		case "lblName":
+			if len(c.IgnoredProperties) != 1 || c.IgnoredProperties[0] != "DataField" {
+				t.Errorf("got ignored properties %v for lblName, want DataField", c.IgnoredProperties)
+			}
+		}
+	}
+
+	if err := WriteReport(p); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.Output, "Test.report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report ProjectReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	want.Files = 1
+	if report.Project != "Test" || len(report.Files) != 1 || report.Totals != want {
+		t.Errorf("got project %s with %d files and totals %+v", report.Project, len(report.Files), report.Totals)
+	}
+
+	data, err = os.ReadFile(filepath.Join(p.Output, "Test.report.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	for _, s := range []string{
+		"# Test conversion report\n",
+		"## frmTest\n",
+		"### Unsupported controls\n",
+		"| ggeSpeed | AcmeLib.Gauge |\n",
+		"| lblName | VB.Label | System.Windows.Forms.Label | DataField |\n",
+		"| imgLogo | Picture | \"frmTest.frx\":0000 |",
+		"| 12 | a \\| b is not supported |\n",
+	} {
+		if !strings.Contains(md, s) {
+			t.Errorf("the Markdown report does not contain %q:\n%s", s, md)
+		}
+	}
+}
+
+func TestFormReportWithoutIssues(t *testing.T) {
+	r := newFileReport("frmTest", "form", "frmTest.frm")
+	r.Controls = []ControlReport{{Name: "frmTest", TypeName: "VB.Form", MappedType: "System.Windows.Forms.Form", Supported: true}}
+	p := &ProjectInfo{Name: "Test", Output: t.TempDir()}
+	p.addReport(r)
+
+	if err := writeFormReport(p, r); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(p.Output, "frmTest.report.md")); !os.IsNotExist(err) {
+		t.Error("a report is written for a form without issues")
+	}
+}
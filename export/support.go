@@ -0,0 +1,740 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// supportClasses holds the C# source of helper classes that generated controls
+// depend on, by class name. A class is written to the project when a form
+// uses it.
+var supportClasses = map[string]string{
+	"ShapeControl": shapeControlSource,
+	"LineControl":  lineControlSource,
+
+	"CommonDialogControl": commonDialogControlSource,
+	"WinsockControl":      winsockControlSource,
+}
+
+const shapeControlSource = `/// <summary>
+/// Replacement for the VB6 Shape control.
+/// </summary>
+public class ShapeControl : Control
+{
+	private int shape;
+	private Color borderColor = SystemColors.WindowText;
+	private int borderWidth = 1;
+	private int borderStyle = 1;
+	private int fillStyle = 1;
+	private Color fillColor = Color.Black;
+	private int backStyle;
+
+	public ShapeControl()
+	{
+		SetStyle(ControlStyles.SupportsTransparentBackColor | ControlStyles.UserPaint |
+			ControlStyles.AllPaintingInWmPaint | ControlStyles.OptimizedDoubleBuffer | ControlStyles.ResizeRedraw, true);
+		SetStyle(ControlStyles.Selectable, false);
+		base.BackColor = Color.Transparent;
+		TabStop = false;
+	}
+
+	/// <summary>
+	/// 0 = Rectangle, 1 = Square, 2 = Oval, 3 = Circle, 4 = Rounded Rectangle, 5 = Rounded Square
+	/// </summary>
+	public int Shape { get => shape; set { shape = value; Invalidate(); } }
+
+	public Color BorderColor { get => borderColor; set { borderColor = value; Invalidate(); } }
+
+	public int BorderWidth { get => borderWidth; set { borderWidth = Math.Max(1, value); Invalidate(); } }
+
+	/// <summary>
+	/// 0 = Transparent, 1 = Solid, 2 = Dash, 3 = Dot, 4 = Dash-Dot, 5 = Dash-Dot-Dot, 6 = Inside Solid
+	/// </summary>
+	public int BorderStyle { get => borderStyle; set { borderStyle = value; Invalidate(); } }
+
+	/// <summary>
+	/// 0 = Solid, 1 = Transparent, 2 = Horizontal Line, 3 = Vertical Line, 4 = Upward Diagonal,
+	/// 5 = Downward Diagonal, 6 = Cross, 7 = Diagonal Cross
+	/// </summary>
+	public int FillStyle { get => fillStyle; set { fillStyle = value; Invalidate(); } }
+
+	public Color FillColor { get => fillColor; set { fillColor = value; Invalidate(); } }
+
+	/// <summary>
+	/// 0 = Transparent, 1 = Opaque
+	/// </summary>
+	public int BackStyle { get => backStyle; set { backStyle = value; Invalidate(); } }
+
+	protected override void OnPaint(PaintEventArgs e)
+	{
+		e.Graphics.SmoothingMode = System.Drawing.Drawing2D.SmoothingMode.AntiAlias;
+
+		var bounds = GetShapeBounds();
+		using (var path = GetShapePath(bounds))
+		{
+			if (backStyle == 1)
+			{
+				using (var brush = new SolidBrush(BackColor))
+				{
+					e.Graphics.FillPath(brush, path);
+				}
+			}
+			if (fillStyle != 1)
+			{
+				using (var brush = CreateFillBrush())
+				{
+					e.Graphics.FillPath(brush, path);
+				}
+			}
+			if (borderStyle != 0)
+			{
+				using (var pen = new Pen(borderColor, borderWidth))
+				{
+					pen.DashStyle = GetDashStyle(borderStyle);
+					e.Graphics.DrawPath(pen, path);
+				}
+			}
+		}
+
+		base.OnPaint(e);
+	}
+
+	private Rectangle GetShapeBounds()
+	{
+		int inset = borderWidth / 2;
+		var bounds = new Rectangle(inset, inset, Width - borderWidth, Height - borderWidth);
+		if (shape == 1 || shape == 3 || shape == 5)
+		{
+			int size = Math.Min(bounds.Width, bounds.Height);
+			bounds = new Rectangle(bounds.X + (bounds.Width - size) / 2, bounds.Y + (bounds.Height - size) / 2, size, size);
+		}
+		return bounds;
+	}
+
+	private System.Drawing.Drawing2D.GraphicsPath GetShapePath(Rectangle bounds)
+	{
+		var path = new System.Drawing.Drawing2D.GraphicsPath();
+		switch (shape)
+		{
+			case 2:
+			case 3:
+				path.AddEllipse(bounds);
+				break;
+			case 4:
+			case 5:
+				int radius = Math.Max(1, Math.Min(bounds.Width, bounds.Height) / 4);
+				path.AddArc(bounds.X, bounds.Y, radius * 2, radius * 2, 180, 90);
+				path.AddArc(bounds.Right - radius * 2, bounds.Y, radius * 2, radius * 2, 270, 90);
+				path.AddArc(bounds.Right - radius * 2, bounds.Bottom - radius * 2, radius * 2, radius * 2, 0, 90);
+				path.AddArc(bounds.X, bounds.Bottom - radius * 2, radius * 2, radius * 2, 90, 90);
+				path.CloseFigure();
+				break;
+			default:
+				path.AddRectangle(bounds);
+				break;
+		}
+		return path;
+	}
+
+	private Brush CreateFillBrush()
+	{
+		switch (fillStyle)
+		{
+			case 2:
+				return new System.Drawing.Drawing2D.HatchBrush(System.Drawing.Drawing2D.HatchStyle.Horizontal, fillColor, Color.Transparent);
+			case 3:
+				return new System.Drawing.Drawing2D.HatchBrush(System.Drawing.Drawing2D.HatchStyle.Vertical, fillColor, Color.Transparent);
+			case 4:
+				return new System.Drawing.Drawing2D.HatchBrush(System.Drawing.Drawing2D.HatchStyle.ForwardDiagonal, fillColor, Color.Transparent);
+			case 5:
+				return new System.Drawing.Drawing2D.HatchBrush(System.Drawing.Drawing2D.HatchStyle.BackwardDiagonal, fillColor, Color.Transparent);
+			case 6:
+				return new System.Drawing.Drawing2D.HatchBrush(System.Drawing.Drawing2D.HatchStyle.Cross, fillColor, Color.Transparent);
+			case 7:
+				return new System.Drawing.Drawing2D.HatchBrush(System.Drawing.Drawing2D.HatchStyle.DiagonalCross, fillColor, Color.Transparent);
+			default:
+				return new SolidBrush(fillColor);
+		}
+	}
+
+	internal static System.Drawing.Drawing2D.DashStyle GetDashStyle(int borderStyle)
+	{
+		switch (borderStyle)
+		{
+			case 2:
+				return System.Drawing.Drawing2D.DashStyle.Dash;
+			case 3:
+				return System.Drawing.Drawing2D.DashStyle.Dot;
+			case 4:
+				return System.Drawing.Drawing2D.DashStyle.DashDot;
+			case 5:
+				return System.Drawing.Drawing2D.DashStyle.DashDotDot;
+			default:
+				return System.Drawing.Drawing2D.DashStyle.Solid;
+		}
+	}
+}`
+
+const lineControlSource = `/// <summary>
+/// Replacement for the VB6 Line control. The end points are in the coordinates
+/// of the parent, the control sizes itself to the bounding box of the line.
+/// </summary>
+public class LineControl : Control
+{
+	private int x1, y1, x2, y2;
+	private Color borderColor = SystemColors.WindowText;
+	private int borderWidth = 1;
+	private int borderStyle = 1;
+
+	public LineControl()
+	{
+		SetStyle(ControlStyles.SupportsTransparentBackColor | ControlStyles.UserPaint |
+			ControlStyles.AllPaintingInWmPaint | ControlStyles.OptimizedDoubleBuffer | ControlStyles.ResizeRedraw, true);
+		SetStyle(ControlStyles.Selectable, false);
+		BackColor = Color.Transparent;
+		TabStop = false;
+	}
+
+	public int X1 { get => x1; set { x1 = value; UpdateLineBounds(); } }
+
+	public int Y1 { get => y1; set { y1 = value; UpdateLineBounds(); } }
+
+	public int X2 { get => x2; set { x2 = value; UpdateLineBounds(); } }
+
+	public int Y2 { get => y2; set { y2 = value; UpdateLineBounds(); } }
+
+	public Color BorderColor { get => borderColor; set { borderColor = value; Invalidate(); } }
+
+	public int BorderWidth { get => borderWidth; set { borderWidth = Math.Max(1, value); UpdateLineBounds(); } }
+
+	/// <summary>
+	/// 0 = Transparent, 1 = Solid, 2 = Dash, 3 = Dot, 4 = Dash-Dot, 5 = Dash-Dot-Dot, 6 = Inside Solid
+	/// </summary>
+	public int BorderStyle { get => borderStyle; set { borderStyle = value; Invalidate(); } }
+
+	private void UpdateLineBounds()
+	{
+		int left = Math.Min(x1, x2) - borderWidth / 2;
+		int top = Math.Min(y1, y2) - borderWidth / 2;
+		SetBounds(left, top, Math.Abs(x2 - x1) + borderWidth, Math.Abs(y2 - y1) + borderWidth);
+		Invalidate();
+	}
+
+	protected override void OnPaint(PaintEventArgs e)
+	{
+		if (borderStyle != 0)
+		{
+			using (var pen = new Pen(borderColor, borderWidth))
+			{
+				pen.DashStyle = ShapeControl.GetDashStyle(borderStyle);
+				e.Graphics.DrawLine(pen, x1 - Left, y1 - Top, x2 - Left, y2 - Top);
+			}
+		}
+
+		base.OnPaint(e);
+	}
+}`
+
+// getSupportClasses returns the names of the support classes used by the
+// controls of a form.
+const commonDialogControlSource = `/// <summary>
+/// Replacement for the VB6 CommonDialog control, showing the .NET dialogs.
+/// </summary>
+public class CommonDialogControl : System.ComponentModel.Component
+{
+	private string fileName = "";
+	private int flags;
+
+	public CommonDialogControl()
+	{
+	}
+
+	public CommonDialogControl(System.ComponentModel.IContainer container)
+	{
+		container.Add(this);
+	}
+
+	public OpenFileDialog OpenDialog { get; set; } = new OpenFileDialog();
+
+	public SaveFileDialog SaveDialog { get; set; } = new SaveFileDialog();
+
+	public ColorDialog ColorDialog { get; set; } = new ColorDialog();
+
+	public FontDialog FontDialog { get; set; } = new FontDialog();
+
+	public PrintDialog PrintDialog { get; set; } = new PrintDialog();
+
+	/// <summary>
+	/// Throws an OperationCanceledException when the user cancels a dialog.
+	/// </summary>
+	public bool CancelError { get; set; }
+
+	public string FileName
+	{
+		get => fileName;
+		set => fileName = value ?? "";
+	}
+
+	public string FileTitle => System.IO.Path.GetFileName(fileName);
+
+	public string Filter
+	{
+		get => OpenDialog.Filter;
+		set { OpenDialog.Filter = value; SaveDialog.Filter = value; }
+	}
+
+	public int FilterIndex
+	{
+		get => OpenDialog.FilterIndex;
+		set { OpenDialog.FilterIndex = value; SaveDialog.FilterIndex = value; }
+	}
+
+	public string DefaultExt
+	{
+		get => OpenDialog.DefaultExt;
+		set { OpenDialog.DefaultExt = value; SaveDialog.DefaultExt = value; }
+	}
+
+	public string DialogTitle
+	{
+		get => OpenDialog.Title;
+		set { OpenDialog.Title = value; SaveDialog.Title = value; }
+	}
+
+	public string InitDir
+	{
+		get => OpenDialog.InitialDirectory;
+		set { OpenDialog.InitialDirectory = value; SaveDialog.InitialDirectory = value; }
+	}
+
+	/// <summary>
+	/// The dialog flags (cdlOFN*, cdlCC*, cdlCF* and cdlPD*), applied to all dialogs.
+	/// </summary>
+	public int Flags
+	{
+		get => flags;
+		set
+		{
+			flags = value;
+			OpenDialog.ReadOnlyChecked = (value & 0x1) != 0;
+			OpenDialog.ShowReadOnly = (value & 0x4) == 0;
+			OpenDialog.Multiselect = (value & 0x200) != 0;
+			SaveDialog.OverwritePrompt = (value & 0x2) != 0;
+			SaveDialog.CreatePrompt = (value & 0x2000) != 0;
+			foreach (FileDialog dialog in new FileDialog[] { OpenDialog, SaveDialog })
+			{
+				dialog.RestoreDirectory = (value & 0x8) != 0;
+				dialog.CheckPathExists = (value & 0x1800) != 0;
+				dialog.CheckFileExists = (value & 0x1000) != 0;
+			}
+			ColorDialog.FullOpen = (value & 0x2) != 0;
+			ColorDialog.AllowFullOpen = (value & 0x4) == 0;
+			FontDialog.ShowEffects = (value & 0x100) != 0;
+			FontDialog.FixedPitchOnly = (value & 0x4000) != 0;
+			FontDialog.FontMustExist = (value & 0x10000) != 0;
+		}
+	}
+
+	/// <summary>
+	/// The selected color as an OLE color.
+	/// </summary>
+	public int Color
+	{
+		get => ColorTranslator.ToOle(ColorDialog.Color);
+		set => ColorDialog.Color = ColorTranslator.FromOle(value);
+	}
+
+	public string FontName
+	{
+		get => FontDialog.Font.Name;
+		set => FontDialog.Font = new Font(value, FontDialog.Font.Size, FontDialog.Font.Style);
+	}
+
+	public float FontSize
+	{
+		get => FontDialog.Font.Size;
+		set => FontDialog.Font = new Font(FontDialog.Font.FontFamily, value, FontDialog.Font.Style);
+	}
+
+	public bool FontBold
+	{
+		get => FontDialog.Font.Bold;
+		set => SetFontStyle(FontStyle.Bold, value);
+	}
+
+	public bool FontItalic
+	{
+		get => FontDialog.Font.Italic;
+		set => SetFontStyle(FontStyle.Italic, value);
+	}
+
+	public bool FontUnderline
+	{
+		get => FontDialog.Font.Underline;
+		set => SetFontStyle(FontStyle.Underline, value);
+	}
+
+	public bool FontStrikethru
+	{
+		get => FontDialog.Font.Strikeout;
+		set => SetFontStyle(FontStyle.Strikeout, value);
+	}
+
+	public int Copies
+	{
+		get => PrintDialog.PrinterSettings.Copies;
+		set => PrintDialog.PrinterSettings.Copies = (short)value;
+	}
+
+	public void ShowOpen()
+	{
+		OpenDialog.FileName = fileName;
+		Show(OpenDialog);
+		fileName = OpenDialog.FileName;
+	}
+
+	public void ShowSave()
+	{
+		SaveDialog.FileName = fileName;
+		Show(SaveDialog);
+		fileName = SaveDialog.FileName;
+	}
+
+	public void ShowColor()
+	{
+		Show(ColorDialog);
+	}
+
+	public void ShowFont()
+	{
+		Show(FontDialog);
+	}
+
+	public void ShowPrinter()
+	{
+		Show(PrintDialog);
+	}
+
+	private void SetFontStyle(FontStyle style, bool value)
+	{
+		var font = FontDialog.Font;
+		FontDialog.Font = new Font(font, value ? font.Style | style : font.Style & ~style);
+	}
+
+	private void Show(System.Windows.Forms.CommonDialog dialog)
+	{
+		if (dialog.ShowDialog() != DialogResult.OK && CancelError)
+		{
+			throw new OperationCanceledException("Cancel was selected.");
+		}
+	}
+}
+`
+
+const winsockControlSource = `using System.Collections.Generic;
+using System.Net;
+using System.Net.Sockets;
+using System.Threading;
+
+public class WinsockDataArrivalEventArgs : EventArgs
+{
+	public WinsockDataArrivalEventArgs(int bytesTotal) => BytesTotal = bytesTotal;
+
+	public int BytesTotal { get; }
+}
+
+public class WinsockConnectionRequestEventArgs : EventArgs
+{
+	public WinsockConnectionRequestEventArgs(int requestID) => RequestID = requestID;
+
+	public int RequestID { get; }
+}
+
+public class WinsockErrorEventArgs : EventArgs
+{
+	public WinsockErrorEventArgs(int number, string description)
+	{
+		Number = number;
+		Description = description;
+	}
+
+	public int Number { get; }
+
+	public string Description { get; }
+}
+
+/// <summary>
+/// Replacement for the VB6 Winsock control, supporting TCP connections only.
+/// Events are raised on the thread that created the control.
+/// </summary>
+public class WinsockControl : System.ComponentModel.Component
+{
+	// Connections accepted by a listening control, waiting for Accept
+	private static readonly Dictionary<int, TcpClient> pending = new Dictionary<int, TcpClient>();
+	private static int nextRequestID;
+
+	// Strings are sent and received as ANSI, one byte per character
+	private static readonly System.Text.Encoding encoding = System.Text.Encoding.GetEncoding(28591);
+
+	private readonly SynchronizationContext context = SynchronizationContext.Current ?? new SynchronizationContext();
+	private readonly List<byte> received = new List<byte>();
+	private readonly byte[] readBuffer = new byte[8192];
+	private TcpClient client;
+	private TcpListener listener;
+	private NetworkStream stream;
+
+	public WinsockControl()
+	{
+	}
+
+	public WinsockControl(System.ComponentModel.IContainer container)
+	{
+		container.Add(this);
+	}
+
+	public event EventHandler Connected;
+
+	public event EventHandler Closed;
+
+	public event EventHandler SendComplete;
+
+	public event EventHandler<WinsockDataArrivalEventArgs> DataArrival;
+
+	public event EventHandler<WinsockConnectionRequestEventArgs> ConnectionRequest;
+
+	public event EventHandler<WinsockErrorEventArgs> Error;
+
+	public string RemoteHost { get; set; } = "";
+
+	public int RemotePort { get; set; }
+
+	public int LocalPort { get; set; }
+
+	/// <summary>
+	/// 0 = TCP, UDP is not supported
+	/// </summary>
+	public int Protocol { get; set; }
+
+	/// <summary>
+	/// 0 = Closed, 2 = Listening, 6 = Connecting, 7 = Connected, 8 = Closing, 9 = Error
+	/// </summary>
+	public int State { get; private set; }
+
+	public int BytesReceived => received.Count;
+
+	public string RemoteHostIP => (client?.Client?.RemoteEndPoint as IPEndPoint)?.Address.ToString() ?? "";
+
+	public void Connect()
+	{
+		Connect(RemoteHost, RemotePort);
+	}
+
+	public void Connect(string remoteHost, int remotePort)
+	{
+		RemoteHost = remoteHost;
+		RemotePort = remotePort;
+		client = new TcpClient();
+		State = 6;
+		var connecting = client;
+		connecting.ConnectAsync(remoteHost, remotePort).ContinueWith(t => Post(() =>
+		{
+			if (connecting != client)
+			{
+				return;
+			}
+			if (t.IsFaulted)
+			{
+				Fail(t.Exception.GetBaseException());
+				return;
+			}
+			Start();
+			Connected?.Invoke(this, EventArgs.Empty);
+		}));
+	}
+
+	public void Listen()
+	{
+		listener = new TcpListener(IPAddress.Any, LocalPort);
+		listener.Start();
+		State = 2;
+		AcceptNext(listener);
+	}
+
+	public void Accept(int requestID)
+	{
+		lock (pending)
+		{
+			client = pending[requestID];
+			pending.Remove(requestID);
+		}
+		Start();
+	}
+
+	public void SendData(object data)
+	{
+		var bytes = data as byte[] ?? encoding.GetBytes(Convert.ToString(data));
+		try
+		{
+			stream.Write(bytes, 0, bytes.Length);
+			Post(() => SendComplete?.Invoke(this, EventArgs.Empty));
+		}
+		catch (Exception ex)
+		{
+			Fail(ex);
+		}
+	}
+
+	/// <summary>
+	/// Returns the received data as a string and removes it from the buffer.
+	/// </summary>
+	public string GetString()
+	{
+		var data = PeekString();
+		received.Clear();
+		return data;
+	}
+
+	/// <summary>
+	/// Returns the received data and removes it from the buffer.
+	/// </summary>
+	public byte[] GetBytes()
+	{
+		var data = received.ToArray();
+		received.Clear();
+		return data;
+	}
+
+	public string PeekString()
+	{
+		return encoding.GetString(received.ToArray());
+	}
+
+	public byte[] PeekBytes()
+	{
+		return received.ToArray();
+	}
+
+	public void Close()
+	{
+		listener?.Stop();
+		listener = null;
+		stream = null;
+		client?.Close();
+		client = null;
+		received.Clear();
+		State = 0;
+	}
+
+	protected override void Dispose(bool disposing)
+	{
+		if (disposing)
+		{
+			Close();
+		}
+		base.Dispose(disposing);
+	}
+
+	private void Post(Action action)
+	{
+		context.Post(_ => action(), null);
+	}
+
+	private void AcceptNext(TcpListener listening)
+	{
+		listening.AcceptTcpClientAsync().ContinueWith(t => Post(() =>
+		{
+			if (listening != listener)
+			{
+				return;
+			}
+			if (t.IsFaulted)
+			{
+				Fail(t.Exception.GetBaseException());
+				return;
+			}
+			int requestID;
+			lock (pending)
+			{
+				requestID = ++nextRequestID;
+				pending[requestID] = t.Result;
+			}
+			ConnectionRequest?.Invoke(this, new WinsockConnectionRequestEventArgs(requestID));
+			AcceptNext(listening);
+		}));
+	}
+
+	private void Start()
+	{
+		stream = client.GetStream();
+		State = 7;
+		BeginRead(stream);
+	}
+
+	private void BeginRead(NetworkStream reading)
+	{
+		reading.ReadAsync(readBuffer, 0, readBuffer.Length).ContinueWith(t => Post(() =>
+		{
+			if (reading != stream)
+			{
+				return;
+			}
+			if (t.IsFaulted)
+			{
+				Fail(t.Exception.GetBaseException());
+				return;
+			}
+			if (t.Result == 0)
+			{
+				State = 8;
+				Closed?.Invoke(this, EventArgs.Empty);
+				return;
+			}
+			for (int i = 0; i < t.Result; i++)
+			{
+				received.Add(readBuffer[i]);
+			}
+			BeginRead(reading);
+			DataArrival?.Invoke(this, new WinsockDataArrivalEventArgs(received.Count));
+		}));
+	}
+
+	private void Fail(Exception ex)
+	{
+		State = 9;
+		var number = ex is SocketException socketException ? socketException.ErrorCode : 0;
+		Post(() => Error?.Invoke(this, new WinsockErrorEventArgs(number, ex.Message)));
+	}
+}
+`
+
+func getSupportClasses(f *Control, used map[string]bool) map[string]bool {
+	if _, ok := supportClasses[f.TypeName]; ok {
+		used[f.TypeName] = true
+	}
+	for _, c := range f.Children {
+		getSupportClasses(c, used)
+	}
+	return used
+}
+
+func writeSupportClasses(p *ProjectInfo, f *Control) {
+	for name := range getSupportClasses(f, make(map[string]bool)) {
+		fileName := filepath.Join(p.Output, name+".cs")
+		file, err := os.Create(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", fileName, err)
+			continue
+		}
+		file.WriteString(fmt.Sprintf(`using System;
+using System.Drawing;
+using System.Windows.Forms;
+
+namespace %s;
+
+%s
+`, p.Namespace, strings.TrimSpace(supportClasses[name])))
+		file.Close()
+	}
+}
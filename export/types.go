@@ -0,0 +1,279 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6/ast"
+)
+
+// userType is a user defined type or an enum, as seen by the code using it.
+type userType struct {
+	Code    string            // C# type
+	Decl    *ast.TypeDecl     // fields of a user defined type, nil for enums
+	Members map[string]string // C# expressions of the members of an enum, by key
+}
+
+// declareTypes adds the user defined types and enums of the file to the
+// symbol table. The members of enums can be used without the name of the
+// enum, unless another enum has a member with the same name.
+func (t *translator) declareTypes() {
+	for _, d := range t.file.Decls {
+		switch d := d.(type) {
+		case *ast.TypeDecl:
+			t.types[key(d.Name)] = &userType{Code: csName(d.Name), Decl: d}
+		case *ast.EnumDecl:
+			ut := &userType{Code: csName(d.Name), Members: make(map[string]string)}
+			for _, m := range d.Members {
+				// Enums are ints, as in VB6
+				code := fmt.Sprintf("((int)%s.%s)", ut.Code, csName(m.Name))
+				ut.Members[key(m.Name)] = code
+				if _, ok := t.globals[key(m.Name)]; !ok {
+					t.globals[key(m.Name)] = &symbol{Kind: constSymbol, Code: code, Type: "int"}
+				}
+			}
+			t.types[key(d.Name)] = ut
+		}
+	}
+}
+
+// shareTypes adds the public user defined types and enums of a module to the
+// project. They are written next to the class of the module, so they are
+// used without the name of the module.
+func (t *translator) shareTypes(p *ProjectInfo) {
+	if p.types == nil {
+		p.types = make(map[string]*userType)
+	}
+	for _, d := range t.file.Decls {
+		switch d := d.(type) {
+		case *ast.TypeDecl:
+			if t.namespaceType(d.Visibility) {
+				p.types[key(d.Name)] = t.types[key(d.Name)]
+			}
+		case *ast.EnumDecl:
+			if !t.namespaceType(d.Visibility) {
+				continue
+			}
+			ut := t.types[key(d.Name)]
+			p.types[key(d.Name)] = ut
+			for k, code := range ut.Members {
+				if _, ok := p.symbols[k]; !ok {
+					p.symbols[k] = &symbol{Kind: constSymbol, Code: code, Type: "int"}
+				}
+			}
+		}
+	}
+}
+
+// namespaceType reports whether a user defined type or enum is written next
+// to the class instead of nested in it, which is the case for the public
+// types of modules. Public classes can use them in their signatures.
+func (t *translator) namespaceType(vis string) bool {
+	return t.static && visibility(vis, "public") == "public"
+}
+
+// lookupType returns the user defined type or enum called name, or nil.
+func (t *translator) lookupType(name string) *userType {
+	if ut, ok := t.types[key(name)]; ok {
+		return ut
+	}
+	return t.sharedTypes[key(name)]
+}
+
+// typeName converts a VB6 type name into a C# type name, including the user
+// defined types and enums of the project. Enums are ints.
+func (t *translator) typeName(name string) string {
+	ut := t.lookupType(name)
+	switch {
+	case ut == nil:
+		return csTypeName(name)
+	case ut.Decl == nil:
+		return "int"
+	}
+	return ut.Code
+}
+
+// structType returns the user defined type typ is the C# type of, or nil.
+func (t *translator) structType(typ string) *userType {
+	for _, types := range []map[string]*userType{t.types, t.sharedTypes} {
+		for _, ut := range types {
+			if ut.Decl != nil && ut.Code == typ {
+				return ut
+			}
+		}
+	}
+	return nil
+}
+
+// newValue returns the value VB6 initializes variables of type typ with.
+// User defined types that have fields to initialize are created by the
+// Create method of their struct.
+func (t *translator) newValue(typ string) string {
+	ut := t.structType(typ)
+	switch {
+	case ut == nil:
+		return zeroValue(typ)
+	case len(t.fieldInits(ut)) > 0:
+		return ut.Code + ".Create()"
+	}
+	return "new " + ut.Code + "()"
+}
+
+// fieldInits returns the statements initializing the fields of a user defined
+// type whose VB6 initial value is not the C# default, such as strings and
+// fixed size arrays.
+func (t *translator) fieldInits(ut *userType) []string {
+	inits := make([]string, 0)
+	for _, f := range ut.Decl.Fields {
+		typ := t.specType(f)
+		init := t.initValue(f, typ)
+		if init == zeroValue(typ) && typ != "string" || t.structType(typ) != nil && init == "new "+typ+"()" {
+			continue
+		}
+		inits = append(inits, fmt.Sprintf("value.%s = %s;", csName(f.Name), init))
+	}
+	return inits
+}
+
+// field returns the field of a user defined type as a member of recv.
+func (t *translator) field(recv value, ut *userType, name string, args []*ast.Arg) (value, bool) {
+	for _, f := range ut.Decl.Fields {
+		if key(f.Name) == name {
+			v := value{code: wrap(recv, precPrimary) + "." + csName(f.Name), typ: t.specType(f), prec: precPrimary, lvalue: true}
+			return t.index(v, args), true
+		}
+	}
+	return value{}, false
+}
+
+// enumMember translates a member of an enum qualified with the name of the
+// enum, as in Colors.Red.
+func (t *translator) enumMember(enum string, name string) (value, bool) {
+	ut := t.lookupType(enum)
+	if ut == nil || ut.Decl != nil {
+		return value{}, false
+	}
+	code, ok := ut.Members[key(name)]
+	if !ok {
+		t.fail("%s is not a member of %s", name, enum)
+	}
+	return value{code: code, typ: "int", prec: precPrimary, konst: true}, true
+}
+
+// writeTypes writes the public user defined types and enums of a module next
+// to its class.
+func (t *translator) writeTypes() {
+	for _, d := range t.file.Decls {
+		switch d := d.(type) {
+		case *ast.TypeDecl:
+			if t.namespaceType(d.Visibility) {
+				t.writeType(d)
+				t.w.Writeln()
+			}
+		case *ast.EnumDecl:
+			if t.namespaceType(d.Visibility) {
+				t.writeEnum(d)
+				t.w.Writeln()
+			}
+		}
+	}
+}
+
+// writeType writes a user defined type as a struct.
+func (t *translator) writeType(d *ast.TypeDecl) {
+	t.tryDecl(d, func() {
+		ut := t.lookupType(d.Name)
+		fields := make([]string, 0, len(d.Fields))
+		for _, f := range d.Fields {
+			fields = append(fields, fmt.Sprintf("public %s %s;", t.specType(f), csName(f.Name)))
+		}
+		inits := t.fieldInits(ut)
+
+		t.w.Writef("%s struct %s", visibility(d.Visibility, "public"), ut.Code)
+		t.w.Write("{")
+		t.w.WriteIndent(func() {
+			for _, f := range fields {
+				t.w.Write(f)
+			}
+			if len(inits) == 0 {
+				return
+			}
+			t.w.Writeln()
+			t.w.Writef("public static %s Create()", ut.Code)
+			t.w.Write("{")
+			t.w.WriteIndent(func() {
+				t.w.Writef("%s value = new %s();", ut.Code, ut.Code)
+				for _, init := range inits {
+					t.w.Write(init)
+				}
+				t.w.Write("return value;")
+			})
+			t.w.Write("}")
+		})
+		t.w.Write("}")
+	})
+}
+
+// writeEnum writes an enum. Its members are used as ints.
+func (t *translator) writeEnum(d *ast.EnumDecl) {
+	t.tryDecl(d, func() {
+		members := make([]string, 0, len(d.Members))
+		for _, m := range d.Members {
+			if m.Value == nil {
+				members = append(members, csName(m.Name)+",")
+				continue
+			}
+			v := coerce(t.value(m.Value), "int")
+			if !v.konst {
+				t.fail("the value of %s is not a constant", m.Name)
+			}
+			members = append(members, fmt.Sprintf("%s = %s,", csName(m.Name), v.code))
+		}
+
+		t.w.Writef("%s enum %s", visibility(d.Visibility, "public"), csName(d.Name))
+		t.w.Write("{")
+		t.w.WriteIndent(func() {
+			for _, m := range members {
+				t.w.Write(m)
+			}
+		})
+		t.w.Write("}")
+	})
+}
+
+// declareSymbol returns the symbol of a Declare statement. Parameters that
+// are not ByVal are passed by reference, as the DLL expects a pointer.
+func (t *translator) declareSymbol(d *ast.DeclareDecl) *symbol {
+	proc := &ast.ProcDecl{Span: d.Span, Visibility: d.Visibility, Kind: ast.SubProc, Name: d.Name, Returns: d.Returns}
+	s := &symbol{Kind: procSymbol, Code: csName(d.Name), Type: "void", Proc: proc}
+	if d.Function {
+		proc.Kind = ast.FunctionProc
+		s.Type = t.returnType(proc)
+	}
+	for _, p := range d.Params {
+		param := *p
+		param.ByRef = !p.ByVal
+		proc.Params = append(proc.Params, &param)
+		if p.Type != nil && strings.EqualFold(p.Type.Name, "Any") {
+			s.Unsupported = fmt.Sprintf("%s has As Any parameters, which are not supported", d.Name)
+		}
+	}
+	return s
+}
+
+// writeDeclare writes a Declare statement as an extern method.
+func (t *translator) writeDeclare(d *ast.DeclareDecl) {
+	t.tryDecl(d, func() {
+		s := t.globals[key(d.Name)]
+		if s.Unsupported != "" {
+			t.fail("As Any parameters are not supported")
+		}
+		attr := "DllImport(" + csString(d.Lib)
+		if d.Alias != "" {
+			attr += ", EntryPoint = " + csString(d.Alias)
+		}
+		params := t.params(s.Proc.Params)
+		t.w.Writef("[System.Runtime.InteropServices.%s)]", attr)
+		t.w.Writef("%s static extern %s %s(%s);", visibility(d.Visibility, "public"), s.Type, s.Code, params)
+	})
+}
@@ -0,0 +1,160 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guthius/vb6conv/vb6"
+	"github.com/guthius/vb6conv/vb6/ast"
+)
+
+// userControl is a UserControl of the project, as seen by the forms it is
+// placed on.
+type userControl struct {
+	Name       string
+	Properties map[string]string         // C# types of the public properties that can be set, by key
+	Events     map[string]*ast.EventDecl // public events, by key
+}
+
+// RegisterUserControl makes instances of a UserControl on forms map to the
+// generated class, with its public properties and events. User controls must
+// be registered before the forms.
+func (p *ProjectInfo) RegisterUserControl(f *vb6.Form) {
+	if p.userControls == nil {
+		p.userControls = make(map[string]*userControl)
+	}
+	uc := &userControl{
+		Name:       f.Root.Name,
+		Properties: make(map[string]string),
+		Events:     make(map[string]*ast.EventDecl),
+	}
+	p.userControls[key(uc.Name)] = uc
+
+	// Parse errors are reported when the user control is exported
+	code, _ := ast.Parse(f.Script)
+	if code == nil {
+		return
+	}
+	for _, d := range code.Decls {
+		switch d := d.(type) {
+		case *ast.ProcDecl:
+			if d.Kind != ast.PropertyLet && d.Kind != ast.PropertySet || len(d.Params) != 1 || visibility(d.Visibility, "public") != "public" {
+				continue
+			}
+			param := d.Params[0]
+			ref := param.Type
+			if ref == nil {
+				ref = typeFromSuffix(param.Name)
+			}
+			uc.Properties[key(d.Name)] = csType(ref, 0)
+		case *ast.EventDecl:
+			if visibility(d.Visibility, "public") == "public" {
+				uc.Events[key(d.Name)] = d
+			}
+		}
+	}
+}
+
+// userControlType returns the UserControl of the project a control is an
+// instance of, or nil.
+func (p *ProjectInfo) userControlType(typeName string) *userControl {
+	return p.userControls[key(shortType(typeName))]
+}
+
+// userControlEvent maps an event declared by a UserControl of the project,
+// handled on the form the user control is placed on.
+func (p *ProjectInfo) userControlEvent(c *Control, name string) (event, bool) {
+	uc := p.userControlType(c.TypeName)
+	if uc == nil {
+		return event{}, false
+	}
+	d, ok := uc.Events[key(name)]
+	if !ok {
+		return event{}, false
+	}
+	return event{
+		Name:     csName(d.Name),
+		Delegate: fmt.Sprintf("%s.%sEventHandler", uc.Name, csName(d.Name)),
+		Custom:   true,
+	}, true
+}
+
+// applyUserControlProps maps the properties saved by WriteProperties on an
+// instance of a UserControl to its public properties. Properties of other
+// types are left for the report.
+func applyUserControlProps(uc *userControl, c *vb6.Control, props map[string]string) {
+	for name := range c.Properties {
+		typ, ok := uc.Properties[key(name)]
+		if !ok {
+			continue
+		}
+		field := csName(name)
+		switch typ {
+		case "string":
+			if s, ok := vb6.GetStr(name, c.Properties); ok {
+				props[field] = toStr(s)
+			}
+		case "bool":
+			if b, ok := vb6.GetBool(name, c.Properties); ok {
+				props[field] = toBool(b)
+			}
+		case "int":
+			if i, ok := vb6.GetInt(name, c.Properties); ok {
+				props[field] = toInt(i)
+			}
+		case "byte", "float", "double", "decimal":
+			if v, ok := vb6.GetFloat64(name, c.Properties); ok {
+				props[field] = fmt.Sprintf("((%s)(%v))", typ, v)
+			}
+		}
+	}
+}
+
+// persistenceEvent reports the events of a UserControl that load and save the
+// properties of its instances, the designer sets the properties instead.
+func (t *translator) persistenceEvent(proc *ast.ProcDecl) (string, bool) {
+	if !t.isUserControl() {
+		return "", false
+	}
+	switch {
+	case strings.EqualFold(proc.Name, "UserControl_ReadProperties"):
+		return "ReadProperties is not supported, the designer sets the properties of the instances", true
+	case strings.EqualFold(proc.Name, "UserControl_WriteProperties"):
+		return "WriteProperties is not supported, the designer saves the properties of the instances", true
+	}
+	return "", false
+}
+
+// isUserControl reports whether the code being translated is the code of a
+// UserControl.
+func (t *translator) isUserControl() bool {
+	return t.form != nil && shortType(t.form.TypeName) == "UserControl"
+}
+
+// ambient translates the members of the Ambient object of a UserControl, the
+// properties of its container, and the Extender members that are not members
+// of the control itself.
+func (t *translator) ambient(obj string, name string) (value, bool) {
+	if !t.isUserControl() {
+		return value{}, false
+	}
+	switch key(obj) + "." + key(name) {
+	case "ambient.usermode":
+		return value{code: "!DesignMode", typ: "bool", prec: precUnary}, true
+	case "ambient.backcolor":
+		return primary("ColorTranslator.ToOle(Parent.BackColor)", "int"), true
+	case "ambient.forecolor":
+		return primary("ColorTranslator.ToOle(Parent.ForeColor)", "int"), true
+	case "ambient.font":
+		return primary("Parent.Font", "System.Drawing.Font"), true
+	case "ambient.displayname", "extender.name":
+		return primary("Name", "string"), true
+	case "extender.parent", "extender.container":
+		return primary("Parent", "System.Windows.Forms.Control"), true
+	}
+
+	if key(obj) == "ambient" {
+		t.fail("Ambient.%s is not supported", name)
+	}
+	return value{}, false
+}
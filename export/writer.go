@@ -2,15 +2,15 @@ package export
 
 import (
 	"fmt"
-	"os"
+	"io"
 )
 
 type ExportWriter struct {
-	file   *os.File
+	file   io.Writer
 	indent int
 }
 
-func NewExportWriter(file *os.File) *ExportWriter {
+func NewExportWriter(file io.Writer) *ExportWriter {
 	return &ExportWriter{
 		file: file,
 	}
@@ -18,14 +18,14 @@ func NewExportWriter(file *os.File) *ExportWriter {
 
 func (w *ExportWriter) Write(s string) {
 	for i := 0; i < w.indent; i++ {
-		w.file.WriteString("\t")
+		io.WriteString(w.file, "\t")
 	}
-	w.file.WriteString(s)
-	w.file.WriteString("\n")
+	io.WriteString(w.file, s)
+	io.WriteString(w.file, "\n")
 }
 
 func (w *ExportWriter) Writeln() {
-	w.file.WriteString("\n")
+	io.WriteString(w.file, "\n")
 }
 
 func (w *ExportWriter) WriteIndent(write func()) {
@@ -2,4 +2,4 @@ module github.com/guthius/vb6conv
 
 go 1.23.3
 
-require github.com/spf13/pflag v1.0.5 // indirect
+require github.com/spf13/pflag v1.0.5
@@ -15,12 +15,24 @@ var (
 	project   string
 	namespace string
 	output    string
+	mapping   string
+	dpi       int
+	menuStrip bool
+	anchor    bool
+	framework string
+	platform  string
 )
 
 func main() {
 	pflag.StringVarP(&project, "project", "p", "", "Path to the project file (required)")
 	pflag.StringVarP(&output, "output", "o", "", "Output directory (required)")
 	pflag.StringVarP(&namespace, "namespace", "n", "", "Namespace for the project (optional)")
+	pflag.StringVarP(&mapping, "mapping", "m", "", "Path to a JSON file mapping ActiveX controls to .NET controls (optional)")
+	pflag.IntVar(&dpi, "dpi", 96, "Resolution of the target screen the pixels are calculated for (optional)")
+	pflag.BoolVar(&menuStrip, "menustrip", false, "Convert menus to a MenuStrip instead of a MainMenu (optional)")
+	pflag.BoolVar(&anchor, "anchor", false, "Anchor controls to the edges of the form they are close to (optional)")
+	pflag.StringVar(&framework, "target-framework", export.DefaultTargetFramework, "Target framework of the converted project, such as net48 or net8.0-windows (optional)")
+	pflag.StringVar(&platform, "platform", export.DefaultPlatform, "Platform target of the converted project, such as x86 or AnyCPU (optional)")
 	pflag.Parse()
 
 	if len(project) == 0 {
@@ -35,6 +47,32 @@ func main() {
 		os.Exit(1)
 	}
 
+	if dpi <= 0 {
+		fmt.Fprintln(os.Stderr, "Error:  'dpi' must be a positive number")
+		pflag.Usage()
+		os.Exit(1)
+	}
+	vb6.DPI = dpi
+
+	if err := export.CheckTargetFramework(framework); err != nil {
+		fmt.Fprintln(os.Stderr, "Error: ", err)
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	if err := export.CheckPlatform(platform); err != nil {
+		fmt.Fprintln(os.Stderr, "Error: ", err)
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	if len(mapping) > 0 {
+		if err := export.LoadMapping(mapping); err != nil {
+			fmt.Fprintln(os.Stderr, "Error: ", err)
+			os.Exit(1)
+		}
+	}
+
 	output, err := filepath.Abs(output)
 	if err != nil {
 		panic(err)
@@ -57,24 +95,91 @@ func main() {
 	}
 
 	project := export.ProjectInfo{
-		Name:      vbproj.Name,
-		Namespace: namespace,
-		Output:    output,
+		Name:            vbproj.Name,
+		Namespace:       namespace,
+		Output:          output,
+		TargetFramework: framework,
+		Platform:        platform,
+		Startup:         vbproj.Startup,
+		HelpFile:        vbproj.HelpFile,
+		MenuStrip:       menuStrip,
+		Anchor:          anchor,
+	}
+
+	// User controls are placed on the forms, they are registered first
+	userControls := make([]*vb6.Form, 0, len(vbproj.UserControls))
+	for _, userControl := range vbproj.UserControls {
+		f, err := vb6.Load(userControl)
+		if err != nil {
+			panic(err)
+		}
+
+		project.RegisterUserControl(f)
+		userControls = append(userControls, f)
 	}
 
-	var count int
+	// Forms are used by their name from the code of all the files
+	forms := make([]*vb6.Form, 0, len(vbproj.Forms))
 	for _, form := range vbproj.Forms {
 		f, err := vb6.Load(form)
 		if err != nil {
 			panic(err)
 		}
 
+		project.RegisterForm(f)
+		forms = append(forms, f)
+	}
+
+	modules := make([]*vb6.Module, 0, len(vbproj.Modules))
+	for _, module := range vbproj.Modules {
+		m, err := vb6.LoadModule(module.Filename)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println("Exporting ", m.Name, "as", m.Name+".cs")
+
+		modules = append(modules, m)
+	}
+
+	export.ExportModules(&project, modules)
+
+	// Classes raise events handled through WithEvents variables in the other
+	// classes and forms, they are registered first
+	classes := make([]*vb6.Class, 0, len(vbproj.Classes))
+	for _, class := range vbproj.Classes {
+		c, err := vb6.LoadClass(class.Filename)
+		if err != nil {
+			panic(err)
+		}
+
+		project.RegisterClass(c)
+		classes = append(classes, c)
+	}
+
+	for _, c := range classes {
+		fmt.Println("Exporting ", c.Name, "as", c.Name+".cs")
+
+		export.ExportClass(&project, c)
+	}
+
+	for _, f := range userControls {
 		fmt.Println("Exporting ", f.Root.Name, "as", f.Root.Name+".cs")
 
 		export.Export(&project, f)
+	}
+
+	for _, f := range forms {
+		fmt.Println("Exporting ", f.Root.Name, "as", f.Root.Name+".cs")
+
+		export.Export(&project, f)
+	}
+
+	export.WriteProject(&project)
 
-		count++
+	if err := export.WriteReport(&project); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write report: %v\n", err)
 	}
 
-	fmt.Println("Exported", count, "forms to", output)
+	fmt.Println("Exported", len(forms), "forms,", len(modules), "modules and", len(vbproj.Classes), "classes to", output)
 }
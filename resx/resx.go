@@ -19,6 +19,12 @@ func NewResx() Resx {
 	}
 }
 
+// Icon holds the data of an .ico file, other byte slices are added as bitmaps.
+type Icon []byte
+
+// Binary holds data that is added as a byte array, such as a cursor file.
+type Binary []byte
+
 type resxElem struct {
 	dataType string
 	mimeType string
@@ -30,13 +36,24 @@ type resxImpl struct {
 }
 
 func (res *resxImpl) Add(key string, value any) {
-	switch (value).(type) {
+	switch v := (value).(type) {
 	case []byte:
 		res.entries[key] = resxElem{
 			dataType: "System.Drawing.Bitmap, System.Drawing",
 			mimeType: "application/x-microsoft.net.object.bytearray.base64",
 			value:    value,
 		}
+	case Binary:
+		res.entries[key] = resxElem{
+			dataType: "System.Byte[], mscorlib",
+			value:    []byte(v),
+		}
+	case Icon:
+		res.entries[key] = resxElem{
+			dataType: "System.Drawing.Icon, System.Drawing",
+			mimeType: "application/x-microsoft.net.object.bytearray.base64",
+			value:    []byte(v),
+		}
 	}
 }
 
@@ -116,7 +133,11 @@ func (res *resxImpl) Save(name string) error {
 		return err
 	}
 	for key, elem := range res.entries {
-		_, err = file.WriteString(fmt.Sprintf("\t<data name=\"%s\" type=\"%s\" mimetype=\"%s\">\n", key, elem.dataType, elem.mimeType))
+		if elem.mimeType != "" {
+			_, err = file.WriteString(fmt.Sprintf("\t<data name=\"%s\" type=\"%s\" mimetype=\"%s\">\n", key, elem.dataType, elem.mimeType))
+		} else {
+			_, err = file.WriteString(fmt.Sprintf("\t<data name=\"%s\" type=\"%s\">\n", key, elem.dataType))
+		}
 		if err != nil {
 			return err
 		}
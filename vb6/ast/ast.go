@@ -0,0 +1,574 @@
+package ast
+
+// Span records where a node is located in the source text.
+type Span struct {
+	Line  int // 1-based line number of the first token
+	Start int // byte offset of the first character
+	End   int // byte offset just past the last character
+}
+
+func (s Span) Pos() Span {
+	return s
+}
+
+type Node interface {
+	Pos() Span
+}
+
+// Decl is a module level declaration.
+type Decl interface {
+	Node
+	declNode()
+}
+
+// Stmt is a statement inside a procedure body.
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Expr is an expression.
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// File is the parsed code section of a .frm, .bas, .cls or .ctl file.
+type File struct {
+	Source string
+	Decls  []Decl
+}
+
+// Text returns the source text of a node.
+func (f *File) Text(n Node) string {
+	span := n.Pos()
+	if span.Start < 0 || span.End > len(f.Source) || span.Start > span.End {
+		return ""
+	}
+	return f.Source[span.Start:span.End]
+}
+
+// Procedures returns all Sub, Function and Property procedures in the file.
+func (f *File) Procedures() []*ProcDecl {
+	procs := make([]*ProcDecl, 0)
+	for _, d := range f.Decls {
+		if proc, ok := d.(*ProcDecl); ok {
+			procs = append(procs, proc)
+		}
+	}
+	return procs
+}
+
+// Declarations
+
+type OptionDecl struct {
+	Span
+	Name  string // Explicit, Base, Compare or Private
+	Value string
+}
+
+type AttributeDecl struct {
+	Span
+	Name  string
+	Value string
+}
+
+// TypeRef is the type in an As clause.
+type TypeRef struct {
+	Name   string
+	New    bool // As New Foo
+	Length Expr // fixed length strings, e.g. As String * 20
+}
+
+// Bound is a single array dimension. Lower is nil when only the upper bound
+// is given.
+type Bound struct {
+	Lower Expr
+	Upper Expr
+}
+
+// VarSpec is a single variable in a Dim, ReDim or Type field list.
+type VarSpec struct {
+	Span
+	Name       string
+	WithEvents bool
+	IsArray    bool
+	Bounds     []Bound // empty for dynamic arrays declared as name()
+	Type       *TypeRef
+}
+
+// VarDecl is a variable declaration (Dim, Static, Private, Public or Global).
+// It is both a module level declaration and a statement.
+type VarDecl struct {
+	Span
+	Keyword string
+	Vars    []*VarSpec
+}
+
+type ConstSpec struct {
+	Span
+	Name  string
+	Type  *TypeRef
+	Value Expr
+}
+
+// ConstDecl is a constant declaration. It is both a module level declaration
+// and a statement.
+type ConstDecl struct {
+	Span
+	Visibility string
+	Consts     []*ConstSpec
+}
+
+type Param struct {
+	Span
+	Name       string
+	Optional   bool
+	ByVal      bool
+	ByRef      bool
+	ParamArray bool
+	IsArray    bool
+	Type       *TypeRef
+	Default    Expr
+}
+
+type ProcKind int
+
+const (
+	SubProc ProcKind = iota
+	FunctionProc
+	PropertyGet
+	PropertyLet
+	PropertySet
+)
+
+func (k ProcKind) String() string {
+	switch k {
+	case FunctionProc:
+		return "Function"
+	case PropertyGet:
+		return "Property Get"
+	case PropertyLet:
+		return "Property Let"
+	case PropertySet:
+		return "Property Set"
+	}
+	return "Sub"
+}
+
+// ProcDecl is a Sub, Function or Property procedure.
+type ProcDecl struct {
+	Span
+	Visibility string // Public, Private, Friend or empty
+	Static     bool
+	Kind       ProcKind
+	Name       string
+	Params     []*Param
+	Returns    *TypeRef
+	Body       []Stmt
+}
+
+// DeclareDecl is an external DLL procedure declaration.
+type DeclareDecl struct {
+	Span
+	Visibility string
+	Function   bool
+	Name       string
+	Lib        string
+	Alias      string
+	Params     []*Param
+	Returns    *TypeRef
+}
+
+// TypeDecl is a user defined type (Type ... End Type).
+type TypeDecl struct {
+	Span
+	Visibility string
+	Name       string
+	Fields     []*VarSpec
+}
+
+type EnumMember struct {
+	Span
+	Name  string
+	Value Expr
+}
+
+type EnumDecl struct {
+	Span
+	Visibility string
+	Name       string
+	Members    []*EnumMember
+}
+
+type EventDecl struct {
+	Span
+	Visibility string
+	Name       string
+	Params     []*Param
+}
+
+type ImplementsDecl struct {
+	Span
+	Name string
+}
+
+// Statements
+
+// CommentStmt is a comment. Trailing is set when the comment follows a
+// statement on the same line.
+type CommentStmt struct {
+	Span
+	Text     string
+	Trailing bool
+}
+
+// DirectiveStmt is a conditional compilation line (#If, #Else, #Const...).
+type DirectiveStmt struct {
+	Span
+	Text string
+}
+
+// BadStmt is a statement that could not be parsed.
+type BadStmt struct {
+	Span
+	Err error
+}
+
+type LabelStmt struct {
+	Span
+	Name string
+}
+
+type AssignStmt struct {
+	Span
+	Set    bool
+	Target Expr
+	Value  Expr
+}
+
+// CallStmt is a procedure call used as a statement, with or without the
+// Call keyword.
+type CallStmt struct {
+	Span
+	Call bool
+	Fn   Expr
+	Args []*Arg
+}
+
+type ReDimStmt struct {
+	Span
+	Preserve bool
+	Vars     []*VarSpec
+}
+
+type ElseIf struct {
+	Span
+	Cond Expr
+	Body []Stmt
+}
+
+type IfStmt struct {
+	Span
+	Cond       Expr
+	Then       []Stmt
+	ElseIfs    []*ElseIf
+	Else       []Stmt
+	SingleLine bool
+}
+
+// CaseCond is a single condition in a Case clause: a value (Case 1), a range
+// (Case 1 To 5) or a comparison (Case Is > 5).
+type CaseCond struct {
+	Span
+	Op    string // comparison operator for Case Is
+	Value Expr
+	To    Expr
+}
+
+// CaseClause is a Case block. Conds is nil for Case Else.
+type CaseClause struct {
+	Span
+	Conds []*CaseCond
+	Body  []Stmt
+}
+
+type SelectStmt struct {
+	Span
+	X     Expr
+	Cases []*CaseClause
+}
+
+type ForStmt struct {
+	Span
+	Var  Expr
+	From Expr
+	To   Expr
+	Step Expr
+	Body []Stmt
+}
+
+type ForEachStmt struct {
+	Span
+	Var  Expr
+	In   Expr
+	Body []Stmt
+}
+
+// DoStmt is a Do loop. Until is set for Until conditions and Post when the
+// condition follows Loop instead of Do. Cond is nil for infinite loops.
+type DoStmt struct {
+	Span
+	Cond  Expr
+	Until bool
+	Post  bool
+	Body  []Stmt
+}
+
+type WhileStmt struct {
+	Span
+	Cond Expr
+	Body []Stmt
+}
+
+type WithStmt struct {
+	Span
+	X    Expr
+	Body []Stmt
+}
+
+// ExitStmt is Exit Sub, Exit Function, Exit Property, Exit For or Exit Do.
+type ExitStmt struct {
+	Span
+	Kind string
+}
+
+type GotoStmt struct {
+	Span
+	Label string
+	GoSub bool
+}
+
+type ReturnStmt struct {
+	Span
+}
+
+// OnErrorStmt is On Error GoTo label, On Error GoTo 0 or On Error Resume Next.
+type OnErrorStmt struct {
+	Span
+	ResumeNext bool
+	Label      string
+}
+
+type ResumeStmt struct {
+	Span
+	Next  bool
+	Label string
+}
+
+type EndStmt struct {
+	Span
+}
+
+type StopStmt struct {
+	Span
+}
+
+type EraseStmt struct {
+	Span
+	Vars []Expr
+}
+
+type RaiseEventStmt struct {
+	Span
+	Name string
+	Args []*Arg
+}
+
+// PrintItem is a single expression in a Print statement followed by its
+// separator (";", "," or empty).
+type PrintItem struct {
+	X   Expr
+	Sep string
+}
+
+// PrintStmt is Print, Debug.Print, obj.Print or Print #n.
+type PrintStmt struct {
+	Span
+	Object Expr
+	File   Expr
+	Items  []*PrintItem
+}
+
+// OpenStmt is Open path For mode [Access access] [lock] As #n [Len = n].
+type OpenStmt struct {
+	Span
+	Path   Expr
+	Mode   string
+	Access string
+	Lock   string
+	File   Expr
+	Len    Expr
+}
+
+// FileStmt is one of the remaining file statements: Close, Input, Line Input,
+// Write, Get, Put and Seek.
+type FileStmt struct {
+	Span
+	Op   string
+	File Expr
+	Args []Expr
+}
+
+// Expressions
+
+type Ident struct {
+	Span
+	Name string
+}
+
+type LitKind int
+
+const (
+	IntLit LitKind = iota
+	FloatLit
+	StringLit
+	DateLit
+	BoolLit
+	NothingLit
+	EmptyLit
+	NullLit
+)
+
+// BasicLit is a literal. Value holds the literal text as written, except for
+// strings where it holds the unquoted value.
+type BasicLit struct {
+	Span
+	Kind  LitKind
+	Value string
+}
+
+type MeExpr struct {
+	Span
+}
+
+// MemberExpr is X.Name, or X!Name when Bang is set. X is nil inside a With
+// block (.Name).
+type MemberExpr struct {
+	Span
+	X    Expr
+	Name string
+	Bang bool
+}
+
+// Arg is an argument in a call. Value is nil for omitted arguments.
+type Arg struct {
+	Span
+	Name  string // named arguments, e.g. Buttons:=vbOKOnly
+	ByVal bool
+	Value Expr
+}
+
+// CallExpr is Fn(Args). VB6 uses the same syntax for calls and array indexing.
+type CallExpr struct {
+	Span
+	Fn   Expr
+	Args []*Arg
+}
+
+type ParenExpr struct {
+	Span
+	X Expr
+}
+
+type UnaryExpr struct {
+	Span
+	Op string // "-", "+" or "Not"
+	X  Expr
+}
+
+type BinaryExpr struct {
+	Span
+	Op string // operator text, keywords in their canonical case (And, Mod...)
+	X  Expr
+	Y  Expr
+}
+
+type NewExpr struct {
+	Span
+	Type string
+}
+
+type TypeOfExpr struct {
+	Span
+	X    Expr
+	Type string
+}
+
+type AddressOfExpr struct {
+	Span
+	Name string
+}
+
+// FileNumber is a #n file number argument.
+type FileNumber struct {
+	Span
+	X Expr
+}
+
+func (*OptionDecl) declNode()     {}
+func (*AttributeDecl) declNode()  {}
+func (*VarDecl) declNode()        {}
+func (*ConstDecl) declNode()      {}
+func (*ProcDecl) declNode()       {}
+func (*DeclareDecl) declNode()    {}
+func (*TypeDecl) declNode()       {}
+func (*EnumDecl) declNode()       {}
+func (*EventDecl) declNode()      {}
+func (*ImplementsDecl) declNode() {}
+func (*CommentStmt) declNode()    {}
+func (*DirectiveStmt) declNode()  {}
+func (*BadStmt) declNode()        {}
+
+func (*AttributeDecl) stmtNode()  {}
+func (*VarDecl) stmtNode()        {}
+func (*ConstDecl) stmtNode()      {}
+func (*CommentStmt) stmtNode()    {}
+func (*DirectiveStmt) stmtNode()  {}
+func (*BadStmt) stmtNode()        {}
+func (*LabelStmt) stmtNode()      {}
+func (*AssignStmt) stmtNode()     {}
+func (*CallStmt) stmtNode()       {}
+func (*ReDimStmt) stmtNode()      {}
+func (*IfStmt) stmtNode()         {}
+func (*SelectStmt) stmtNode()     {}
+func (*ForStmt) stmtNode()        {}
+func (*ForEachStmt) stmtNode()    {}
+func (*DoStmt) stmtNode()         {}
+func (*WhileStmt) stmtNode()      {}
+func (*WithStmt) stmtNode()       {}
+func (*ExitStmt) stmtNode()       {}
+func (*GotoStmt) stmtNode()       {}
+func (*ReturnStmt) stmtNode()     {}
+func (*OnErrorStmt) stmtNode()    {}
+func (*ResumeStmt) stmtNode()     {}
+func (*EndStmt) stmtNode()        {}
+func (*StopStmt) stmtNode()       {}
+func (*EraseStmt) stmtNode()      {}
+func (*RaiseEventStmt) stmtNode() {}
+func (*PrintStmt) stmtNode()      {}
+func (*OpenStmt) stmtNode()       {}
+func (*FileStmt) stmtNode()       {}
+
+func (*Ident) exprNode()         {}
+func (*BasicLit) exprNode()      {}
+func (*MeExpr) exprNode()        {}
+func (*MemberExpr) exprNode()    {}
+func (*CallExpr) exprNode()      {}
+func (*ParenExpr) exprNode()     {}
+func (*UnaryExpr) exprNode()     {}
+func (*BinaryExpr) exprNode()    {}
+func (*NewExpr) exprNode()       {}
+func (*TypeOfExpr) exprNode()    {}
+func (*AddressOfExpr) exprNode() {}
+func (*FileNumber) exprNode()    {}
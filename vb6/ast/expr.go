@@ -0,0 +1,281 @@
+package ast
+
+import (
+	"strings"
+)
+
+// binaryLevels lists the binary operators from lowest to highest precedence.
+// The Not operator sits between And and the comparison operators.
+var binaryLevels = [][]string{
+	{"Imp"},
+	{"Eqv"},
+	{"Xor"},
+	{"Or"},
+	{"And"},
+	{"=", "<>", "<", ">", "<=", ">=", "Like", "Is"},
+	{"&"},
+	{"+", "-"},
+	{"Mod"},
+	{"\\"},
+	{"*", "/"},
+}
+
+const notLevel = 5
+
+// operator returns the canonical operator text if the current token is one of
+// the operators in ops.
+func (p *parser) operator(ops []string) (string, bool) {
+	tok := p.tok()
+	for _, op := range ops {
+		if tok.Kind == Word {
+			if strings.EqualFold(tok.Text, op) {
+				return op, true
+			}
+			continue
+		}
+		if tok.Kind != String && tok.Text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseExpr() Expr {
+	return p.parseBinary(0)
+}
+
+func (p *parser) parseBinary(level int) Expr {
+	if level == notLevel && p.is("Not") {
+		start := p.i
+		p.next()
+		x := p.parseBinary(level)
+		return &UnaryExpr{
+			Span: p.spanFrom(start),
+			Op:   "Not",
+			X:    x,
+		}
+	}
+
+	if level >= len(binaryLevels) {
+		return p.parseUnary()
+	}
+
+	start := p.i
+	x := p.parseBinary(level + 1)
+	for {
+		op, ok := p.operator(binaryLevels[level])
+		if !ok {
+			return x
+		}
+		p.next()
+		y := p.parseBinary(level + 1)
+		x = &BinaryExpr{
+			Span: p.spanFrom(start),
+			Op:   op,
+			X:    x,
+			Y:    y,
+		}
+	}
+}
+
+// parseUnary parses unary plus and minus, which bind weaker than ^.
+func (p *parser) parseUnary() Expr {
+	start := p.i
+	switch p.tok().Kind {
+	case Minus, Plus:
+		op := p.next().Text
+		x := p.parseUnary()
+		return &UnaryExpr{
+			Span: p.spanFrom(start),
+			Op:   op,
+			X:    x,
+		}
+	}
+	return p.parsePow()
+}
+
+func (p *parser) parsePow() Expr {
+	start := p.i
+	x := p.parsePostfix()
+	for p.tok().Kind == Pow {
+		p.next()
+		var y Expr
+		if p.tok().Kind == Minus || p.tok().Kind == Plus {
+			y = p.parseUnary()
+		} else {
+			y = p.parsePostfix()
+		}
+		x = &BinaryExpr{
+			Span: p.spanFrom(start),
+			Op:   "^",
+			X:    x,
+			Y:    y,
+		}
+	}
+	return x
+}
+
+// parsePostfix parses a primary expression followed by member accesses and
+// argument lists. The position of the last argument list is remembered so
+// that call statements can re-interpret it.
+func (p *parser) parsePostfix() Expr {
+	start := p.i
+	x := p.parsePrimary()
+	for {
+		switch p.tok().Kind {
+		case Dot, Bang:
+			bang := p.next().Kind == Bang
+			name := p.expectKind(Word).Text
+			x = &MemberExpr{
+				Span: p.spanFrom(start),
+				X:    x,
+				Name: name,
+				Bang: bang,
+			}
+		case LParen:
+			p.lastParen = p.i
+			p.next()
+			args := p.parseArgs(RParen)
+			p.expectKind(RParen)
+			x = &CallExpr{
+				Span: p.spanFrom(start),
+				Fn:   x,
+				Args: args,
+			}
+		default:
+			return x
+		}
+	}
+}
+
+// parseArgs parses a comma separated argument list up to the closing token.
+// Use EOF as the closing token for argument lists that end with the statement.
+func (p *parser) parseArgs(closing Kind) []*Arg {
+	args := make([]*Arg, 0)
+	if p.tok().Kind == closing && closing != EOF {
+		return args
+	}
+
+	saved := p.lastParen
+	defer func() {
+		p.lastParen = saved
+	}()
+
+	for {
+		start := p.i
+		arg := &Arg{}
+		if p.tok().Kind == Word && p.peek(1).Kind == ColonEq {
+			arg.Name = p.next().Text
+			p.next()
+		}
+		arg.ByVal = p.accept("ByVal")
+		if p.tok().Kind != Comma && p.tok().Kind != closing && !p.atEndOfStmt() {
+			arg.Value = p.parseExpr()
+		}
+		arg.Span = p.spanFrom(start)
+		args = append(args, arg)
+		if !p.acceptKind(Comma) {
+			return args
+		}
+	}
+}
+
+func (p *parser) parsePrimary() Expr {
+	start := p.i
+	tok := p.tok()
+
+	lit := func(kind LitKind, value string) Expr {
+		p.next()
+		return &BasicLit{
+			Span:  p.spanFrom(start),
+			Kind:  kind,
+			Value: value,
+		}
+	}
+
+	switch tok.Kind {
+	case Int:
+		return lit(IntLit, tok.Text)
+	case Float:
+		return lit(FloatLit, tok.Text)
+	case String:
+		return lit(StringLit, unquote(tok.Text))
+	case Date:
+		return lit(DateLit, strings.Trim(tok.Text, "#"))
+	case LParen:
+		p.next()
+		x := p.parseExpr()
+		p.expectKind(RParen)
+		return &ParenExpr{
+			Span: p.spanFrom(start),
+			X:    x,
+		}
+	case Dot, Bang:
+		// Member of the object in the enclosing With block
+		bang := p.next().Kind == Bang
+		name := p.expectKind(Word).Text
+		return &MemberExpr{
+			Span: p.spanFrom(start),
+			Name: name,
+			Bang: bang,
+		}
+	case Hash:
+		p.next()
+		x := p.parsePostfix()
+		return &FileNumber{
+			Span: p.spanFrom(start),
+			X:    x,
+		}
+	case Word:
+		switch strings.ToLower(tok.Text) {
+		case "true", "false":
+			return lit(BoolLit, tok.Text)
+		case "nothing":
+			return lit(NothingLit, tok.Text)
+		case "empty":
+			return lit(EmptyLit, tok.Text)
+		case "null":
+			return lit(NullLit, tok.Text)
+		case "me":
+			p.next()
+			return &MeExpr{
+				Span: p.spanFrom(start),
+			}
+		case "new":
+			p.next()
+			name := p.typeName()
+			return &NewExpr{
+				Span: p.spanFrom(start),
+				Type: name,
+			}
+		case "typeof":
+			p.next()
+			x := p.parsePostfix()
+			p.expect("Is")
+			name := p.typeName()
+			return &TypeOfExpr{
+				Span: p.spanFrom(start),
+				X:    x,
+				Type: name,
+			}
+		case "addressof":
+			p.next()
+			name := p.typeName()
+			return &AddressOfExpr{
+				Span: p.spanFrom(start),
+				Name: name,
+			}
+		}
+		if IsReserved(tok.Text) {
+			p.errorf("unexpected %s", p.describe())
+		}
+		p.next()
+		return &Ident{
+			Span: p.spanFrom(start),
+			Name: strings.Trim(tok.Text, "[]"),
+		}
+	}
+
+	p.errorf("expected expression, found %s", p.describe())
+	return nil
+}
@@ -0,0 +1,332 @@
+package ast
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Lexer splits VB6 source text into tokens.
+//
+// Line continuations (" _" at the end of a line) are folded away, so a
+// statement spanning several physical lines produces a single run of tokens
+// terminated by one Newline.
+type Lexer struct {
+	src  string
+	pos  int
+	line int
+	prev Kind
+}
+
+func NewLexer(src string) *Lexer {
+	return &Lexer{
+		src:  src,
+		line: 1,
+		prev: Newline,
+	}
+}
+
+// Tokenize returns all tokens in src, ending with an EOF token.
+func Tokenize(src string) []Token {
+	lexer := NewLexer(src)
+	tokens := make([]Token, 0, len(src)/4)
+	for {
+		tok := lexer.Next()
+		tokens = append(tokens, tok)
+		if tok.Kind == EOF {
+			return tokens
+		}
+	}
+}
+
+var datePattern = regexp.MustCompile(`^\s*(\d{1,4}[/\-]\d{1,2}[/\-]\d{1,4}(\s+\d{1,2}:\d{2}(:\d{2})?(\s*[AaPp][Mm])?)?|\d{1,2}:\d{2}(:\d{2})?(\s*[AaPp][Mm])?)\s*$`)
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func isIdentChar(c byte) bool {
+	return isLetter(c) || isDigit(c) || c == '_'
+}
+
+func (l *Lexer) peek(n int) byte {
+	if l.pos+n < len(l.src) {
+		return l.src[l.pos+n]
+	}
+	return 0
+}
+
+// skipSpace skips blanks and line continuations.
+func (l *Lexer) skipSpace() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\r' && l.peek(1) != '\n' {
+			l.pos++
+			continue
+		}
+		if c == '_' && l.isContinuation() {
+			for l.src[l.pos] != '\n' {
+				l.pos++
+			}
+			l.pos++
+			l.line++
+			continue
+		}
+		break
+	}
+}
+
+// isContinuation reports whether the underscore at the current position is a
+// line continuation, i.e. preceded by a blank and followed only by blanks.
+func (l *Lexer) isContinuation() bool {
+	if l.pos > 0 && l.src[l.pos-1] != ' ' && l.src[l.pos-1] != '\t' {
+		return false
+	}
+	for i := l.pos + 1; i < len(l.src); i++ {
+		switch l.src[i] {
+		case ' ', '\t', '\r':
+			continue
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func (l *Lexer) restOfLine() string {
+	end := strings.IndexByte(l.src[l.pos:], '\n')
+	if end == -1 {
+		end = len(l.src) - l.pos
+	}
+	return strings.TrimRight(l.src[l.pos:l.pos+end], "\r")
+}
+
+func (l *Lexer) token(kind Kind, start int) Token {
+	l.prev = kind
+	return Token{
+		Kind: kind,
+		Text: l.src[start:l.pos],
+		Line: l.line,
+		Pos:  start,
+		End:  l.pos,
+	}
+}
+
+// Next returns the next token.
+func (l *Lexer) Next() Token {
+	l.skipSpace()
+
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return l.token(EOF, start)
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case c == '\n' || c == '\r':
+		if c == '\r' {
+			l.pos++
+		}
+		l.pos++
+		tok := l.token(Newline, start)
+		l.line++
+		return tok
+
+	case c == '\'':
+		l.pos += len(l.restOfLine())
+		return l.token(Comment, start)
+
+	case c == '"':
+		return l.scanString()
+
+	case isLetter(c) || c == '[':
+		return l.scanIdent()
+
+	case isDigit(c) || c == '.' && isDigit(l.peek(1)):
+		return l.scanNumber()
+
+	case c == '&' && (l.peek(1) == 'H' || l.peek(1) == 'h' || l.peek(1) == 'O' || l.peek(1) == 'o') && isHexDigit(l.peek(2)):
+		return l.scanNumber()
+
+	case c == '#':
+		if l.prev == Newline && isLetter(l.peek(1)) {
+			l.pos += len(l.restOfLine())
+			return l.token(Directive, start)
+		}
+		if end := strings.IndexByte(l.restOfLine()[1:], '#'); end != -1 {
+			if datePattern.MatchString(l.src[l.pos+1 : l.pos+1+end]) {
+				l.pos += end + 2
+				return l.token(Date, start)
+			}
+		}
+		l.pos++
+		return l.token(Hash, start)
+	}
+
+	l.pos++
+	switch c {
+	case '(':
+		return l.token(LParen, start)
+	case ')':
+		return l.token(RParen, start)
+	case ',':
+		return l.token(Comma, start)
+	case ';':
+		return l.token(Semicolon, start)
+	case ':':
+		if l.peek(0) == '=' {
+			l.pos++
+			return l.token(ColonEq, start)
+		}
+		return l.token(Colon, start)
+	case '.':
+		return l.token(Dot, start)
+	case '!':
+		return l.token(Bang, start)
+	case '+':
+		return l.token(Plus, start)
+	case '-':
+		return l.token(Minus, start)
+	case '*':
+		return l.token(Mul, start)
+	case '/':
+		return l.token(Div, start)
+	case '\\':
+		return l.token(IntDiv, start)
+	case '^':
+		return l.token(Pow, start)
+	case '&':
+		return l.token(Concat, start)
+	case '=':
+		return l.token(Eq, start)
+	case '<':
+		switch l.peek(0) {
+		case '>':
+			l.pos++
+			return l.token(Neq, start)
+		case '=':
+			l.pos++
+			return l.token(Le, start)
+		}
+		return l.token(Lt, start)
+	case '>':
+		if l.peek(0) == '=' {
+			l.pos++
+			return l.token(Ge, start)
+		}
+		return l.token(Gt, start)
+	}
+
+	return l.token(Illegal, start)
+}
+
+func (l *Lexer) scanString() Token {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '\n' {
+			break
+		}
+		l.pos++
+		if c == '"' {
+			if l.peek(0) != '"' {
+				return l.token(String, start)
+			}
+			l.pos++
+		}
+	}
+	return l.token(Illegal, start)
+}
+
+func (l *Lexer) scanIdent() Token {
+	start := l.pos
+
+	if l.src[l.pos] == '[' {
+		end := strings.IndexByte(l.restOfLine(), ']')
+		if end == -1 {
+			l.pos++
+			return l.token(Illegal, start)
+		}
+		l.pos += end + 1
+		return l.token(Word, start)
+	}
+
+	for l.pos < len(l.src) && isIdentChar(l.src[l.pos]) {
+		l.pos++
+	}
+
+	// A Rem statement comments out the rest of the line
+	if strings.EqualFold(l.src[start:l.pos], "Rem") && (l.prev == Newline || l.prev == Colon) {
+		l.pos += len(l.restOfLine())
+		return l.token(Comment, start)
+	}
+
+	// Type declaration characters, e.g. Left$ or count&. An exclamation mark
+	// followed by a name is a bang operator (rs!Field) rather than a suffix.
+	switch l.peek(0) {
+	case '$', '%', '&', '#', '@':
+		l.pos++
+	case '!':
+		if !isLetter(l.peek(1)) && l.peek(1) != '[' {
+			l.pos++
+		}
+	}
+
+	return l.token(Word, start)
+}
+
+func (l *Lexer) scanNumber() Token {
+	start := l.pos
+	kind := Int
+
+	if l.src[l.pos] == '&' {
+		l.pos += 2
+		for l.pos < len(l.src) && isHexDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	} else {
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+		if l.peek(0) == '.' && isDigit(l.peek(1)) {
+			kind = Float
+			l.pos++
+			for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+				l.pos++
+			}
+		}
+		if c := l.peek(0); c == 'E' || c == 'e' || c == 'D' || c == 'd' {
+			n := 1
+			if l.peek(1) == '+' || l.peek(1) == '-' {
+				n++
+			}
+			if isDigit(l.peek(n)) {
+				kind = Float
+				l.pos += n
+				for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+					l.pos++
+				}
+			}
+		}
+	}
+
+	switch l.peek(0) {
+	case '%', '&':
+		l.pos++
+	case '!', '#', '@':
+		l.pos++
+		kind = Float
+	}
+
+	return l.token(kind, start)
+}
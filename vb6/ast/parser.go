@@ -0,0 +1,672 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is a syntax error at a specific line.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// ErrorList is the list of syntax errors returned by Parse.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// bailout is used to unwind the parser to the nearest statement boundary.
+type bailout struct {
+	err *Error
+}
+
+type parser struct {
+	src        string
+	toks       []Token
+	i          int
+	singleLine int // nesting depth of single line If statements
+	lastParen  int // token index of the most recent argument list
+	nextCount  int // loops still to be closed by the last Next, as in Next j, i
+	errs       ErrorList
+}
+
+// Parse parses the code section of a VB6 source file.
+//
+// The parser recovers from syntax errors: statements it cannot parse are
+// returned as BadStmt nodes so the rest of the file is still usable. The
+// returned error is an ErrorList when any errors were found.
+func Parse(src string) (*File, error) {
+	p := &parser{
+		src:  src,
+		toks: Tokenize(src),
+	}
+
+	file := &File{
+		Source: src,
+		Decls:  make([]Decl, 0),
+	}
+
+	for {
+		p.skipSeparators()
+		tok := p.tok()
+		if tok.Kind == EOF {
+			break
+		}
+		switch tok.Kind {
+		case Comment:
+			file.Decls = append(file.Decls, p.parseComment())
+		case Directive:
+			file.Decls = append(file.Decls, p.parseDirective())
+		default:
+			file.Decls = append(file.Decls, p.parseDeclSafe())
+		}
+	}
+
+	if len(p.errs) > 0 {
+		return file, p.errs
+	}
+	return file, nil
+}
+
+func (p *parser) tok() Token {
+	return p.toks[p.i]
+}
+
+func (p *parser) peek(n int) Token {
+	if p.i+n < len(p.toks) {
+		return p.toks[p.i+n]
+	}
+	return p.toks[len(p.toks)-1]
+}
+
+func (p *parser) next() Token {
+	tok := p.toks[p.i]
+	if tok.Kind != EOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) is(word string) bool {
+	return p.tok().Is(word)
+}
+
+func (p *parser) accept(word string) bool {
+	if p.is(word) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptKind(kind Kind) bool {
+	if p.tok().Kind == kind {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(word string) Token {
+	if !p.is(word) {
+		p.errorf("expected %s, found %s", word, p.describe())
+	}
+	return p.next()
+}
+
+func (p *parser) expectKind(kind Kind) Token {
+	if p.tok().Kind != kind {
+		p.errorf("expected %s, found %s", kind, p.describe())
+	}
+	return p.next()
+}
+
+// ident reads a name that may not be a reserved word.
+func (p *parser) ident() string {
+	tok := p.tok()
+	if tok.Kind != Word || IsReserved(tok.Text) {
+		p.errorf("expected identifier, found %s", p.describe())
+	}
+	p.next()
+	return tok.Text
+}
+
+func (p *parser) describe() string {
+	tok := p.tok()
+	switch tok.Kind {
+	case Word, Int, Float, String, Date:
+		return fmt.Sprintf("'%s'", tok.Text)
+	}
+	return tok.Kind.String()
+}
+
+func (p *parser) error(line int, format string, args ...any) *Error {
+	err := &Error{
+		Line: line,
+		Msg:  fmt.Sprintf(format, args...),
+	}
+	p.errs = append(p.errs, err)
+	return err
+}
+
+func (p *parser) errorf(format string, args ...any) {
+	panic(bailout{p.error(p.tok().Line, format, args...)})
+}
+
+// spanFrom returns the span from the token at index start up to the last
+// consumed token.
+func (p *parser) spanFrom(start int) Span {
+	first := p.toks[start]
+	end := first.Pos
+	for i := p.i - 1; i >= start; i-- {
+		if p.toks[i].Kind != Newline {
+			end = p.toks[i].End
+			break
+		}
+	}
+	return Span{
+		Line:  first.Line,
+		Start: first.Pos,
+		End:   end,
+	}
+}
+
+func (p *parser) atLineStart() bool {
+	return p.i == 0 || p.toks[p.i-1].Kind == Newline
+}
+
+// atLineEnd reports whether the current token ends the physical line.
+func (p *parser) atLineEnd() bool {
+	switch p.tok().Kind {
+	case Newline, Comment, EOF:
+		return true
+	}
+	return false
+}
+
+// atEndOfStmt reports whether the current token ends a statement.
+func (p *parser) atEndOfStmt() bool {
+	if p.atLineEnd() || p.tok().Kind == Colon {
+		return true
+	}
+	return p.singleLine > 0 && p.is("Else")
+}
+
+func (p *parser) endStmt() {
+	if !p.atEndOfStmt() {
+		p.errorf("unexpected %s", p.describe())
+	}
+}
+
+// skipLine skips the remaining tokens on the current line.
+func (p *parser) skipLine() {
+	for {
+		switch p.tok().Kind {
+		case Newline, EOF:
+			return
+		}
+		p.next()
+	}
+}
+
+func (p *parser) skipSeparators() {
+	for p.tok().Kind == Newline || p.tok().Kind == Colon {
+		p.next()
+	}
+}
+
+func (p *parser) parseComment() *CommentStmt {
+	start := p.i
+	tok := p.next()
+	text := tok.Text
+	if strings.HasPrefix(text, "'") {
+		text = text[1:]
+	} else {
+		text = strings.TrimPrefix(text[3:], " ")
+	}
+	return &CommentStmt{
+		Span:     p.spanFrom(start),
+		Text:     text,
+		Trailing: start > 0 && p.toks[start-1].Kind != Newline,
+	}
+}
+
+func (p *parser) parseDirective() *DirectiveStmt {
+	start := p.i
+	tok := p.next()
+	return &DirectiveStmt{
+		Span: p.spanFrom(start),
+		Text: tok.Text,
+	}
+}
+
+// recoverBad turns a bailout into a BadStmt covering the failed line.
+func (p *parser) recoverBad(start int, r any) *BadStmt {
+	b, ok := r.(bailout)
+	if !ok {
+		panic(r)
+	}
+	p.skipLine()
+	return &BadStmt{
+		Span: p.spanFrom(start),
+		Err:  b.err,
+	}
+}
+
+func (p *parser) parseDeclSafe() (decl Decl) {
+	start := p.i
+	defer func() {
+		if r := recover(); r != nil {
+			decl = p.recoverBad(start, r)
+		}
+	}()
+	decl = p.parseDecl()
+	p.endStmt()
+	return decl
+}
+
+func (p *parser) parseDecl() Decl {
+	start := p.i
+
+	switch {
+	case p.is("Option"):
+		return p.parseOption()
+	case p.is("Attribute"):
+		return p.parseAttribute()
+	case p.is("Implements"):
+		p.next()
+		name := p.typeName()
+		return &ImplementsDecl{
+			Span: p.spanFrom(start),
+			Name: name,
+		}
+	case p.is("Dim"):
+		p.next()
+		return p.parseVarDecl(start, "Dim")
+	}
+
+	var visibility string
+	for _, word := range []string{"Public", "Private", "Friend", "Global"} {
+		if p.is(word) {
+			visibility = word
+			p.next()
+			break
+		}
+	}
+
+	static := p.accept("Static")
+
+	switch {
+	case p.is("Sub"), p.is("Function"), p.is("Property"):
+		return p.parseProc(start, visibility, static)
+	case p.is("Declare"):
+		return p.parseDeclare(start, visibility)
+	case p.is("Type"):
+		return p.parseType(start, visibility)
+	case p.is("Enum"):
+		return p.parseEnum(start, visibility)
+	case p.is("Const"):
+		p.next()
+		return p.parseConst(start, visibility)
+	case p.is("Event"):
+		p.next()
+		return p.parseEvent(start, visibility)
+	}
+
+	if static {
+		return p.parseVarDecl(start, "Static")
+	}
+	if visibility != "" {
+		return p.parseVarDecl(start, visibility)
+	}
+
+	p.errorf("unexpected %s", p.describe())
+	return nil
+}
+
+func (p *parser) parseOption() *OptionDecl {
+	start := p.i
+	p.expect("Option")
+	decl := &OptionDecl{
+		Name: p.expectKind(Word).Text,
+	}
+	if !p.atEndOfStmt() {
+		decl.Value = p.next().Text
+	}
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+func (p *parser) parseAttribute() *AttributeDecl {
+	start := p.i
+	p.expect("Attribute")
+
+	name := strings.Builder{}
+	for !p.atEndOfStmt() && p.tok().Kind != Eq {
+		name.WriteString(p.next().Text)
+	}
+	p.expectKind(Eq)
+
+	first := p.tok()
+	p.skipLine()
+	last := p.toks[p.i-1]
+
+	return &AttributeDecl{
+		Span:  p.spanFrom(start),
+		Name:  name.String(),
+		Value: strings.TrimSpace(p.src[first.Pos:last.End]),
+	}
+}
+
+// typeName reads a possibly qualified type name such as ADODB.Recordset.
+func (p *parser) typeName() string {
+	name := p.expectKind(Word).Text
+	for p.tok().Kind == Dot {
+		p.next()
+		name += "." + p.expectKind(Word).Text
+	}
+	return name
+}
+
+func (p *parser) parseTypeRef() *TypeRef {
+	ref := &TypeRef{
+		New: p.accept("New"),
+	}
+	ref.Name = p.typeName()
+	if p.acceptKind(Mul) {
+		ref.Length = p.parsePrimary()
+	}
+	return ref
+}
+
+func (p *parser) parseBounds() []Bound {
+	bounds := make([]Bound, 0)
+	if p.tok().Kind == RParen {
+		return bounds
+	}
+	for {
+		bound := Bound{
+			Upper: p.parseExpr(),
+		}
+		if p.accept("To") {
+			bound.Lower = bound.Upper
+			bound.Upper = p.parseExpr()
+		}
+		bounds = append(bounds, bound)
+		if !p.acceptKind(Comma) {
+			return bounds
+		}
+	}
+}
+
+func (p *parser) parseVarSpec() *VarSpec {
+	start := p.i
+	spec := &VarSpec{
+		WithEvents: p.accept("WithEvents"),
+	}
+	spec.Name = p.ident()
+	if p.acceptKind(LParen) {
+		spec.IsArray = true
+		spec.Bounds = p.parseBounds()
+		p.expectKind(RParen)
+	}
+	if p.accept("As") {
+		spec.Type = p.parseTypeRef()
+	}
+	spec.Span = p.spanFrom(start)
+	return spec
+}
+
+func (p *parser) parseVarSpecs() []*VarSpec {
+	vars := []*VarSpec{p.parseVarSpec()}
+	for p.acceptKind(Comma) {
+		vars = append(vars, p.parseVarSpec())
+	}
+	return vars
+}
+
+func (p *parser) parseVarDecl(start int, keyword string) *VarDecl {
+	vars := p.parseVarSpecs()
+	return &VarDecl{
+		Span:    p.spanFrom(start),
+		Keyword: keyword,
+		Vars:    vars,
+	}
+}
+
+func (p *parser) parseConst(start int, visibility string) *ConstDecl {
+	decl := &ConstDecl{
+		Visibility: visibility,
+		Consts:     make([]*ConstSpec, 0),
+	}
+	for {
+		specStart := p.i
+		spec := &ConstSpec{
+			Name: p.ident(),
+		}
+		if p.accept("As") {
+			spec.Type = p.parseTypeRef()
+		}
+		p.expectKind(Eq)
+		spec.Value = p.parseExpr()
+		spec.Span = p.spanFrom(specStart)
+		decl.Consts = append(decl.Consts, spec)
+		if !p.acceptKind(Comma) {
+			break
+		}
+	}
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+func (p *parser) parseParam() *Param {
+	start := p.i
+	param := &Param{}
+	param.Optional = p.accept("Optional")
+	switch {
+	case p.accept("ByVal"):
+		param.ByVal = true
+	case p.accept("ByRef"):
+		param.ByRef = true
+	}
+	param.ParamArray = p.accept("ParamArray")
+	param.Name = p.ident()
+	if p.acceptKind(LParen) {
+		p.expectKind(RParen)
+		param.IsArray = true
+	}
+	if p.accept("As") {
+		param.Type = p.parseTypeRef()
+	}
+	if p.acceptKind(Eq) {
+		param.Default = p.parseExpr()
+	}
+	param.Span = p.spanFrom(start)
+	return param
+}
+
+func (p *parser) parseParams() []*Param {
+	params := make([]*Param, 0)
+	if !p.acceptKind(LParen) {
+		return params
+	}
+	if p.acceptKind(RParen) {
+		return params
+	}
+	for {
+		params = append(params, p.parseParam())
+		if !p.acceptKind(Comma) {
+			break
+		}
+	}
+	p.expectKind(RParen)
+	return params
+}
+
+var procEnds = map[ProcKind]string{
+	SubProc:      "End Sub",
+	FunctionProc: "End Function",
+	PropertyGet:  "End Property",
+	PropertyLet:  "End Property",
+	PropertySet:  "End Property",
+}
+
+func (p *parser) parseProc(start int, visibility string, static bool) *ProcDecl {
+	proc := &ProcDecl{
+		Visibility: visibility,
+		Static:     static,
+	}
+
+	switch {
+	case p.accept("Sub"):
+		proc.Kind = SubProc
+	case p.accept("Function"):
+		proc.Kind = FunctionProc
+	case p.accept("Property"):
+		switch {
+		case p.accept("Get"):
+			proc.Kind = PropertyGet
+		case p.accept("Let"):
+			proc.Kind = PropertyLet
+		case p.accept("Set"):
+			proc.Kind = PropertySet
+		default:
+			p.errorf("expected Get, Let or Set, found %s", p.describe())
+		}
+	}
+
+	proc.Name = p.ident()
+	proc.Params = p.parseParams()
+	if p.accept("As") {
+		proc.Returns = p.parseTypeRef()
+	}
+	p.endStmt()
+
+	line := p.toks[start].Line
+	end := procEnds[proc.Kind]
+	body, term := p.parseBlock(end)
+	proc.Body = body
+	if term == "" {
+		p.error(line, "missing %s", end)
+	} else {
+		p.consumeTerm(term)
+	}
+
+	proc.Span = p.spanFrom(start)
+	return proc
+}
+
+func (p *parser) parseDeclare(start int, visibility string) *DeclareDecl {
+	p.expect("Declare")
+	decl := &DeclareDecl{
+		Visibility: visibility,
+	}
+	switch {
+	case p.accept("Function"):
+		decl.Function = true
+	case p.accept("Sub"):
+	default:
+		p.errorf("expected Sub or Function, found %s", p.describe())
+	}
+	decl.Name = p.ident()
+	p.expect("Lib")
+	decl.Lib = unquote(p.expectKind(String).Text)
+	if p.accept("Alias") {
+		decl.Alias = unquote(p.expectKind(String).Text)
+	}
+	decl.Params = p.parseParams()
+	if p.accept("As") {
+		decl.Returns = p.parseTypeRef()
+	}
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+// parseMembers reads the lines of a Type or Enum block up to the terminator.
+func (p *parser) parseMembers(term string, member func()) {
+	line := p.tok().Line
+	for {
+		p.skipSeparators()
+		switch p.tok().Kind {
+		case EOF:
+			p.error(line, "missing %s", term)
+			return
+		case Comment, Directive:
+			p.next()
+			continue
+		}
+		if p.matchTerm([]string{term}) != "" {
+			p.consumeTerm(term)
+			return
+		}
+		member()
+		p.endStmt()
+	}
+}
+
+func (p *parser) parseType(start int, visibility string) *TypeDecl {
+	p.expect("Type")
+	decl := &TypeDecl{
+		Visibility: visibility,
+		Name:       p.ident(),
+		Fields:     make([]*VarSpec, 0),
+	}
+	p.endStmt()
+	p.parseMembers("End Type", func() {
+		decl.Fields = append(decl.Fields, p.parseVarSpec())
+	})
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+func (p *parser) parseEnum(start int, visibility string) *EnumDecl {
+	p.expect("Enum")
+	decl := &EnumDecl{
+		Visibility: visibility,
+		Name:       p.ident(),
+		Members:    make([]*EnumMember, 0),
+	}
+	p.endStmt()
+	p.parseMembers("End Enum", func() {
+		memberStart := p.i
+		member := &EnumMember{
+			Name: p.expectKind(Word).Text,
+		}
+		if p.acceptKind(Eq) {
+			member.Value = p.parseExpr()
+		}
+		member.Span = p.spanFrom(memberStart)
+		decl.Members = append(decl.Members, member)
+	})
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+func (p *parser) parseEvent(start int, visibility string) *EventDecl {
+	decl := &EventDecl{
+		Visibility: visibility,
+		Name:       p.ident(),
+	}
+	decl.Params = p.parseParams()
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+func unquote(s string) string {
+	s = strings.TrimPrefix(s, "\"")
+	s = strings.TrimSuffix(s, "\"")
+	return strings.ReplaceAll(s, "\"\"", "\"")
+}
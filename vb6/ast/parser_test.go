@@ -0,0 +1,231 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+)
+
+// parseBody parses the statements of a Sub wrapped around src.
+func parseBody(t *testing.T, src string) ([]Stmt, error) {
+	t.Helper()
+	file, err := Parse("Sub Test()\n" + src + "\nEnd Sub\n")
+	for _, d := range file.Decls {
+		if proc, ok := d.(*ProcDecl); ok {
+			return proc.Body, err
+		}
+	}
+	t.Fatalf("no procedure parsed from %q", src)
+	return nil, err
+}
+
+func TestParseStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		check func(t *testing.T, body []Stmt)
+	}{
+		{
+			name: "line continuation",
+			src:  "x = 1 + _\n    2 + _\n    3",
+			check: func(t *testing.T, body []Stmt) {
+				assign := stmtAt[*AssignStmt](t, body, 0)
+				if _, ok := assign.Value.(*BinaryExpr); !ok {
+					t.Errorf("value is %T, want *BinaryExpr", assign.Value)
+				}
+				wantLen(t, body, 1)
+			},
+		},
+		{
+			name: "single line If",
+			src:  "If x Then y = 1 Else y = 2\nz = 3",
+			check: func(t *testing.T, body []Stmt) {
+				wantLen(t, body, 2)
+				stmt := stmtAt[*IfStmt](t, body, 0)
+				if !stmt.SingleLine {
+					t.Error("If is not single line")
+				}
+				if len(stmt.Then) != 1 || len(stmt.Else) != 1 {
+					t.Errorf("got %d Then and %d Else statements, want 1 and 1", len(stmt.Then), len(stmt.Else))
+				}
+			},
+		},
+		{
+			name: "single line If with several statements",
+			src:  "If x Then y = 1: z = 2",
+			check: func(t *testing.T, body []Stmt) {
+				wantLen(t, body, 1)
+				if stmt := stmtAt[*IfStmt](t, body, 0); len(stmt.Then) != 2 {
+					t.Errorf("got %d Then statements, want 2", len(stmt.Then))
+				}
+			},
+		},
+		{
+			name: "Next closing two loops",
+			src:  "For i = 1 To 3\n    For j = 1 To 3\n        x = i * j\nNext j, i\ny = 1",
+			check: func(t *testing.T, body []Stmt) {
+				wantLen(t, body, 2)
+				outer := stmtAt[*ForStmt](t, body, 0)
+				inner := stmtAt[*ForStmt](t, outer.Body, 0)
+				wantLen(t, outer.Body, 1)
+				wantLen(t, inner.Body, 1)
+				stmtAt[*AssignStmt](t, body, 1)
+			},
+		},
+		{
+			name: "Next closing three loops",
+			src:  "For i = 1 To 3\nFor j = 1 To 3\nFor k = 1 To 3\nx = 1\nNext k, j, i",
+			check: func(t *testing.T, body []Stmt) {
+				wantLen(t, body, 1)
+				outer := stmtAt[*ForStmt](t, body, 0)
+				middle := stmtAt[*ForStmt](t, outer.Body, 0)
+				inner := stmtAt[*ForStmt](t, middle.Body, 0)
+				stmtAt[*AssignStmt](t, inner.Body, 0)
+			},
+		},
+		{
+			name: "Next closing the inner loop only",
+			src:  "For i = 1 To 3\nFor j = 1 To 3\nNext j\nx = 1\nNext",
+			check: func(t *testing.T, body []Stmt) {
+				wantLen(t, body, 1)
+				outer := stmtAt[*ForStmt](t, body, 0)
+				wantLen(t, outer.Body, 2)
+			},
+		},
+		{
+			name: "With block",
+			src:  "With txtName\n    .Text = \"a\"\n    .SetFocus\nEnd With",
+			check: func(t *testing.T, body []Stmt) {
+				with := stmtAt[*WithStmt](t, body, 0)
+				wantLen(t, with.Body, 2)
+				assign := stmtAt[*AssignStmt](t, with.Body, 0)
+				if member, ok := assign.Target.(*MemberExpr); !ok || member.X != nil || member.Name != "Text" {
+					t.Errorf("target is %#v, want .Text", assign.Target)
+				}
+			},
+		},
+		{
+			name: "type suffixes",
+			src:  "s$ = Left$(name$, 2)",
+			check: func(t *testing.T, body []Stmt) {
+				assign := stmtAt[*AssignStmt](t, body, 0)
+				if id, ok := assign.Target.(*Ident); !ok || id.Name != "s$" {
+					t.Errorf("target is %#v, want s$", assign.Target)
+				}
+				call, ok := assign.Value.(*CallExpr)
+				if !ok {
+					t.Fatalf("value is %T, want *CallExpr", assign.Value)
+				}
+				if id, ok := call.Fn.(*Ident); !ok || id.Name != "Left$" {
+					t.Errorf("function is %#v, want Left$", call.Fn)
+				}
+			},
+		},
+		{
+			name: "hexadecimal literal",
+			src:  "x = &HFF00&",
+			check: func(t *testing.T, body []Stmt) {
+				assign := stmtAt[*AssignStmt](t, body, 0)
+				lit, ok := assign.Value.(*BasicLit)
+				if !ok || lit.Kind != IntLit || lit.Value != "&HFF00&" {
+					t.Errorf("value is %#v, want integer &HFF00&", assign.Value)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := parseBody(t, test.src)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			test.check(t, body)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+		good int // statements parsed besides the bad one
+	}{
+		{"missing expression", "x = \ny = 1", "line 2", 1},
+		{"unexpected End If", "End If\ny = 1", "unexpected End If", 1},
+		{"Next closing too many loops", "For i = 1 To 3\nNext i, j\ny = 1", "Next closes more loops", 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := parseBody(t, test.src)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("got error %v, want %q", err, test.err)
+			}
+			good := 0
+			for _, stmt := range body {
+				if _, ok := stmt.(*BadStmt); !ok {
+					good++
+				}
+			}
+			if good != test.good {
+				t.Errorf("got %d good statements, want %d", good, test.good)
+			}
+			if _, ok := body[len(body)-1].(*AssignStmt); !ok {
+				t.Errorf("last statement is %T, want *AssignStmt after recovering", body[len(body)-1])
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []Kind
+	}{
+		{"x% = &H1F", []Kind{Word, Eq, Int}},
+		{"rs!Name", []Kind{Word, Bang, Word}},
+		{"a = b _\n + 1", []Kind{Word, Eq, Word, Plus, Int}},
+		{"x = 1.5# ' done", []Kind{Word, Eq, Float, Comment}},
+	}
+
+	for _, test := range tests {
+		got := make([]Kind, 0)
+		for _, tok := range Tokenize(test.src) {
+			if tok.Kind != EOF && tok.Kind != Newline {
+				got = append(got, tok.Kind)
+			}
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%q: got %v, want %v", test.src, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%q: got %v, want %v", test.src, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func stmtAt[T Stmt](t *testing.T, body []Stmt, i int) T {
+	t.Helper()
+	var zero T
+	if i >= len(body) {
+		t.Fatalf("got %d statements, want at least %d", len(body), i+1)
+		return zero
+	}
+	stmt, ok := body[i].(T)
+	if !ok {
+		t.Fatalf("statement %d is %T, want %T", i, body[i], zero)
+	}
+	return stmt
+}
+
+func wantLen(t *testing.T, body []Stmt, n int) {
+	t.Helper()
+	if len(body) != n {
+		t.Errorf("got %d statements, want %d", len(body), n)
+	}
+}
@@ -0,0 +1,714 @@
+package ast
+
+import (
+	"slices"
+	"strings"
+)
+
+// blockEnds contains the words that close or continue a statement block. They
+// are never parsed as statements themselves.
+var blockEnds = []string{
+	"End If", "End Select", "End With", "End Type", "End Enum",
+	"Else", "ElseIf", "Case", "Next", "Loop", "Wend",
+}
+
+// matchTerm returns the first terminator in terms found at the current token.
+func (p *parser) matchTerm(terms []string) string {
+	for _, term := range terms {
+		words := strings.Split(term, " ")
+		match := true
+		for i, word := range words {
+			if !p.peek(i).Is(word) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return term
+		}
+	}
+	return ""
+}
+
+func (p *parser) consumeTerm(term string) {
+	for range strings.Split(term, " ") {
+		p.next()
+	}
+}
+
+// parseBlock parses statements until one of the terminators is found and
+// returns the statements together with the terminator, which is left
+// unconsumed. The returned terminator is empty when the block ended without
+// one, either at the end of the file or at the end of the enclosing procedure.
+func (p *parser) parseBlock(terms ...string) ([]Stmt, string) {
+	body := make([]Stmt, 0)
+	for {
+		// A Next closing several loops also closes the loop of this block
+		if p.nextCount > 0 {
+			if slices.Contains(terms, "Next") {
+				return body, "Next"
+			}
+			p.error(p.tok().Line, "Next closes more loops than are open")
+			p.nextCount = 0
+		}
+
+		p.skipSeparators()
+
+		switch p.tok().Kind {
+		case EOF:
+			return body, ""
+		case Comment:
+			body = append(body, p.parseComment())
+			continue
+		case Directive:
+			body = append(body, p.parseDirective())
+			continue
+		}
+
+		if term := p.matchTerm(terms); term != "" {
+			return body, term
+		}
+
+		if p.matchTerm([]string{"End Sub", "End Function", "End Property"}) != "" {
+			return body, ""
+		}
+
+		if term := p.matchTerm(blockEnds); term != "" {
+			start := p.i
+			err := p.error(p.tok().Line, "unexpected %s", term)
+			p.skipLine()
+			body = append(body, &BadStmt{
+				Span: p.spanFrom(start),
+				Err:  err,
+			})
+			continue
+		}
+
+		body = append(body, p.parseStmtSafe())
+	}
+}
+
+// parseBody parses a block that must end with term and consumes the
+// terminator.
+func (p *parser) parseBody(line int, term string) []Stmt {
+	body, found := p.parseBlock(term)
+	if found == "" {
+		p.error(line, "missing %s", term)
+	} else {
+		p.consumeTerm(found)
+	}
+	return body
+}
+
+func (p *parser) parseStmtSafe() (stmt Stmt) {
+	start := p.i
+	defer func() {
+		if r := recover(); r != nil {
+			stmt = p.recoverBad(start, r)
+		}
+	}()
+	stmt = p.parseStmt()
+	if _, ok := stmt.(*LabelStmt); !ok {
+		p.endStmt()
+	}
+	return stmt
+}
+
+func (p *parser) parseStmt() Stmt {
+	start := p.i
+	tok := p.tok()
+
+	// Line numbers and labels
+	if p.atLineStart() {
+		if tok.Kind == Int {
+			p.next()
+			return &LabelStmt{
+				Span: p.spanFrom(start),
+				Name: tok.Text,
+			}
+		}
+		if tok.Kind == Word && p.peek(1).Kind == Colon && !IsReserved(tok.Text) {
+			p.next()
+			return &LabelStmt{
+				Span: p.spanFrom(start),
+				Name: tok.Text,
+			}
+		}
+	}
+
+	if tok.Kind != Word {
+		return p.parseSimpleStmt()
+	}
+
+	switch strings.ToLower(tok.Text) {
+	case "dim", "static", "private", "public", "global":
+		p.next()
+		return p.parseVarDecl(start, tok.Text)
+	case "const":
+		p.next()
+		return p.parseConst(start, "")
+	case "redim":
+		return p.parseReDim()
+	case "set", "let":
+		p.next()
+		stmt := p.parseSimpleStmt()
+		assign, ok := stmt.(*AssignStmt)
+		if !ok {
+			p.errorf("expected assignment after %s", tok.Text)
+		}
+		assign.Set = strings.EqualFold(tok.Text, "set")
+		assign.Span = p.spanFrom(start)
+		return assign
+	case "if":
+		return p.parseIf()
+	case "for":
+		return p.parseFor()
+	case "do":
+		return p.parseDo()
+	case "while":
+		return p.parseWhile()
+	case "select":
+		return p.parseSelect()
+	case "with":
+		return p.parseWith()
+	case "exit":
+		p.next()
+		kind := p.expectKind(Word).Text
+		return &ExitStmt{
+			Span: p.spanFrom(start),
+			Kind: kind,
+		}
+	case "goto", "gosub":
+		p.next()
+		label := p.next().Text
+		return &GotoStmt{
+			Span:  p.spanFrom(start),
+			Label: label,
+			GoSub: strings.EqualFold(tok.Text, "gosub"),
+		}
+	case "return":
+		p.next()
+		return &ReturnStmt{
+			Span: p.spanFrom(start),
+		}
+	case "on":
+		return p.parseOnError()
+	case "resume":
+		p.next()
+		stmt := &ResumeStmt{}
+		if p.accept("Next") {
+			stmt.Next = true
+		} else if !p.atEndOfStmt() {
+			stmt.Label = p.next().Text
+		}
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case "call":
+		p.next()
+		stmt := p.parseSimpleStmt()
+		call, ok := stmt.(*CallStmt)
+		if !ok {
+			p.errorf("expected procedure call after Call")
+		}
+		call.Call = true
+		call.Span = p.spanFrom(start)
+		return call
+	case "end":
+		p.next()
+		return &EndStmt{
+			Span: p.spanFrom(start),
+		}
+	case "stop":
+		p.next()
+		return &StopStmt{
+			Span: p.spanFrom(start),
+		}
+	case "erase":
+		p.next()
+		stmt := &EraseStmt{
+			Vars: []Expr{p.parseExpr()},
+		}
+		for p.acceptKind(Comma) {
+			stmt.Vars = append(stmt.Vars, p.parseExpr())
+		}
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case "raiseevent":
+		p.next()
+		stmt := &RaiseEventStmt{
+			Name: p.ident(),
+		}
+		if p.acceptKind(LParen) {
+			stmt.Args = p.parseArgs(RParen)
+			p.expectKind(RParen)
+		}
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case "attribute":
+		return p.parseAttribute()
+	case "print":
+		p.next()
+		return p.parsePrint(start, nil)
+	case "open":
+		if p.lineHasWord("For") {
+			return p.parseOpen()
+		}
+	case "close":
+		return p.parseFileStmt("Close", 1)
+	case "input", "write", "get", "put", "seek":
+		if p.peek(1).Kind == Hash {
+			return p.parseFileStmt(tok.Text, 1)
+		}
+	case "line":
+		if p.peek(1).Is("Input") {
+			return p.parseFileStmt("Line Input", 2)
+		}
+	}
+
+	return p.parseSimpleStmt()
+}
+
+// lineHasWord reports whether word appears in the rest of the statement.
+func (p *parser) lineHasWord(word string) bool {
+	for i := p.i; p.toks[i].Kind != EOF; i++ {
+		switch p.toks[i].Kind {
+		case Newline, Colon, Comment:
+			return false
+		}
+		if p.toks[i].Is(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSimpleStmt parses an assignment or a procedure call.
+func (p *parser) parseSimpleStmt() Stmt {
+	start := p.i
+
+	p.lastParen = -1
+	target := p.parsePostfix()
+
+	if p.acceptKind(Eq) {
+		value := p.parseExpr()
+		return &AssignStmt{
+			Span:   p.spanFrom(start),
+			Target: target,
+			Value:  value,
+		}
+	}
+
+	switch fn := target.(type) {
+	case *Ident:
+		if strings.EqualFold(fn.Name, "Print") {
+			return p.parsePrint(start, nil)
+		}
+	case *MemberExpr:
+		if strings.EqualFold(fn.Name, "Print") && !fn.Bang {
+			return p.parsePrint(start, fn.X)
+		}
+	case *CallExpr:
+		if m, ok := fn.Fn.(*MemberExpr); ok && strings.EqualFold(m.Name, "Print") && p.lastParen != -1 {
+			p.i = p.lastParen
+			return p.parsePrint(start, m.X)
+		}
+		if p.atEndOfStmt() {
+			return &CallStmt{
+				Span: p.spanFrom(start),
+				Fn:   fn.Fn,
+				Args: fn.Args,
+			}
+		}
+
+		// Foo (a), b and Foo (a) + 1 pass a parenthesized expression as the
+		// first argument rather than calling Foo with an argument list.
+		if p.lastParen != -1 {
+			p.i = p.lastParen
+			target = fn.Fn
+		}
+	case *BasicLit, *ParenExpr, *UnaryExpr, *BinaryExpr, *NewExpr, *TypeOfExpr, *AddressOfExpr, *FileNumber:
+		p.errorf("expected statement")
+	}
+
+	args := make([]*Arg, 0)
+	if !p.atEndOfStmt() {
+		args = p.parseArgs(EOF)
+	}
+
+	return &CallStmt{
+		Span: p.spanFrom(start),
+		Fn:   target,
+		Args: args,
+	}
+}
+
+func (p *parser) parseReDim() *ReDimStmt {
+	start := p.i
+	p.expect("ReDim")
+	stmt := &ReDimStmt{
+		Preserve: p.accept("Preserve"),
+	}
+	stmt.Vars = p.parseVarSpecs()
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+// parseInline parses the colon separated statements of a single line If.
+func (p *parser) parseInline() []Stmt {
+	body := make([]Stmt, 0)
+	for {
+		if p.tok().Kind == Int {
+			// If x Then 100 is shorthand for If x Then GoTo 100
+			start := p.i
+			label := p.next().Text
+			body = append(body, &GotoStmt{
+				Span:  p.spanFrom(start),
+				Label: label,
+			})
+		} else {
+			body = append(body, p.parseStmt())
+		}
+		p.endStmt()
+		if !p.acceptKind(Colon) || p.atLineEnd() || p.is("Else") {
+			return body
+		}
+	}
+}
+
+func (p *parser) parseIf() *IfStmt {
+	start := p.i
+	line := p.tok().Line
+	p.expect("If")
+	stmt := &IfStmt{
+		Cond:    p.parseExpr(),
+		ElseIfs: make([]*ElseIf, 0),
+	}
+	p.expect("Then")
+
+	if !p.atLineEnd() {
+		p.singleLine++
+		defer func() {
+			p.singleLine--
+		}()
+		stmt.SingleLine = true
+		stmt.Then = p.parseInline()
+		if p.accept("Else") {
+			stmt.Else = p.parseInline()
+		}
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	}
+
+	// Nested blocks are never single line, even inside a single line If
+	saved := p.singleLine
+	p.singleLine = 0
+	defer func() {
+		p.singleLine = saved
+	}()
+
+	body, term := p.parseBlock("ElseIf", "Else", "End If")
+	stmt.Then = body
+	for term == "ElseIf" {
+		elseStart := p.i
+		p.next()
+		elseIf := &ElseIf{
+			Cond: p.parseExpr(),
+		}
+		p.expect("Then")
+		elseIf.Body, term = p.parseBlock("ElseIf", "Else", "End If")
+		elseIf.Span = p.spanFrom(elseStart)
+		stmt.ElseIfs = append(stmt.ElseIfs, elseIf)
+	}
+	if term == "Else" {
+		p.next()
+		stmt.Else, term = p.parseBlock("End If")
+	}
+	if term == "" {
+		p.error(line, "missing End If")
+	} else {
+		p.consumeTerm(term)
+	}
+
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *parser) parseFor() Stmt {
+	start := p.i
+	line := p.tok().Line
+	p.expect("For")
+
+	if p.accept("Each") {
+		stmt := &ForEachStmt{
+			Var: p.parsePostfix(),
+		}
+		p.expect("In")
+		stmt.In = p.parseExpr()
+		stmt.Body = p.parseNext(line)
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	}
+
+	stmt := &ForStmt{
+		Var: p.parsePostfix(),
+	}
+	p.expectKind(Eq)
+	stmt.From = p.parseExpr()
+	p.expect("To")
+	stmt.To = p.parseExpr()
+	if p.accept("Step") {
+		stmt.Step = p.parseExpr()
+	}
+	stmt.Body = p.parseNext(line)
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+// parseNext parses the body of a For loop including the closing Next. Next
+// i, j closes several loops at once, the loops it closes besides this one are
+// left in nextCount for the enclosing For statements.
+func (p *parser) parseNext(line int) []Stmt {
+	body, term := p.parseBlock("Next")
+	if term == "" {
+		p.error(line, "missing Next")
+		return body
+	}
+
+	// Closed by the Next of a nested loop
+	if p.nextCount > 0 {
+		p.nextCount--
+		return body
+	}
+
+	p.next()
+	closes := 1
+	if p.tok().Kind == Word && !IsReserved(p.tok().Text) {
+		p.parsePostfix()
+		for p.acceptKind(Comma) {
+			p.parsePostfix()
+			closes++
+		}
+	}
+	p.nextCount = closes - 1
+
+	return body
+}
+
+func (p *parser) parseDo() *DoStmt {
+	start := p.i
+	line := p.tok().Line
+	p.expect("Do")
+	stmt := &DoStmt{}
+	switch {
+	case p.accept("While"):
+		stmt.Cond = p.parseExpr()
+	case p.accept("Until"):
+		stmt.Until = true
+		stmt.Cond = p.parseExpr()
+	}
+
+	stmt.Body = p.parseBody(line, "Loop")
+	if stmt.Cond == nil {
+		switch {
+		case p.accept("While"):
+			stmt.Post = true
+			stmt.Cond = p.parseExpr()
+		case p.accept("Until"):
+			stmt.Post = true
+			stmt.Until = true
+			stmt.Cond = p.parseExpr()
+		}
+	}
+
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *parser) parseWhile() *WhileStmt {
+	start := p.i
+	line := p.tok().Line
+	p.expect("While")
+	stmt := &WhileStmt{
+		Cond: p.parseExpr(),
+	}
+	stmt.Body = p.parseBody(line, "Wend")
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *parser) parseWith() *WithStmt {
+	start := p.i
+	line := p.tok().Line
+	p.expect("With")
+	stmt := &WithStmt{
+		X: p.parseExpr(),
+	}
+	stmt.Body = p.parseBody(line, "End With")
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *parser) parseCaseCond() *CaseCond {
+	start := p.i
+	cond := &CaseCond{}
+	if p.accept("Is") {
+		switch p.tok().Kind {
+		case Eq, Neq, Lt, Gt, Le, Ge:
+			cond.Op = p.next().Text
+		default:
+			p.errorf("expected comparison operator, found %s", p.describe())
+		}
+		cond.Value = p.parseExpr()
+	} else {
+		cond.Value = p.parseExpr()
+		if p.accept("To") {
+			cond.To = p.parseExpr()
+		}
+	}
+	cond.Span = p.spanFrom(start)
+	return cond
+}
+
+func (p *parser) parseSelect() *SelectStmt {
+	start := p.i
+	line := p.tok().Line
+	p.expect("Select")
+	p.expect("Case")
+	stmt := &SelectStmt{
+		X:     p.parseExpr(),
+		Cases: make([]*CaseClause, 0),
+	}
+	p.endStmt()
+
+	for {
+		p.skipSeparators()
+		for p.tok().Kind == Comment || p.tok().Kind == Directive {
+			p.next()
+			p.skipSeparators()
+		}
+		if p.matchTerm([]string{"End Select"}) != "" {
+			p.consumeTerm("End Select")
+			break
+		}
+		if !p.is("Case") {
+			p.error(line, "missing End Select")
+			break
+		}
+
+		caseStart := p.i
+		p.next()
+		clause := &CaseClause{}
+		if !p.accept("Else") {
+			clause.Conds = []*CaseCond{p.parseCaseCond()}
+			for p.acceptKind(Comma) {
+				clause.Conds = append(clause.Conds, p.parseCaseCond())
+			}
+		}
+		p.endStmt()
+		clause.Body, _ = p.parseBlock("Case", "End Select")
+		clause.Span = p.spanFrom(caseStart)
+		stmt.Cases = append(stmt.Cases, clause)
+	}
+
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *parser) parseOnError() *OnErrorStmt {
+	start := p.i
+	p.expect("On")
+	p.accept("Local")
+	p.expect("Error")
+	stmt := &OnErrorStmt{}
+	if p.accept("Resume") {
+		p.expect("Next")
+		stmt.ResumeNext = true
+	} else {
+		p.expect("GoTo")
+		stmt.Label = p.next().Text
+	}
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *parser) parsePrint(start int, object Expr) *PrintStmt {
+	stmt := &PrintStmt{
+		Object: object,
+		Items:  make([]*PrintItem, 0),
+	}
+	if p.tok().Kind == Hash {
+		stmt.File = p.parseUnary()
+		p.acceptKind(Comma)
+	}
+	for !p.atEndOfStmt() {
+		item := &PrintItem{}
+		if p.tok().Kind != Semicolon && p.tok().Kind != Comma {
+			item.X = p.parseExpr()
+		}
+		switch p.tok().Kind {
+		case Semicolon, Comma:
+			item.Sep = p.next().Text
+		}
+		stmt.Items = append(stmt.Items, item)
+	}
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *parser) parseOpen() *OpenStmt {
+	start := p.i
+	p.expect("Open")
+	stmt := &OpenStmt{
+		Path: p.parseExpr(),
+	}
+	p.expect("For")
+	stmt.Mode = p.expectKind(Word).Text
+	if p.accept("Access") {
+		stmt.Access = p.expectKind(Word).Text
+		if p.is("Write") {
+			stmt.Access += " " + p.next().Text
+		}
+	}
+	switch {
+	case p.is("Shared"):
+		stmt.Lock = p.next().Text
+	case p.accept("Lock"):
+		stmt.Lock = "Lock " + p.expectKind(Word).Text
+		if p.is("Write") {
+			stmt.Lock += " " + p.next().Text
+		}
+	}
+	p.expect("As")
+	stmt.File = p.parseExpr()
+	if p.accept("Len") {
+		p.expectKind(Eq)
+		stmt.Len = p.parseExpr()
+	}
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *parser) parseFileStmt(op string, words int) *FileStmt {
+	start := p.i
+	for i := 0; i < words; i++ {
+		p.next()
+	}
+	stmt := &FileStmt{
+		Op:   op,
+		Args: make([]Expr, 0),
+	}
+	if p.atEndOfStmt() {
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	}
+	stmt.File = p.parseExpr()
+	for p.acceptKind(Comma) {
+		if p.tok().Kind == Comma || p.atEndOfStmt() {
+			stmt.Args = append(stmt.Args, nil)
+			continue
+		}
+		stmt.Args = append(stmt.Args, p.parseExpr())
+	}
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
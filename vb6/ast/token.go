@@ -0,0 +1,123 @@
+package ast
+
+import "strings"
+
+type Kind int
+
+const (
+	EOF Kind = iota
+	Illegal
+	Newline
+	Comment
+	Directive // Conditional compilation line, e.g. #If Win32 Then
+
+	Word
+	Int
+	Float
+	String
+	Date
+
+	LParen    // (
+	RParen    // )
+	Comma     // ,
+	Semicolon // ;
+	Colon     // :
+	ColonEq   // :=
+	Dot       // .
+	Bang      // !
+	Hash      // #
+	Plus      // +
+	Minus     // -
+	Mul       // *
+	Div       // /
+	IntDiv    // \
+	Pow       // ^
+	Concat    // &
+	Eq        // =
+	Neq       // <>
+	Lt        // <
+	Gt        // >
+	Le        // <=
+	Ge        // >=
+)
+
+var kindNames = map[Kind]string{
+	EOF:       "end of file",
+	Illegal:   "illegal character",
+	Newline:   "end of line",
+	Comment:   "comment",
+	Directive: "directive",
+	Word:      "identifier",
+	Int:       "integer",
+	Float:     "number",
+	String:    "string",
+	Date:      "date",
+	LParen:    "(",
+	RParen:    ")",
+	Comma:     ",",
+	Semicolon: ";",
+	Colon:     ":",
+	ColonEq:   ":=",
+	Dot:       ".",
+	Bang:      "!",
+	Hash:      "#",
+	Plus:      "+",
+	Minus:     "-",
+	Mul:       "*",
+	Div:       "/",
+	IntDiv:    "\\",
+	Pow:       "^",
+	Concat:    "&",
+	Eq:        "=",
+	Neq:       "<>",
+	Lt:        "<",
+	Gt:        ">",
+	Le:        "<=",
+	Ge:        ">=",
+}
+
+func (k Kind) String() string {
+	if s, ok := kindNames[k]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// Token is a single lexical element of VB6 source text.
+//
+// Keywords are returned as Word tokens; VB6 allows most of them to be used as
+// member names so the parser decides what a word means from its position.
+type Token struct {
+	Kind Kind
+	Text string
+	Line int // 1-based line number
+	Pos  int // byte offset of the first character
+	End  int // byte offset just past the last character
+}
+
+// Is reports whether the token is the given word, ignoring case.
+func (t Token) Is(word string) bool {
+	return t.Kind == Word && strings.EqualFold(t.Text, word)
+}
+
+// reserved contains the words that can never start an expression or be used
+// as a plain identifier.
+var reserved = map[string]bool{
+	"and": true, "as": true, "byref": true, "byval": true, "call": true,
+	"case": true, "const": true, "declare": true, "dim": true, "do": true,
+	"each": true, "else": true, "elseif": true, "end": true, "enum": true,
+	"eqv": true, "exit": true, "for": true, "friend": true, "function": true,
+	"global": true, "gosub": true, "goto": true, "if": true, "imp": true,
+	"in": true, "is": true, "like": true, "loop": true, "mod": true,
+	"next": true, "not": true, "on": true, "option": true, "optional": true,
+	"or": true, "paramarray": true, "private": true, "property": true,
+	"public": true, "redim": true, "resume": true, "select": true, "set": true,
+	"static": true, "step": true, "sub": true, "then": true, "to": true,
+	"type": true, "until": true, "wend": true, "while": true, "with": true,
+	"withevents": true, "xor": true,
+}
+
+// IsReserved reports whether word is a reserved VB6 keyword.
+func IsReserved(word string) bool {
+	return reserved[strings.ToLower(word)]
+}
@@ -0,0 +1,78 @@
+package vb6
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Class struct {
+	Filename   string
+	Folder     string
+	Name       string
+	Properties PropertyMap // MultiUse, Persistable, DataBindingBehavior...
+	Attributes []Attribute
+	Script     string
+}
+
+// LoadClass loads a class module (.cls).
+func LoadClass(path string) (*Class, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, ErrFileNotExist
+	}
+
+	defer file.Close()
+
+	lines := readLines(file)
+	if len(lines) == 0 {
+		return nil, ErrFileEmpty
+	}
+
+	v, err := readVersion(lines[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if v != "1.0 CLASS" {
+		return nil, ErrBadVersion
+	}
+
+	class := &Class{
+		Filename:   path,
+		Folder:     filepath.Dir(path),
+		Name:       strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Properties: make(PropertyMap),
+	}
+
+	lines = lines[1:]
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "BEGIN" {
+		return nil, ErrExpectedBegin
+	}
+
+	lines = lines[1:]
+	for len(lines) > 0 {
+		line := strings.TrimSpace(lines[0])
+		lines = lines[1:]
+		if line == "END" {
+			break
+		}
+		readProperty(line, class.Properties)
+	}
+
+	lines, attr := readAttributes(lines)
+
+	class.Attributes = attr
+	class.Script = strings.Join(lines, "\n")
+
+	for _, a := range attr {
+		if a.Name == "VB_Name" {
+			if name, err := strconv.Unquote(a.Value); err == nil {
+				class.Name = name
+			}
+		}
+	}
+
+	return class, nil
+}
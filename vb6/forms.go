@@ -12,16 +12,19 @@ type Property struct {
 	Name       string
 	Value      string
 	Properties PropertyMap
+	Used       bool // Set when the property is read by one of the helpers
 }
 
-type PropertyMap map[string]Property
+type PropertyMap map[string]*Property
 
 type Control struct {
 	Form       *Form
+	Parent     *Control // Container of the control, nil for the root
 	TypeName   string
 	Name       string
 	Children   []*Control
 	Properties PropertyMap
+	Source     []string // Lines of the Begin ... End block
 }
 
 type Attribute struct {
@@ -72,7 +75,7 @@ func readProperty(line string, properties PropertyMap) {
 	equal := strings.Index(line, "=")
 	if equal != -1 {
 		name := strings.TrimSpace(line[:equal])
-		properties[name] = Property{
+		properties[name] = &Property{
 			Name:       name,
 			Value:      strings.TrimSpace(line[equal+1:]),
 			Properties: make(PropertyMap),
@@ -86,7 +89,12 @@ func readComplexProperty(lines []string, properties PropertyMap) ([]string, erro
 		return lines, ErrExpectedBeginProperty
 	}
 
+	// Strip the class id, e.g. BeginProperty Font {0BE35203-8F91-11CE-9DE3-00AA004BB851}
 	name := strings.TrimSpace(line[14:])
+	if space := strings.Index(name, " "); space != -1 {
+		name = name[:space]
+	}
+
 	results := make(PropertyMap)
 	lines = lines[1:]
 	for len(lines) > 0 {
@@ -94,11 +102,22 @@ func readComplexProperty(lines []string, properties PropertyMap) ([]string, erro
 		if line == "EndProperty" {
 			break
 		}
+		if strings.HasPrefix(line, "BeginProperty ") {
+			newLines, err := readComplexProperty(lines, results)
+			if err != nil {
+				return newLines, err
+			}
+			lines = newLines
+			continue
+		}
 		readProperty(line, results)
 		lines = lines[1:]
 	}
+	if len(lines) == 0 {
+		return lines, ErrUnexpectedEOF
+	}
 	lines = lines[1:]
-	properties[name] = Property{
+	properties[name] = &Property{
 		Name:       name,
 		Value:      "",
 		Properties: results,
@@ -116,6 +135,7 @@ func readControl(lines []string, form *Form) ([]string, *Control, error) {
 	if !strings.HasPrefix(line, "Begin ") {
 		return lines, nil, ErrExpectedBegin
 	}
+	source := lines
 
 	line = line[6:]
 
@@ -150,6 +170,7 @@ func readControl(lines []string, form *Form) ([]string, *Control, error) {
 			}
 			lines = newLines
 
+			child.Parent = control
 			control.Children = append(control.Children, child)
 			continue
 		}
@@ -171,6 +192,8 @@ func readControl(lines []string, form *Form) ([]string, *Control, error) {
 		lines = lines[1:]
 	}
 
+	control.Source = source[:len(source)-len(lines)]
+
 	return lines, control, nil
 }
 
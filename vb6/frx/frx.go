@@ -103,6 +103,41 @@ func LoadBinary(searchPath string, refStr string) ([]byte, error) {
 	return data, nil
 }
 
+// LoadBlob loads a resource that is stored with a 32-bit length prefix, such as
+// the property blobs of ActiveX controls and long strings.
+func LoadBlob(searchPath string, refStr string) ([]byte, error) {
+	ref, err := parseRef(searchPath, strings.TrimPrefix(refStr, "$"))
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(ref.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	_, err = file.Seek(ref.offset, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var size uint32
+	err = binary.Read(file, binary.LittleEndian, &size)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, size)
+	_, err = io.ReadFull(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
 // LoadList loads a list of strings from a FRX file.
 func LoadList(searchPath string, refStr string) ([]string, error) {
 	ref, err := parseRef(searchPath, refStr)
@@ -146,3 +181,65 @@ func LoadList(searchPath string, refStr string) ([]string, error) {
 
 	return items, nil
 }
+
+// LoadItemData loads the ItemData values of a list from a FRX file.
+func LoadItemData(searchPath string, refStr string) ([]int32, error) {
+	ref, err := parseRef(searchPath, refStr)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(ref.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	_, err = file.Seek(ref.offset, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var size uint16
+	err = binary.Read(file, binary.LittleEndian, &size)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]int32, size)
+	err = binary.Read(file, binary.LittleEndian, items)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// ExtractImages finds the pictures stored in an OLE object blob, such as the
+// images of an ImageList. Pictures are either stored as a picture stream
+// ("lt\0\0" followed by the size) or as plain bitmaps.
+func ExtractImages(blob []byte) [][]byte {
+	images := make([][]byte, 0)
+	for i := 0; i+8 <= len(blob); {
+		switch {
+		case blob[i] == 'l' && blob[i+1] == 't' && blob[i+2] == 0 && blob[i+3] == 0:
+			size := int(binary.LittleEndian.Uint32(blob[i+4:]))
+			if size > 0 && i+8+size <= len(blob) {
+				images = append(images, blob[i+8:i+8+size])
+				i += 8 + size
+				continue
+			}
+		case blob[i] == 'B' && blob[i+1] == 'M' && i+14 <= len(blob):
+			size := int(binary.LittleEndian.Uint32(blob[i+2:]))
+			reserved := binary.LittleEndian.Uint32(blob[i+6:])
+			if reserved == 0 && size > 26 && i+size <= len(blob) {
+				images = append(images, blob[i:i+size])
+				i += size
+				continue
+			}
+		}
+		i++
+	}
+	return images
+}
@@ -1,6 +1,7 @@
 package vb6
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -12,13 +13,19 @@ func GetStr(key string, props PropertyMap) (string, bool) {
 	if !ok {
 		return "", false
 	}
+	prop.Used = true
 
-	str, err := strconv.Unquote(prop.Value)
-	if err != nil {
+	// VB6 strings have no escape sequences, quotes are doubled
+	str := prop.Value
+	if len(str) < 2 || str[0] != '"' {
+		return "", false
+	}
+	end := strings.LastIndex(str, "\"")
+	if end == 0 {
 		return "", false
 	}
 
-	return str, true
+	return strings.ReplaceAll(str[1:end], "\"\"", "\""), true
 }
 
 func GetProp(key string, props PropertyMap) (string, bool) {
@@ -26,6 +33,7 @@ func GetProp(key string, props PropertyMap) (string, bool) {
 	if !ok {
 		return "", false
 	}
+	prop.Used = true
 
 	str := prop.Value
 
@@ -37,9 +45,36 @@ func GetProp(key string, props PropertyMap) (string, bool) {
 	return str, true
 }
 
+// GetBlock returns the properties of a BeginProperty ... EndProperty block.
+func GetBlock(key string, props PropertyMap) (PropertyMap, bool) {
+	prop, ok := props[key]
+	if !ok {
+		return nil, false
+	}
+	prop.Used = true
+
+	return prop.Properties, true
+}
+
+// Unused returns the names of the properties that were never read, in sorted
+// order.
+func Unused(props PropertyMap) []string {
+	names := make([]string, 0)
+	for name, prop := range props {
+		if !prop.Used {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DPI is the resolution of the screen the pixels are calculated for.
+var DPI = 96
+
 func TwipsToPixels(twips int) int {
 	inches := float64(twips) / 1440.0
-	return int(inches * 96.0)
+	return int(inches * float64(DPI))
 }
 
 func GetInt(key string, props PropertyMap) (int, bool) {
@@ -72,6 +107,21 @@ func GetFloat32(key string, props PropertyMap) (float32, bool) {
 	return float32(v), true
 }
 
+func GetFloat64(key string, props PropertyMap) (float64, bool) {
+	str, ok := GetProp(key, props)
+	if !ok {
+		return 0, false
+	}
+
+	str = strings.TrimSpace(str)
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
+
 func GetBool(key string, props PropertyMap) (bool, bool) {
 	v, ok := GetInt(key, props)
 	if !ok {
@@ -115,10 +165,23 @@ func GetColor(key string, props PropertyMap) (uint32, bool) {
 		return 0, false
 	}
 
+	str = strings.TrimSpace(str)
+
+	// ActiveX controls store colors as decimal numbers, system colors are
+	// negative
+	if !strings.HasPrefix(str, "&H") {
+		v, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return uint32(v), true
+	}
+
 	str = strings.TrimPrefix(str, "&H")
 	str = strings.TrimSuffix(str, "&")
 
-	v, err := strconv.ParseInt(str, 16, 32)
+	// System colors have the high bit set, e.g. &H8000000F&
+	v, err := strconv.ParseUint(str, 16, 32)
 	if err != nil {
 		return 0, false
 	}
@@ -141,6 +204,7 @@ func GetFont(key string, props PropertyMap) (*Font, bool) {
 	if !ok {
 		return nil, false
 	}
+	prop.Used = true
 
 	font := &Font{}
 	font.Family, _ = GetStr("Name", prop.Properties)
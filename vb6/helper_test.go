@@ -0,0 +1,31 @@
+package vb6
+
+import "testing"
+
+func TestGetColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  uint32
+		ok    bool
+	}{
+		{"&H000080FF&", 0x000080FF, true},
+		{"&H8000000F&", 0x8000000F, true},
+		{"&H80000012&  ' button text", 0x80000012, true},
+		{"255", 255, true},
+		{"-2147483633", 0x8000000F, true},
+		{"&HXYZ&", 0, false},
+		{"red", 0, false},
+	}
+
+	for _, test := range tests {
+		props := PropertyMap{"BackColor": {Name: "BackColor", Value: test.value}}
+		got, ok := GetColor("BackColor", props)
+		if got != test.want || ok != test.ok {
+			t.Errorf("GetColor(%q) = %#x, %v, want %#x, %v", test.value, got, ok, test.want, test.ok)
+		}
+	}
+
+	if _, ok := GetColor("ForeColor", PropertyMap{}); ok {
+		t.Error("GetColor of a missing property is ok")
+	}
+}
@@ -0,0 +1,51 @@
+package vb6
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Module struct {
+	Filename   string
+	Folder     string
+	Name       string
+	Attributes []Attribute
+	Script     string
+}
+
+// LoadModule loads a standard module (.bas).
+func LoadModule(path string) (*Module, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, ErrFileNotExist
+	}
+
+	defer file.Close()
+
+	lines := readLines(file)
+	if len(lines) == 0 {
+		return nil, ErrFileEmpty
+	}
+
+	lines, attr := readAttributes(lines)
+
+	module := &Module{
+		Filename:   path,
+		Folder:     filepath.Dir(path),
+		Name:       strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Attributes: attr,
+		Script:     strings.Join(lines, "\n"),
+	}
+
+	for _, a := range attr {
+		if a.Name == "VB_Name" {
+			if name, err := strconv.Unquote(a.Value); err == nil {
+				module.Name = name
+			}
+		}
+	}
+
+	return module, nil
+}
@@ -0,0 +1,163 @@
+package vb6
+
+import "math"
+
+// Values of the ScaleMode property
+const (
+	ScaleUser       = 0
+	ScaleTwips      = 1
+	ScalePoints     = 2
+	ScalePixels     = 3
+	ScaleCharacters = 4
+	ScaleInches     = 5
+	ScaleMillimeter = 6
+	ScaleCentimeter = 7
+)
+
+// designTwipsPerPixel is the size of a pixel on the 96 DPI screens the forms
+// were designed on.
+const designTwipsPerPixel = 15
+
+// Scale is the coordinate system of a container, which the position and size
+// of its children are in.
+type Scale struct {
+	X    float64 // Twips per horizontal unit
+	Y    float64 // Twips per vertical unit
+	Left float64 // ScaleLeft, the horizontal coordinate of the left edge
+	Top  float64 // ScaleTop, the vertical coordinate of the top edge
+}
+
+var twipScale = Scale{X: 1, Y: 1}
+
+// hasScale reports whether a container has its own coordinate system.
+// Containers without one, such as frames, use the coordinates of their own
+// container.
+func hasScale(c *Control) bool {
+	switch c.TypeName {
+	case "VB.Form", "VB.PictureBox", "VB.UserControl", "VB.PropertyPage":
+		return true
+	}
+	_, ok := c.Properties["ScaleMode"]
+	return ok
+}
+
+// unitScale returns the number of twips per unit of a ScaleMode.
+func unitScale(mode int) (float64, float64, bool) {
+	switch mode {
+	case ScaleTwips:
+		return 1, 1, true
+	case ScalePoints:
+		return 20, 20, true
+	case ScalePixels:
+		return designTwipsPerPixel, designTwipsPerPixel, true
+	case ScaleCharacters:
+		return 120, 240, true
+	case ScaleInches:
+		return 1440, 1440, true
+	case ScaleMillimeter:
+		return 1440 / 25.4, 1440 / 25.4, true
+	case ScaleCentimeter:
+		return 1440 / 2.54, 1440 / 2.54, true
+	}
+	return 0, 0, false
+}
+
+// innerSize returns the size of the inside of a container in twips.
+func innerSize(c *Control) (float64, float64, bool) {
+	if w, ok := GetFloat64("ClientWidth", c.Properties); ok {
+		h, ok := GetFloat64("ClientHeight", c.Properties)
+		return w, h, ok
+	}
+	w, h, ok := getFloat2("Width", "Height", c.Properties)
+	if !ok {
+		return 0, 0, false
+	}
+	parent := GetScale(c.Parent)
+	return w * math.Abs(parent.X), h * math.Abs(parent.Y), true
+}
+
+// GetScale returns the coordinate system of the children of a container, by
+// walking up the containers until one with its own ScaleMode is found. A nil
+// container has the twips of the screen.
+func GetScale(c *Control) Scale {
+	for c != nil && !hasScale(c) {
+		c = c.Parent
+	}
+	if c == nil {
+		return twipScale
+	}
+
+	// The size of the container in its own units is stored for every scale,
+	// but only needed for a user defined scale
+	scaleWidth, _ := GetFloat64("ScaleWidth", c.Properties)
+	scaleHeight, _ := GetFloat64("ScaleHeight", c.Properties)
+
+	mode, ok := GetInt("ScaleMode", c.Properties)
+	if !ok {
+		return twipScale
+	}
+	if x, y, ok := unitScale(mode); ok {
+		return Scale{X: x, Y: y}
+	}
+
+	// A user defined scale maps ScaleWidth and ScaleHeight units to the inside
+	// of the container
+	w, h, ok := innerSize(c)
+	if !ok || scaleWidth == 0 || scaleHeight == 0 {
+		return twipScale
+	}
+	scale := Scale{X: w / scaleWidth, Y: h / scaleHeight}
+	scale.Left, _ = GetFloat64("ScaleLeft", c.Properties)
+	scale.Top, _ = GetFloat64("ScaleTop", c.Properties)
+	return scale
+}
+
+func getFloat2(x string, y string, props PropertyMap) (float64, float64, bool) {
+	vx, ok := GetFloat64(x, props)
+	if !ok {
+		return 0, 0, false
+	}
+	vy, ok := GetFloat64(y, props)
+	if !ok {
+		return 0, 0, false
+	}
+	return vx, vy, true
+}
+
+// GetPoint returns a point of a control in pixels, such as Left and Top or
+// X1 and Y1 of a line, converted from the coordinates of its container.
+func GetPoint(x string, y string, c *Control) (int, int, bool) {
+	vx, vy, ok := getFloat2(x, y, c.Properties)
+	if !ok {
+		return 0, 0, false
+	}
+	scale := GetScale(c.Parent)
+	return twipsToPixels((vx - scale.Left) * scale.X), twipsToPixels((vy - scale.Top) * scale.Y), true
+}
+
+// GetExtent returns a size of a control in pixels, such as Width and Height,
+// converted from the units of its container.
+func GetExtent(w string, h string, c *Control) (int, int, bool) {
+	vw, vh, ok := getFloat2(w, h, c.Properties)
+	if !ok {
+		return 0, 0, false
+	}
+	scale := GetScale(c.Parent)
+	return twipsToPixels(vw * math.Abs(scale.X)), twipsToPixels(vh * math.Abs(scale.Y)), true
+}
+
+// GetLength returns a horizontal size of a control in pixels, converted from
+// the units of its container.
+func GetLength(key string, c *Control) (int, bool) {
+	v, ok := GetFloat64(key, c.Properties)
+	if !ok {
+		return 0, false
+	}
+	return twipsToPixels(v * math.Abs(GetScale(c.Parent).X)), true
+}
+
+func twipsToPixels(twips float64) int {
+	// Truncated like TwipsToPixels, the small margin keeps units that are a
+	// whole number of pixels from rounding down
+	return int(math.Floor(twips/1440*float64(DPI) + 1e-6))
+}
@@ -0,0 +1,89 @@
+package vb6
+
+import "testing"
+
+// newControl creates a control with the given properties as written in a form.
+func newControl(typeName string, parent *Control, props map[string]string) *Control {
+	c := &Control{TypeName: typeName, Parent: parent, Properties: make(PropertyMap)}
+	for k, v := range props {
+		c.Properties[k] = &Property{Name: k, Value: v}
+	}
+	if parent != nil {
+		parent.Children = append(parent.Children, c)
+	}
+	return c
+}
+
+func TestGetScale(t *testing.T) {
+	form := newControl("VB.Form", nil, map[string]string{"ClientWidth": "3000", "ClientHeight": "1500"})
+	pixels := newControl("VB.Form", nil, map[string]string{"ScaleMode": "3  'Pixel"})
+	user := newControl("VB.Form", nil, map[string]string{
+		"ClientWidth": "3000", "ClientHeight": "1500", "ScaleMode": "0  'User",
+		"ScaleWidth": "100", "ScaleHeight": "-50", "ScaleLeft": "10", "ScaleTop": "50",
+	})
+
+	tests := []struct {
+		name string
+		c    *Control
+		want Scale
+	}{
+		{"no container", nil, Scale{X: 1, Y: 1}},
+		{"twips", form, Scale{X: 1, Y: 1}},
+		{"pixels", pixels, Scale{X: 15, Y: 15}},
+		{"inches", newControl("VB.PictureBox", form, map[string]string{"ScaleMode": "5"}), Scale{X: 1440, Y: 1440}},
+		{"characters", newControl("VB.PictureBox", form, map[string]string{"ScaleMode": "4"}), Scale{X: 120, Y: 240}},
+		{"frame uses its container", newControl("VB.Frame", pixels, nil), Scale{X: 15, Y: 15}},
+		{"user", user, Scale{X: 30, Y: -30, Left: 10, Top: 50}},
+		{"user in a picture box", newControl("VB.PictureBox", pixels, map[string]string{
+			"Width": "200", "Height": "100", "ScaleMode": "0", "ScaleWidth": "10", "ScaleHeight": "10",
+		}), Scale{X: 300, Y: 150}},
+		{"user without size", newControl("VB.PictureBox", nil, map[string]string{"ScaleMode": "0", "ScaleWidth": "10"}), Scale{X: 1, Y: 1}},
+	}
+
+	for _, test := range tests {
+		if got := GetScale(test.c); got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGetPoint(t *testing.T) {
+	form := newControl("VB.Form", nil, nil)
+	pixels := newControl("VB.Form", nil, map[string]string{"ScaleMode": "3"})
+	frame := newControl("VB.Frame", pixels, nil)
+	user := newControl("VB.Form", nil, map[string]string{
+		"ClientWidth": "3000", "ClientHeight": "1500", "ScaleMode": "0",
+		"ScaleWidth": "100", "ScaleHeight": "-50", "ScaleLeft": "10", "ScaleTop": "50",
+	})
+
+	tests := []struct {
+		name       string
+		parent     *Control
+		props      map[string]string
+		dpi        int
+		x, y, w, h int
+		hasPoint   bool
+		hasExtent  bool
+	}{
+		{"twips", form, map[string]string{"Left": "1500", "Top": "750", "Width": "1200", "Height": "300"}, 96, 100, 50, 80, 20, true, true},
+		{"twips at 144 DPI", form, map[string]string{"Left": "1500", "Top": "750", "Width": "1200", "Height": "300"}, 144, 150, 75, 120, 30, true, true},
+		{"pixels", pixels, map[string]string{"Left": "10", "Top": "20", "Width": "30", "Height": "40"}, 96, 10, 20, 30, 40, true, true},
+		{"pixels in a frame", frame, map[string]string{"Left": "10", "Top": "20", "Width": "30", "Height": "40"}, 96, 10, 20, 30, 40, true, true},
+		{"flipped user scale", user, map[string]string{"Left": "20", "Top": "40", "Width": "10", "Height": "5"}, 96, 20, 20, 20, 10, true, true},
+		{"no position", form, map[string]string{"Width": "150", "Height": "150"}, 96, 0, 0, 10, 10, false, true},
+	}
+
+	t.Cleanup(func() { DPI = 96 })
+	for _, test := range tests {
+		DPI = test.dpi
+		c := newControl("VB.CommandButton", test.parent, test.props)
+		x, y, ok := GetPoint("Left", "Top", c)
+		if ok != test.hasPoint || ok && (x != test.x || y != test.y) {
+			t.Errorf("%s: GetPoint = %d, %d, %v, want %d, %d, %v", test.name, x, y, ok, test.x, test.y, test.hasPoint)
+		}
+		w, h, ok := GetExtent("Width", "Height", c)
+		if ok != test.hasExtent || w != test.w || h != test.h {
+			t.Errorf("%s: GetExtent = %d, %d, %v, want %d, %d, %v", test.name, w, h, ok, test.w, test.h, test.hasExtent)
+		}
+	}
+}
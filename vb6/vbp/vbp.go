@@ -29,6 +29,11 @@ type Module struct {
 	Filename string
 }
 
+type Class struct {
+	Name     string
+	Filename string
+}
+
 type Version struct {
 	Major    int
 	Minor    int
@@ -36,16 +41,19 @@ type Version struct {
 }
 
 type Project struct {
-	Name       string
-	Folder     string
-	References []*Reference
-	Objects    []*Object
-	Modules    []*Module
-	Forms      []string
-	Startup    string
-	Title      string
-	ExeName32  string
-	Version    Version
+	Name         string
+	Folder       string
+	References   []*Reference
+	Objects      []*Object
+	Modules      []*Module
+	Classes      []*Class
+	Forms        []string
+	UserControls []string
+	Startup      string
+	Title        string
+	ExeName32    string
+	HelpFile     string
+	Version      Version
 }
 
 func Open(name string) (*Project, error) {
@@ -55,12 +63,14 @@ func Open(name string) (*Project, error) {
 	}
 	defer file.Close()
 	project := &Project{
-		Name:       name,
-		Folder:     filepath.Dir(name),
-		References: make([]*Reference, 0),
-		Objects:    make([]*Object, 0),
-		Modules:    make([]*Module, 0),
-		Forms:      make([]string, 0),
+		Name:         name,
+		Folder:       filepath.Dir(name),
+		References:   make([]*Reference, 0),
+		Objects:      make([]*Object, 0),
+		Modules:      make([]*Module, 0),
+		Classes:      make([]*Class, 0),
+		Forms:        make([]string, 0),
+		UserControls: make([]string, 0),
 	}
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -98,8 +108,17 @@ func Open(name string) (*Project, error) {
 			}
 			mod.Filename = filepath.Join(project.Folder, mod.Filename)
 			project.Modules = append(project.Modules, mod)
+		case "Class":
+			class, err := parseClass(value)
+			if err != nil {
+				return nil, err
+			}
+			class.Filename = filepath.Join(project.Folder, class.Filename)
+			project.Classes = append(project.Classes, class)
 		case "Form":
 			project.Forms = append(project.Forms, filepath.Join(project.Folder, value))
+		case "UserControl":
+			project.UserControls = append(project.UserControls, filepath.Join(project.Folder, value))
 		case "Name":
 			value = strings.TrimSpace(value)
 			if len(value) > 0 {
@@ -127,6 +146,13 @@ func Open(name string) (*Project, error) {
 				return nil, err
 			}
 			project.ExeName32 = s
+		case "HelpFile":
+			// Help files are paths, their backslashes are not escapes
+			s, err := unquote(value)
+			if err != nil {
+				return nil, err
+			}
+			project.HelpFile = s
 		case "MajorVer":
 			project.Version.Major, _ = strconv.Atoi(value)
 		case "MinorVer":
@@ -138,6 +164,16 @@ func Open(name string) (*Project, error) {
 	return project, nil
 }
 
+// unquote removes the quotes around a VB6 string, which has no escape
+// sequences, quotes are doubled.
+func unquote(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", errors.New("invalid string")
+	}
+	return strings.ReplaceAll(s[1:len(s)-1], "\"\"", "\""), nil
+}
+
 func parseGuid(s string) string {
 	s = strings.TrimPrefix(s, "*\\G{")
 	s = strings.TrimSuffix(s, "}")
@@ -196,3 +232,14 @@ func parseModule(s string) (*Module, error) {
 		Filename: strings.TrimSpace(tok[1]),
 	}, nil
 }
+
+func parseClass(s string) (*Class, error) {
+	tok := strings.Split(s, ";")
+	if len(tok) != 2 {
+		return nil, errors.New("invalid class")
+	}
+	return &Class{
+		Name:     tok[0],
+		Filename: strings.TrimSpace(tok[1]),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package vbp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeProject writes a project file with the given lines after the Type.
+func writeProject(t *testing.T, lines string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "Project1.vbp")
+	if err := os.WriteFile(name, []byte("Type=Exe\r\n"+lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestOpenHelpFile(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`HelpFile="C:\Help\app.hlp"`, `C:\Help\app.hlp`},
+		{`HelpFile="help\app.chm"`, `help\app.chm`},
+		{`HelpFile="a ""quoted"" name.chm"`, `a "quoted" name.chm`},
+		{`HelpFile=""`, ``},
+	}
+
+	for _, test := range tests {
+		project, err := Open(writeProject(t, test.line+"\r\n"))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.line, err)
+			continue
+		}
+		if project.HelpFile != test.want {
+			t.Errorf("%s: got %q, want %q", test.line, project.HelpFile, test.want)
+		}
+	}
+
+	if _, err := Open(writeProject(t, "HelpFile=app.hlp\r\n")); err == nil {
+		t.Error("no error for a HelpFile without quotes")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	name := writeProject(t, `Form=frmMain.frm
+Module=modMain; Main.bas
+Class=CThing; CThing.cls
+UserControl=ucBox.ctl
+Startup="frmMain"
+Name="Project1"
+HelpFile="C:\Program Files\App\app.chm"
+MajorVer=1
+MinorVer=2
+RevisionVer=3
+`)
+	project, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folder := filepath.Dir(name)
+	if len(project.Forms) != 1 || project.Forms[0] != filepath.Join(folder, "frmMain.frm") {
+		t.Errorf("got forms %v", project.Forms)
+	}
+	if len(project.Modules) != 1 || project.Modules[0].Name != "modMain" {
+		t.Errorf("got modules %+v", project.Modules)
+	}
+	if len(project.Classes) != 1 || project.Classes[0].Name != "CThing" {
+		t.Errorf("got classes %+v", project.Classes)
+	}
+	if len(project.UserControls) != 1 {
+		t.Errorf("got user controls %v", project.UserControls)
+	}
+	if project.Name != "Project1" || project.Startup != "frmMain" {
+		t.Errorf("got name %q and startup %q", project.Name, project.Startup)
+	}
+	if project.HelpFile != `C:\Program Files\App\app.chm` {
+		t.Errorf("got help file %q", project.HelpFile)
+	}
+	if project.Version != (Version{1, 2, 3}) {
+		t.Errorf("got version %+v", project.Version)
+	}
+}